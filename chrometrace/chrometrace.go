@@ -0,0 +1,350 @@
+// Package chrometrace converts JFR recordings into the Chrome trace event
+// format, which can be loaded into Perfetto or chrome://tracing to see
+// events on a timeline instead of aggregated into a profile.
+package chrometrace
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/grafana/jfr-parser/internal/panics"
+	"github.com/grafana/jfr-parser/jvmstats"
+	"github.com/grafana/jfr-parser/parser"
+	"github.com/grafana/jfr-parser/parser/types"
+)
+
+type traceOptions struct {
+	disablePanicRecovery bool
+}
+
+type Option func(*traceOptions)
+
+func WithDisablePanicRecovery(v bool) Option {
+	return func(o *traceOptions) {
+		o.disablePanicRecovery = v
+	}
+}
+
+// Phases of the trace event format.
+const (
+	PhaseComplete = "X"
+	PhaseInstant  = "i"
+	PhaseMetadata = "M"
+)
+
+// Java threads are grouped under one process and the JVM runtime tracks,
+// which do not belong to a thread, under another.
+const (
+	PIDThreads = 1
+	PIDRuntime = 2
+)
+
+// Thread ids of the runtime tracks.
+const (
+	TIDSafepoints = iota + 1
+	TIDGarbageCollection
+	TIDVMOperations
+	TIDCompilations
+)
+
+var runtimeTracks = []struct {
+	tid  uint64
+	name string
+}{
+	{TIDSafepoints, "Safepoints"},
+	{TIDGarbageCollection, "Garbage collection"},
+	{TIDVMOperations, "VM operations"},
+	{TIDCompilations, "JIT compilation"},
+}
+
+// Event is a trace event. Ts and Dur are in microseconds, Ts is relative to
+// the start of the recording.
+type Event struct {
+	Name string         `json:"name"`
+	Cat  string         `json:"cat,omitempty"`
+	Ph   string         `json:"ph"`
+	Ts   float64        `json:"ts"`
+	Dur  float64        `json:"dur,omitempty"`
+	PID  int            `json:"pid"`
+	TID  uint64         `json:"tid"`
+	S    string         `json:"s,omitempty"`
+	Args map[string]any `json:"args,omitempty"`
+}
+
+type Trace struct {
+	TraceEvents     []Event `json:"traceEvents"`
+	DisplayTimeUnit string  `json:"displayTimeUnit,omitempty"`
+	// StartNanos is the unix time the event timestamps are relative to.
+	StartNanos int64 `json:"-"`
+}
+
+// Write writes the trace in the JSON object format.
+func (t *Trace) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(t)
+}
+
+func ParseJFR(body []byte, opts ...Option) (res *Trace, err error) {
+	o := &traceOptions{}
+	for i := range opts {
+		opts[i](o)
+	}
+	if !o.disablePanicRecovery {
+		defer panics.Recover(&err)
+	}
+	p := parser.NewParser(body, parser.Options{
+		SymbolProcessor: parser.ProcessSymbols,
+	})
+	return parse(p)
+}
+
+func parse(p *parser.Parser) (*Trace, error) {
+	b := newBuilder(p)
+	stats := jvmstats.NewBuilder()
+	for {
+		typ, err := p.ParseEvent()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("jfr parser ParseEvent error: %w", err)
+		}
+		b.setStart()
+		switch typ {
+		case p.TypeMap.T_EXECUTION_SAMPLE:
+			e := &p.ExecutionSample
+			b.addSample("cpu", p.TimestampNanos(typ, "startTime", int64(e.StartTime)), e.SampledThread, e.StackTrace)
+		case p.TypeMap.T_WALL_CLOCK_SAMPLE:
+			e := &p.WallClockSample
+			b.addSample("wall", p.TimestampNanos(typ, "startTime", int64(e.StartTime)), e.SampledThread, e.StackTrace)
+		case p.TypeMap.T_NATIVE_METHOD_SAMPLE:
+			e := &p.NativeMethodSample
+			b.addSample("native", p.TimestampNanos(typ, "startTime", int64(e.StartTime)), e.SampledThread, e.StackTrace)
+		case p.TypeMap.T_MONITOR_ENTER:
+			e := &p.JavaMonitorEnter
+			b.addSlice("monitor enter", "lock",
+				p.TimestampNanos(typ, "startTime", int64(e.StartTime)), p.TimespanNanos(typ, "duration", int64(e.Duration)),
+				e.EventThread, e.StackTrace, b.className(e.MonitorClass))
+		case p.TypeMap.T_THREAD_PARK:
+			e := &p.ThreadPark
+			b.addSlice("park", "lock",
+				p.TimestampNanos(typ, "startTime", int64(e.StartTime)), p.TimespanNanos(typ, "duration", int64(e.Duration)),
+				e.EventThread, e.StackTrace, b.className(e.ParkedClass))
+		default:
+			stats.Add(p, typ)
+		}
+	}
+	s := stats.Build()
+	b.addSafepoints(s.Safepoints)
+	b.addCollections(s.Collections)
+	b.addVMOperations(s.VMOperations)
+	b.addCompilations(s.Compilations)
+	return b.build(), nil
+}
+
+type builder struct {
+	parser *parser.Parser
+	res    Trace
+	// tids are the trace thread ids of the Java threads, names their names indexed by tid-1
+	tids  map[parser.ThreadKey]uint64
+	names []string
+}
+
+func newBuilder(p *parser.Parser) *builder {
+	return &builder{
+		parser: p,
+		res:    Trace{DisplayTimeUnit: "ns"},
+		tids:   make(map[parser.ThreadKey]uint64),
+	}
+}
+
+// setStart uses the start of the first chunk as the origin of the timeline.
+func (b *builder) setStart() {
+	if b.res.StartNanos == 0 {
+		b.res.StartNanos = int64(b.parser.ChunkHeader().StartNanos)
+	}
+}
+
+func (b *builder) ts(nanos int64) float64 {
+	return float64(nanos-b.res.StartNanos) / 1e3
+}
+
+// addSample adds a thread scoped instant event with the top frame of the stack trace.
+func (b *builder) addSample(name string, nanos int64, thread types.ThreadRef, ref types.StackTraceRef) {
+	tid, ok := b.thread(thread)
+	if !ok {
+		return
+	}
+	e := Event{Name: name, Cat: "sample", Ph: PhaseInstant, Ts: b.ts(nanos), PID: PIDThreads, TID: tid, S: "t"}
+	if frame := b.topFrame(ref); frame != "" {
+		e.Args = map[string]any{"frame": frame}
+	}
+	b.res.TraceEvents = append(b.res.TraceEvents, e)
+}
+
+// addSlice adds a thread scoped duration event, e.g. a thread blocked on a lock.
+func (b *builder) addSlice(name, cat string, nanos, durationNanos int64, thread types.ThreadRef, ref types.StackTraceRef, class string) {
+	tid, ok := b.thread(thread)
+	if !ok {
+		return
+	}
+	e := Event{Name: name, Cat: cat, Ph: PhaseComplete, Ts: b.ts(nanos), Dur: float64(durationNanos) / 1e3, PID: PIDThreads, TID: tid}
+	args := map[string]any{}
+	if class != "" {
+		args["class"] = class
+	}
+	if frame := b.topFrame(ref); frame != "" {
+		args["frame"] = frame
+	}
+	if len(args) > 0 {
+		e.Args = args
+	}
+	b.res.TraceEvents = append(b.res.TraceEvents, e)
+}
+
+// addSafepoints adds a slice per safepoint, named after its VM operation,
+// with a nested slice for the time it took to reach the safepoint.
+func (b *builder) addSafepoints(safepoints []jvmstats.Safepoint) {
+	for _, sp := range safepoints {
+		if sp.TotalNanos == 0 {
+			continue
+		}
+		name := sp.Operation
+		if name == "" {
+			name = "safepoint"
+		}
+		b.res.TraceEvents = append(b.res.TraceEvents, Event{
+			Name: name, Cat: "safepoint", Ph: PhaseComplete,
+			Ts: b.ts(sp.StartNanos), Dur: float64(sp.TotalNanos) / 1e3,
+			PID: PIDRuntime, TID: TIDSafepoints,
+			Args: map[string]any{
+				"safepoint_id":       sp.ID,
+				"time_to_safepoint":  sp.TimeToSafepointNanos,
+				"operation_duration": sp.OperationNanos,
+				"threads":            sp.TotalThreads,
+			},
+		}, Event{
+			Name: "time to safepoint", Cat: "safepoint", Ph: PhaseComplete,
+			Ts: b.ts(sp.StartNanos), Dur: float64(sp.TimeToSafepointNanos) / 1e3,
+			PID: PIDRuntime, TID: TIDSafepoints,
+		})
+	}
+}
+
+// addCollections adds a slice per garbage collection with its pauses nested.
+func (b *builder) addCollections(collections []jvmstats.Collection) {
+	for _, c := range collections {
+		if c.StartNanos == 0 {
+			continue
+		}
+		b.res.TraceEvents = append(b.res.TraceEvents, Event{
+			Name: c.Name, Cat: "gc", Ph: PhaseComplete,
+			Ts: b.ts(c.StartNanos), Dur: float64(c.Duration.Nanoseconds()) / 1e3,
+			PID: PIDRuntime, TID: TIDGarbageCollection,
+			Args: map[string]any{
+				"gc_id":         c.ID,
+				"cause":         c.Cause,
+				"generation":    c.Generation.String(),
+				"sum_of_pauses": c.SumOfPauses.Nanoseconds(),
+			},
+		})
+		for _, pause := range c.Pauses {
+			b.res.TraceEvents = append(b.res.TraceEvents, Event{
+				Name: pause.Name, Cat: "gc", Ph: PhaseComplete,
+				Ts: b.ts(pause.StartNanos), Dur: float64(pause.Duration.Nanoseconds()) / 1e3,
+				PID: PIDRuntime, TID: TIDGarbageCollection,
+			})
+		}
+	}
+}
+
+func (b *builder) addVMOperations(ops []jvmstats.VMOperation) {
+	for _, op := range ops {
+		args := map[string]any{"caller": op.Caller, "blocking": op.Blocking}
+		if op.SafepointID != 0 {
+			args["safepoint_id"] = op.SafepointID
+		}
+		b.res.TraceEvents = append(b.res.TraceEvents, Event{
+			Name: op.Name, Cat: "vm_operation", Ph: PhaseComplete,
+			Ts: b.ts(op.StartNanos), Dur: float64(op.DurationNanos) / 1e3,
+			PID: PIDRuntime, TID: TIDVMOperations, Args: args,
+		})
+	}
+}
+
+func (b *builder) addCompilations(compilations []jvmstats.Compilation) {
+	for _, c := range compilations {
+		args := map[string]any{"compile_id": c.CompileID, "compiler": c.Compiler, "level": c.Level}
+		if !c.Succeeded {
+			args["failure"] = c.FailureMessage
+		}
+		b.res.TraceEvents = append(b.res.TraceEvents, Event{
+			Name: c.Method, Cat: "jit", Ph: PhaseComplete,
+			Ts: b.ts(c.StartNanos), Dur: float64(c.DurationNanos) / 1e3,
+			PID: PIDRuntime, TID: TIDCompilations, Args: args,
+		})
+	}
+}
+
+// thread returns the trace thread id of a Java thread. Threads are numbered from 1
+// in the order of their first event.
+func (b *builder) thread(ref types.ThreadRef) (uint64, bool) {
+	t := b.parser.GetThread(ref)
+	if t == nil {
+		return 0, false
+	}
+	k := parser.ThreadKeyOf(t)
+	if tid, ok := b.tids[k]; ok {
+		return tid, true
+	}
+	name := t.JavaName
+	if name == "" {
+		name = t.OsName
+	}
+	b.names = append(b.names, name)
+	tid := uint64(len(b.names))
+	b.tids[k] = tid
+	return tid, true
+}
+
+func (b *builder) className(ref types.ClassRef) string {
+	cls := b.parser.GetClass(ref)
+	if cls == nil {
+		return ""
+	}
+	return b.parser.GetSymbolString(cls.Name)
+}
+
+func (b *builder) topFrame(ref types.StackTraceRef) string {
+	st := b.parser.GetStacktrace(ref)
+	if st == nil || len(st.Frames) == 0 {
+		return ""
+	}
+	m := b.parser.GetMethod(st.Frames[0].Method)
+	if m == nil {
+		return ""
+	}
+	frame, _ := b.parser.FrameName(m)
+	return frame
+}
+
+// build adds the process and thread name metadata and orders the events by time.
+func (b *builder) build() *Trace {
+	meta := []Event{
+		{Name: "process_name", Ph: PhaseMetadata, PID: PIDThreads, Args: map[string]any{"name": "Java threads"}},
+		{Name: "process_name", Ph: PhaseMetadata, PID: PIDRuntime, Args: map[string]any{"name": "JVM"}},
+	}
+	for _, t := range runtimeTracks {
+		meta = append(meta, Event{Name: "thread_name", Ph: PhaseMetadata, PID: PIDRuntime, TID: t.tid, Args: map[string]any{"name": t.name}})
+	}
+	for i, name := range b.names {
+		meta = append(meta, Event{Name: "thread_name", Ph: PhaseMetadata, PID: PIDThreads, TID: uint64(i + 1), Args: map[string]any{"name": name}})
+	}
+	slices.SortStableFunc(b.res.TraceEvents, func(x, y Event) int {
+		return cmp.Compare(x.Ts, y.Ts)
+	})
+	b.res.TraceEvents = append(meta, b.res.TraceEvents...)
+	return &b.res
+}
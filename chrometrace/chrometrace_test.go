@@ -0,0 +1,58 @@
+package chrometrace
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/jfr-parser/internal/testutil"
+)
+
+func TestTrace(t *testing.T) {
+	tr, err := ParseJFR(testutil.ReadGzipFile(t, "object-allocation-sample.jfr.gz"))
+	require.NoError(t, err)
+
+	safepoints, samples, locks := 0, 0, 0
+	threads := map[uint64]bool{}
+	tracks := map[uint64]bool{}
+	for i, e := range tr.TraceEvents {
+		if i > 0 && e.Ph != PhaseMetadata {
+			require.LessOrEqual(t, tr.TraceEvents[i-1].Ts, e.Ts)
+		}
+		if e.PID == PIDRuntime && e.Ph != PhaseMetadata {
+			tracks[e.TID] = true
+		}
+		switch {
+		case e.Ph == PhaseMetadata && e.Name == "thread_name" && e.PID == PIDThreads:
+			threads[e.TID] = true
+		case e.Cat == "lock":
+			locks++
+			assert.True(t, threads[e.TID], "slice on thread %d without a name", e.TID)
+			assert.Contains(t, []string{"monitor enter", "park"}, e.Name)
+		case e.Cat == "safepoint" && e.Name != "time to safepoint":
+			safepoints++
+			assert.Equal(t, TIDSafepoints, int(e.TID))
+			assert.Positive(t, e.Dur)
+		case e.Cat == "sample":
+			samples++
+			assert.True(t, threads[e.TID], "sample on thread %d without a name", e.TID)
+			assert.GreaterOrEqual(t, e.Ts, 0.0)
+		}
+	}
+	// the last safepoint is cut by the end of the recording
+	assert.Equal(t, 145, safepoints)
+	assert.Equal(t, 1243, samples)
+	assert.Equal(t, 799, locks)
+	assert.Len(t, tracks, len(runtimeTracks))
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, tr.Write(buf))
+	var decoded struct {
+		TraceEvents []map[string]any `json:"traceEvents"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Len(t, decoded.TraceEvents, len(tr.TraceEvents))
+}
@@ -0,0 +1,207 @@
+// Package collapsed converts JFR recordings into folded stacks
+// (`frame;frame;frame value`), the input format of Brendan Gregg's
+// flamegraph.pl, speedscope and similar tools.
+package collapsed
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grafana/jfr-parser/pprof"
+)
+
+type collapsedOptions struct {
+	threadRoot           bool
+	frameTypes           bool
+	sampleRate           int64
+	disablePanicRecovery bool
+}
+
+type Option func(*collapsedOptions)
+
+// WithThreadRoot adds the thread name as the root frame of every stack.
+func WithThreadRoot(v bool) Option {
+	return func(o *collapsedOptions) {
+		o.threadRoot = v
+	}
+}
+
+// WithFrameTypes annotates frames with async-profiler style suffixes:
+// _[j] for JIT compiled, _[i] for inlined, _[0] for interpreted,
+// _[1] for C1 compiled and _[k] for kernel frames.
+func WithFrameTypes(v bool) Option {
+	return func(o *collapsedOptions) {
+		o.frameTypes = v
+	}
+}
+
+// WithSampleRate sets the sample rate of the recording in Hz, which converts CPU and
+// wall samples to nanoseconds. It is 100 by default, see pprof.ParseInput.
+func WithSampleRate(hz int64) Option {
+	return func(o *collapsedOptions) {
+		o.sampleRate = hz
+	}
+}
+
+func WithDisablePanicRecovery(v bool) Option {
+	return func(o *collapsedOptions) {
+		o.disablePanicRecovery = v
+	}
+}
+
+type Profiles struct {
+	Profiles []Profile
+	JFREvent string
+}
+
+// Profile holds the folded stacks of a profile of the pprof package, with the same
+// sample types and units. CPU and wall values are nanoseconds.
+type Profile struct {
+	Metric      string
+	SampleTypes []pprof.ValueType
+	Stacks      []Stack
+}
+
+type Stack struct {
+	// Frames are root first.
+	Frames []string
+	Values []int64
+}
+
+// Write writes the profile as folded stacks, one line per unique stack,
+// using the value at valueIndex. Stacks with a zero value are omitted.
+func (p *Profile) Write(w io.Writer, valueIndex int) error {
+	if valueIndex < 0 || valueIndex >= len(p.SampleTypes) {
+		return fmt.Errorf("value index %d out of range, profile %s has %d sample types", valueIndex, p.Metric, len(p.SampleTypes))
+	}
+	for _, s := range p.Stacks {
+		if s.Values[valueIndex] == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s %d\n", strings.Join(s.Frames, ";"), s.Values[valueIndex]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseJFR converts a recording to folded stacks, a profile per profile of pprof.ParseJFR.
+func ParseJFR(body []byte, opts ...Option) (*Profiles, error) {
+	o := &collapsedOptions{sampleRate: 100}
+	for i := range opts {
+		opts[i](o)
+	}
+	pi := &pprof.ParseInput{
+		StartTime:  time.Now(),
+		EndTime:    time.Now(),
+		SampleRate: o.sampleRate,
+	}
+	s := NewSink()
+	err := pprof.WalkJFR(body, pi, nil, s,
+		pprof.WithThreadNameLabel(o.threadRoot),
+		pprof.WithFrameTypes(o.frameTypes),
+		pprof.WithDisablePanicRecovery(o.disablePanicRecovery),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Profiles{Profiles: s.Profiles(), JFREvent: s.jfrEvent}, nil
+}
+
+// Sink is a pprof.Sink that builds folded stacks, ordered by their frames. Samples that
+// only differ in their labels become the same stack, the folded format has no labels.
+// A pprof.LabelThreadName label, see pprof.WithThreadNameLabel, becomes the root frame.
+type Sink struct {
+	profiles []*sinkProfile
+	result   []Profile
+	jfrEvent string
+}
+
+type sinkProfile struct {
+	Profile
+	functions []string
+	// locations are indices into functions
+	locations []int
+	// stacks maps a StackID and index the joined frames to an index into Stacks.
+	stacks map[uint64]int
+	index  map[string]int
+}
+
+func NewSink() *Sink {
+	return &Sink{}
+}
+
+func (s *Sink) AddProfile(t *pprof.ProfileType) int {
+	p := &sinkProfile{
+		Profile: Profile{Metric: t.Metric, SampleTypes: t.SampleTypes},
+		stacks:  make(map[uint64]int),
+		index:   make(map[string]int),
+	}
+	s.profiles = append(s.profiles, p)
+	return len(s.profiles) - 1
+}
+
+func (s *Sink) AddFunction(profile int, name string) uint64 {
+	p := s.profiles[profile]
+	p.functions = append(p.functions, name)
+	return uint64(len(p.functions))
+}
+
+func (s *Sink) AddLocation(profile int, function uint64, _ int64) uint64 {
+	p := s.profiles[profile]
+	p.locations = append(p.locations, int(function)-1)
+	return uint64(len(p.locations))
+}
+
+func (s *Sink) AddSample(profile int, sample *pprof.Sample) {
+	p := s.profiles[profile]
+	i, ok := p.stacks[sample.StackID]
+	if !ok {
+		frames := make([]string, 0, len(sample.Locations)+1)
+		for _, l := range sample.Labels {
+			if l.Key == pprof.LabelThreadName {
+				frames = append(frames, "["+l.Value+"]")
+			}
+		}
+		// sample locations are leaf first
+		for j := len(sample.Locations) - 1; j >= 0; j-- {
+			frames = append(frames, p.functions[p.locations[sample.Locations[j]-1]])
+		}
+		key := strings.Join(frames, ";")
+		i, ok = p.index[key]
+		if !ok {
+			i = len(p.Stacks)
+			p.index[key] = i
+			p.Stacks = append(p.Stacks, Stack{Frames: frames, Values: make([]int64, len(p.SampleTypes))})
+		}
+		p.stacks[sample.StackID] = i
+	}
+	values := p.Stacks[i].Values
+	for j, v := range sample.Values {
+		values[j] += v
+	}
+}
+
+func (s *Sink) Finish(summary *pprof.Summary) error {
+	s.jfrEvent = summary.JFREvent
+	s.result = make([]Profile, 0, len(s.profiles))
+	for i, p := range s.profiles {
+		if slices.Contains(summary.Discarded, i) {
+			continue
+		}
+		slices.SortFunc(p.Stacks, func(a, b Stack) int {
+			return slices.Compare(a.Frames, b.Frames)
+		})
+		s.result = append(s.result, p.Profile)
+	}
+	s.profiles = nil
+	return nil
+}
+
+// Profiles returns the folded stacks once the walk finished.
+func (s *Sink) Profiles() []Profile {
+	return s.result
+}
@@ -0,0 +1,84 @@
+package collapsed
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/jfr-parser/internal/testutil"
+	"github.com/grafana/jfr-parser/pprof"
+)
+
+func TestTotalsMatchPprof(t *testing.T) {
+	for _, jfr := range []string{"cortex-dev-01__kafka-0__cpu_lock0_alloc0__0", "async-profiler", "nativemem", "example"} {
+		t.Run(jfr, func(t *testing.T) {
+			body := testutil.ReadGzipFile(t, jfr+".jfr.gz")
+			pi := &pprof.ParseInput{
+				StartTime:  time.Unix(1706241880, 0),
+				EndTime:    time.Unix(1706241890, 0),
+				SampleRate: 100,
+			}
+			expected, err := pprof.ParseJFR(body, pi, nil)
+			require.NoError(t, err)
+			actual, err := ParseJFR(body, WithThreadRoot(true))
+			require.NoError(t, err)
+
+			require.Equal(t, len(expected.Profiles), len(actual.Profiles))
+			assert.Equal(t, expected.JFREvent, actual.JFREvent)
+			for i, e := range expected.Profiles {
+				a := actual.Profiles[i]
+				assert.Equal(t, e.Metric, a.Metric)
+				require.Equal(t, len(e.Profile.SampleType), len(a.SampleTypes))
+				for vi, st := range e.Profile.SampleType {
+					assert.Equal(t, e.Profile.StringTable[st.Type], a.SampleTypes[vi].Type)
+					assert.Equal(t, e.Profile.StringTable[st.Unit], a.SampleTypes[vi].Unit)
+					var expectedTotal, actualTotal int64
+					for _, s := range e.Profile.Sample {
+						expectedTotal += s.Value[vi]
+					}
+					for _, s := range a.Stacks {
+						actualTotal += s.Values[vi]
+					}
+					assert.Equal(t, expectedTotal, actualTotal)
+				}
+				assert.True(t, slices.IsSortedFunc(a.Stacks, func(x, y Stack) int {
+					return slices.Compare(x.Frames, y.Frames)
+				}))
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	body := testutil.ReadGzipFile(t, "async-profiler.jfr.gz")
+	profiles, err := ParseJFR(body, WithThreadRoot(true), WithFrameTypes(true))
+	require.NoError(t, err)
+
+	var cpu *Profile
+	for i := range profiles.Profiles {
+		if profiles.Profiles[i].Metric == "process_cpu" {
+			cpu = &profiles.Profiles[i]
+		}
+	}
+	require.NotNil(t, cpu)
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, cpu.Write(buf, 0))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.NotEmpty(t, lines)
+	jit := 0
+	for _, line := range lines {
+		assert.True(t, strings.HasPrefix(line, "["), line)
+		if strings.Contains(line, "_[j];") {
+			jit++
+		}
+	}
+	assert.NotZero(t, jit)
+
+	assert.Error(t, cpu.Write(buf, 1))
+}
@@ -0,0 +1,372 @@
+// Package diff compares the pprof profiles of two JFR recordings, e.g. before
+// and after a deploy. Stacks are aligned by function name, so recordings of
+// different processes can be compared.
+package diff
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+
+	profilev1 "github.com/grafana/pyroscope/api/gen/proto/go/google/v1"
+
+	"github.com/grafana/jfr-parser/pprof"
+)
+
+type diffOptions struct {
+	normalize bool
+}
+
+type Option func(*diffOptions)
+
+// WithNormalize scales the values of the before profile so that its total equals
+// the total of the after profile, per sample type. Recordings of different length
+// or load then compare by their share of the total. Sample types with a zero total
+// in either profile are not scaled.
+func WithNormalize(v bool) Option {
+	return func(o *diffOptions) {
+		o.normalize = v
+	}
+}
+
+// Diff is the difference of the profiles with the same metric and sample types
+// of two recordings.
+type Diff struct {
+	Metric      string
+	SampleTypes []pprof.ValueType
+	// Profile is the differential profile, sample values are after minus before.
+	// Stacks with the same values in both profiles are left out.
+	Profile *profilev1.Profile
+	// Functions are ordered by name.
+	Functions []Function
+	// BeforeTotal and AfterTotal are the totals per sample type, BeforeTotal is normalized with WithNormalize.
+	BeforeTotal []int64
+	AfterTotal  []int64
+}
+
+// Function holds the self and total values of a function per sample type. Self
+// is the value of the samples the function is the leaf of, total the value of
+// the samples it is on the stack of.
+type Function struct {
+	Name        string
+	SelfBefore  []int64
+	SelfAfter   []int64
+	TotalBefore []int64
+	TotalAfter  []int64
+}
+
+// Regression is a row of Diff.Top, the values of a function for one sample type.
+type Regression struct {
+	Name        string
+	SelfBefore  int64
+	SelfAfter   int64
+	TotalBefore int64
+	TotalAfter  int64
+}
+
+func (r *Regression) SelfDelta() int64 {
+	return r.SelfAfter - r.SelfBefore
+}
+
+func (r *Regression) TotalDelta() int64 {
+	return r.TotalAfter - r.TotalBefore
+}
+
+// Top returns the n functions with the largest increase of their self value at
+// valueIndex, largest first. Functions that did not increase are left out.
+func (d *Diff) Top(valueIndex, n int) []Regression {
+	var res []Regression
+	for _, f := range d.Functions {
+		r := Regression{
+			Name:        f.Name,
+			SelfBefore:  f.SelfBefore[valueIndex],
+			SelfAfter:   f.SelfAfter[valueIndex],
+			TotalBefore: f.TotalBefore[valueIndex],
+			TotalAfter:  f.TotalAfter[valueIndex],
+		}
+		if r.SelfDelta() > 0 {
+			res = append(res, r)
+		}
+	}
+	slices.SortStableFunc(res, func(a, b Regression) int {
+		return cmp.Compare(b.SelfDelta(), a.SelfDelta())
+	})
+	return res[:min(n, len(res))]
+}
+
+// Compare diffs the profiles of two recordings. Profiles are matched by metric and
+// sample types, a profile only one of the recordings has is compared to an empty one.
+// Diffs are ordered by metric and sample types.
+func Compare(before, after *pprof.Profiles, opts ...Option) []*Diff {
+	o := &diffOptions{}
+	for i := range opts {
+		opts[i](o)
+	}
+
+	builders := map[string]*builder{}
+	var keys []string
+	add := func(ps *pprof.Profiles, side int) {
+		for _, p := range ps.Profiles {
+			sampleTypes := make([]pprof.ValueType, 0, len(p.Profile.SampleType))
+			for _, st := range p.Profile.SampleType {
+				sampleTypes = append(sampleTypes, pprof.ValueType{
+					Type: p.Profile.StringTable[st.Type],
+					Unit: p.Profile.StringTable[st.Unit],
+				})
+			}
+			key := profileKey(p.Metric, sampleTypes)
+			b, ok := builders[key]
+			if !ok {
+				b = newBuilder(p.Metric, sampleTypes)
+				builders[key] = b
+				keys = append(keys, key)
+			}
+			b.add(p.Profile, side)
+		}
+	}
+	add(before, sideBefore)
+	add(after, sideAfter)
+
+	slices.Sort(keys)
+	res := make([]*Diff, 0, len(keys))
+	for _, key := range keys {
+		res = append(res, builders[key].build(o.normalize))
+	}
+	return res
+}
+
+func profileKey(metric string, sampleTypes []pprof.ValueType) string {
+	sb := strings.Builder{}
+	sb.WriteString(metric)
+	for _, st := range sampleTypes {
+		sb.WriteByte(0)
+		sb.WriteString(st.Type)
+		sb.WriteByte('/')
+		sb.WriteString(st.Unit)
+	}
+	return sb.String()
+}
+
+const (
+	sideBefore = 0
+	sideAfter  = 1
+)
+
+type stack struct {
+	// frames are function names, leaf first
+	frames []string
+	values [2][]int64
+}
+
+type builder struct {
+	metric      string
+	sampleTypes []pprof.ValueType
+	stacks      map[string]*stack
+	order       []*stack
+	periodType  pprof.ValueType
+	timeNanos   int64
+	duration    int64
+}
+
+func newBuilder(metric string, sampleTypes []pprof.ValueType) *builder {
+	return &builder{metric: metric, sampleTypes: sampleTypes, stacks: map[string]*stack{}}
+}
+
+func (b *builder) add(p *profilev1.Profile, side int) {
+	if side == sideAfter || b.timeNanos == 0 {
+		b.timeNanos, b.duration = p.TimeNanos, p.DurationNanos
+		if p.PeriodType != nil {
+			b.periodType = pprof.ValueType{Type: p.StringTable[p.PeriodType.Type], Unit: p.StringTable[p.PeriodType.Unit]}
+		}
+	}
+	functions := make(map[uint64]string, len(p.Function))
+	for _, f := range p.Function {
+		functions[f.Id] = p.StringTable[f.Name]
+	}
+	locations := make(map[uint64][]string, len(p.Location))
+	for _, l := range p.Location {
+		names := make([]string, 0, len(l.Line))
+		// lines are innermost first, like the locations of a sample
+		for _, line := range l.Line {
+			names = append(names, functions[line.FunctionId])
+		}
+		locations[l.Id] = names
+	}
+	var frames []string
+	sb := strings.Builder{}
+	for _, s := range p.Sample {
+		frames = frames[:0]
+		for _, id := range s.LocationId {
+			frames = append(frames, locations[id]...)
+		}
+		sb.Reset()
+		for _, f := range frames {
+			sb.WriteString(f)
+			sb.WriteByte(0)
+		}
+		st, ok := b.stacks[sb.String()]
+		if !ok {
+			st = &stack{frames: slices.Clone(frames)}
+			st.values[sideBefore] = make([]int64, len(b.sampleTypes))
+			st.values[sideAfter] = make([]int64, len(b.sampleTypes))
+			b.stacks[sb.String()] = st
+			b.order = append(b.order, st)
+		}
+		for i, v := range s.Value[:min(len(s.Value), len(b.sampleTypes))] {
+			st.values[side][i] += v
+		}
+	}
+}
+
+func (b *builder) build(normalize bool) *Diff {
+	n := len(b.sampleTypes)
+	d := &Diff{
+		Metric:      b.metric,
+		SampleTypes: b.sampleTypes,
+		BeforeTotal: make([]int64, n),
+		AfterTotal:  make([]int64, n),
+	}
+	for _, st := range b.order {
+		for i := range n {
+			d.BeforeTotal[i] += st.values[sideBefore][i]
+			d.AfterTotal[i] += st.values[sideAfter][i]
+		}
+	}
+	if normalize {
+		for i := range n {
+			// a profile only one recording has is not scaled to or from nothing
+			if d.BeforeTotal[i] == 0 || d.AfterTotal[i] == 0 {
+				continue
+			}
+			scale := float64(d.AfterTotal[i]) / float64(d.BeforeTotal[i])
+			d.BeforeTotal[i] = 0
+			for _, st := range b.order {
+				v := int64(math.Round(float64(st.values[sideBefore][i]) * scale))
+				st.values[sideBefore][i] = v
+				d.BeforeTotal[i] += v
+			}
+		}
+	}
+
+	pb := newProfileBuilder(b)
+	functions := map[string]*Function{}
+	function := func(name string) *Function {
+		f, ok := functions[name]
+		if !ok {
+			f = &Function{
+				Name:        name,
+				SelfBefore:  make([]int64, n),
+				SelfAfter:   make([]int64, n),
+				TotalBefore: make([]int64, n),
+				TotalAfter:  make([]int64, n),
+			}
+			functions[name] = f
+		}
+		return f
+	}
+	seen := map[string]struct{}{}
+	for _, st := range b.order {
+		pb.addSample(st)
+		if len(st.frames) == 0 {
+			continue
+		}
+		leaf := function(st.frames[0])
+		for i := range n {
+			leaf.SelfBefore[i] += st.values[sideBefore][i]
+			leaf.SelfAfter[i] += st.values[sideAfter][i]
+		}
+		// recursive functions count once per stack
+		clear(seen)
+		for _, name := range st.frames {
+			if _, ok := seen[name]; ok {
+				continue
+			}
+			seen[name] = struct{}{}
+			f := function(name)
+			for i := range n {
+				f.TotalBefore[i] += st.values[sideBefore][i]
+				f.TotalAfter[i] += st.values[sideAfter][i]
+			}
+		}
+	}
+	d.Profile = pb.Profile
+	d.Functions = make([]Function, 0, len(functions))
+	for _, f := range functions {
+		d.Functions = append(d.Functions, *f)
+	}
+	slices.SortFunc(d.Functions, func(a, b Function) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return d
+}
+
+// profileBuilder builds the differential profile with one location per function.
+type profileBuilder struct {
+	*profilev1.Profile
+	strings   map[string]int64
+	locations map[string]uint64
+}
+
+func newProfileBuilder(b *builder) *profileBuilder {
+	p := &profileBuilder{
+		Profile: &profilev1.Profile{
+			TimeNanos:     b.timeNanos,
+			DurationNanos: b.duration,
+			Mapping:       []*profilev1.Mapping{{Id: 1, HasFunctions: true}},
+		},
+		strings:   map[string]int64{},
+		locations: map[string]uint64{},
+	}
+	p.addString("")
+	for _, st := range b.sampleTypes {
+		p.SampleType = append(p.SampleType, &profilev1.ValueType{Type: p.addString(st.Type), Unit: p.addString(st.Unit)})
+	}
+	if b.periodType != (pprof.ValueType{}) {
+		p.PeriodType = &profilev1.ValueType{Type: p.addString(b.periodType.Type), Unit: p.addString(b.periodType.Unit)}
+	}
+	return p
+}
+
+func (p *profileBuilder) addString(s string) int64 {
+	i, ok := p.strings[s]
+	if !ok {
+		i = int64(len(p.StringTable))
+		p.strings[s] = i
+		p.StringTable = append(p.StringTable, s)
+	}
+	return i
+}
+
+func (p *profileBuilder) location(name string) uint64 {
+	if id, ok := p.locations[name]; ok {
+		return id
+	}
+	fn := &profilev1.Function{Id: uint64(len(p.Function)) + 1, Name: p.addString(name)}
+	p.Function = append(p.Function, fn)
+	loc := &profilev1.Location{
+		Id:        uint64(len(p.Location)) + 1,
+		MappingId: 1,
+		Line:      []*profilev1.Line{{FunctionId: fn.Id}},
+	}
+	p.Location = append(p.Location, loc)
+	p.locations[name] = loc.Id
+	return loc.Id
+}
+
+func (p *profileBuilder) addSample(st *stack) {
+	values := make([]int64, len(p.SampleType))
+	changed := false
+	for i := range values {
+		values[i] = st.values[sideAfter][i] - st.values[sideBefore][i]
+		changed = changed || values[i] != 0
+	}
+	if !changed {
+		return
+	}
+	s := &profilev1.Sample{LocationId: make([]uint64, 0, len(st.frames)), Value: values}
+	for _, name := range st.frames {
+		s.LocationId = append(s.LocationId, p.location(name))
+	}
+	p.Sample = append(p.Sample, s)
+}
@@ -0,0 +1,163 @@
+package diff
+
+import (
+	"testing"
+	"time"
+
+	profilev1 "github.com/grafana/pyroscope/api/gen/proto/go/google/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/jfr-parser/internal/testutil"
+	"github.com/grafana/jfr-parser/pprof"
+)
+
+func parseTestFile(t testing.TB, name string) *pprof.Profiles {
+	profiles, err := pprof.ParseJFR(testutil.ReadGzipFile(t, name+".jfr.gz"), &pprof.ParseInput{
+		StartTime:  time.Unix(1706241880, 0),
+		EndTime:    time.Unix(1706241890, 0),
+		SampleRate: 100,
+	}, nil)
+	require.NoError(t, err)
+	return profiles
+}
+
+// newProfile builds a cpu profile of stacks, frames are root first as in folded stacks.
+// functions lists the function names in the order of their ids.
+func newProfile(functions []string, stacks map[string]int64) *pprof.Profiles {
+	p := &profilev1.Profile{
+		StringTable: []string{"", "cpu", "nanoseconds"},
+		SampleType:  []*profilev1.ValueType{{Type: 1, Unit: 2}},
+		PeriodType:  &profilev1.ValueType{Type: 1, Unit: 2},
+	}
+	ids := map[string]uint64{}
+	for _, name := range functions {
+		id := uint64(len(p.Function)) + 1
+		p.Function = append(p.Function, &profilev1.Function{Id: id, Name: int64(len(p.StringTable))})
+		p.StringTable = append(p.StringTable, name)
+		p.Location = append(p.Location, &profilev1.Location{Id: id, Line: []*profilev1.Line{{FunctionId: id, Line: int64(id)}}})
+		ids[name] = id
+	}
+	for folded, v := range stacks {
+		s := &profilev1.Sample{Value: []int64{v}}
+		var frames []string
+		for i, f := 0, 0; i <= len(folded); i++ {
+			if i == len(folded) || folded[i] == ';' {
+				frames = append(frames, folded[f:i])
+				f = i + 1
+			}
+		}
+		for i := len(frames) - 1; i >= 0; i-- {
+			s.LocationId = append(s.LocationId, ids[frames[i]])
+		}
+		p.Sample = append(p.Sample, s)
+	}
+	return &pprof.Profiles{Profiles: []pprof.Profile{{Profile: p, Metric: "process_cpu"}}}
+}
+
+// collapse returns the folded stacks of a differential profile.
+func collapse(p *profilev1.Profile) map[string]int64 {
+	res := map[string]int64{}
+	for _, s := range p.Sample {
+		folded := ""
+		for i := len(s.LocationId) - 1; i >= 0; i-- {
+			l := p.Location[s.LocationId[i]-1]
+			if folded != "" {
+				folded += ";"
+			}
+			folded += p.StringTable[p.Function[l.Line[0].FunctionId-1].Name]
+		}
+		res[folded] += s.Value[0]
+	}
+	return res
+}
+
+func TestCompare(t *testing.T) {
+	before := newProfile([]string{"main", "a", "b"}, map[string]int64{
+		"main;a":   10,
+		"main;b":   20,
+		"main;a;b": 5,
+	})
+	// the same functions with other ids, b got slower and a is gone
+	after := newProfile([]string{"c", "b", "main"}, map[string]int64{
+		"main;b":   50,
+		"main;c":   5,
+		"main;c;b": 5,
+	})
+
+	diffs := Compare(before, after)
+	require.Len(t, diffs, 1)
+	d := diffs[0]
+	assert.Equal(t, "process_cpu", d.Metric)
+	assert.Equal(t, []pprof.ValueType{{Type: "cpu", Unit: "nanoseconds"}}, d.SampleTypes)
+	assert.Equal(t, []int64{35}, d.BeforeTotal)
+	assert.Equal(t, []int64{60}, d.AfterTotal)
+	assert.Equal(t, map[string]int64{
+		"main;a":   -10,
+		"main;b":   30,
+		"main;a;b": -5,
+		"main;c":   5,
+		"main;c;b": 5,
+	}, collapse(d.Profile))
+
+	top := d.Top(0, 10)
+	require.Len(t, top, 2)
+	assert.Equal(t, Regression{Name: "b", SelfBefore: 25, SelfAfter: 55, TotalBefore: 25, TotalAfter: 55}, top[0])
+	assert.Equal(t, int64(30), top[0].SelfDelta())
+	assert.Equal(t, Regression{Name: "c", SelfBefore: 0, SelfAfter: 5, TotalBefore: 0, TotalAfter: 10}, top[1])
+	assert.Len(t, d.Top(0, 1), 1)
+
+	names := make([]string, 0, len(d.Functions))
+	for _, f := range d.Functions {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{"a", "b", "c", "main"}, names)
+	assert.Equal(t, []int64{35}, d.Functions[3].TotalBefore)
+	assert.Equal(t, []int64{60}, d.Functions[3].TotalAfter)
+}
+
+func TestCompareNormalize(t *testing.T) {
+	before := newProfile([]string{"main", "a", "b"}, map[string]int64{"main;a": 10, "main;b": 10})
+	// twice as many samples, b takes a larger share
+	after := newProfile([]string{"main", "a", "b"}, map[string]int64{"main;a": 10, "main;b": 30})
+
+	d := Compare(before, after, WithNormalize(true))[0]
+	assert.Equal(t, []int64{40}, d.BeforeTotal)
+	assert.Equal(t, []int64{40}, d.AfterTotal)
+	assert.Equal(t, map[string]int64{"main;a": -10, "main;b": 10}, collapse(d.Profile))
+	top := d.Top(0, 10)
+	require.Len(t, top, 1)
+	assert.Equal(t, "b", top[0].Name)
+	assert.Equal(t, int64(10), top[0].SelfDelta())
+}
+
+func TestCompareNormalizeBeforeOnly(t *testing.T) {
+	before := newProfile([]string{"main", "a", "b"}, map[string]int64{"main;a": 10, "main;b": 30})
+
+	d := Compare(before, &pprof.Profiles{}, WithNormalize(true))
+	require.Len(t, d, 1)
+	assert.Equal(t, []int64{40}, d[0].BeforeTotal)
+	assert.Equal(t, []int64{0}, d[0].AfterTotal)
+	assert.Equal(t, map[string]int64{"main;a": -10, "main;b": -30}, collapse(d[0].Profile))
+}
+
+func TestCompareRecordings(t *testing.T) {
+	a := parseTestFile(t, "async-profiler")
+	d := Compare(a, a)
+	require.Len(t, d, len(a.Profiles))
+	for _, p := range d {
+		assert.Empty(t, p.Profile.Sample, p.Metric)
+		assert.Equal(t, p.BeforeTotal, p.AfterTotal)
+		assert.Empty(t, p.Top(0, 10))
+	}
+
+	// profiles only one recording has are diffed against an empty profile
+	b := parseTestFile(t, "example")
+	for _, p := range Compare(a, b) {
+		var delta int64
+		for _, s := range p.Profile.Sample {
+			delta += s.Value[0]
+		}
+		assert.Equal(t, p.AfterTotal[0]-p.BeforeTotal[0], delta, p.Metric)
+	}
+}
@@ -0,0 +1,161 @@
+// Package flamegraph renders pprof profiles converted from JFR recordings as a
+// single self-contained interactive HTML flame graph.
+package flamegraph
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"slices"
+	"strings"
+
+	profilev1 "github.com/grafana/pyroscope/api/gen/proto/go/google/v1"
+
+	"github.com/grafana/jfr-parser/pprof"
+)
+
+//go:embed flamegraph.html
+var page string
+
+var pageTemplate = template.Must(template.New("flamegraph").Parse(page))
+
+// Node is a frame of the call tree. Values are the totals of the samples
+// with the frame on their stack, one per sample type of the profile.
+type Node struct {
+	Name     string
+	Values   []int64
+	Children []*Node
+
+	index map[string]*Node
+}
+
+func (n *Node) child(name string, nValues int) *Node {
+	if c, ok := n.index[name]; ok {
+		return c
+	}
+	if n.index == nil {
+		n.index = make(map[string]*Node)
+	}
+	c := &Node{Name: name, Values: make([]int64, nValues)}
+	n.index[name] = c
+	n.Children = append(n.Children, c)
+	return c
+}
+
+func (n *Node) sort() {
+	n.index = nil
+	slices.SortFunc(n.Children, func(a, b *Node) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	for _, c := range n.Children {
+		c.sort()
+	}
+}
+
+// NewTree merges the samples of p into a call tree rooted at a node named "all".
+// Children are ordered by name.
+func NewTree(p *profilev1.Profile) *Node {
+	nValues := len(p.SampleType)
+	root := &Node{Name: "all", Values: make([]int64, nValues)}
+	functions := make(map[uint64]string, len(p.Function))
+	for _, f := range p.Function {
+		functions[f.Id] = p.StringTable[f.Name]
+	}
+	locations := make(map[uint64][]string, len(p.Location))
+	for _, l := range p.Location {
+		names := make([]string, 0, len(l.Line))
+		// lines are innermost first, like the locations of a sample
+		for i := len(l.Line) - 1; i >= 0; i-- {
+			names = append(names, functions[l.Line[i].FunctionId])
+		}
+		locations[l.Id] = names
+	}
+	for _, s := range p.Sample {
+		n := root
+		addValues(n.Values, s.Value)
+		for i := len(s.LocationId) - 1; i >= 0; i-- {
+			for _, name := range locations[s.LocationId[i]] {
+				n = n.child(name, nValues)
+				addValues(n.Values, s.Value)
+			}
+		}
+	}
+	root.sort()
+	return root
+}
+
+func addValues(dst, src []int64) {
+	for i := range min(len(dst), len(src)) {
+		dst[i] += src[i]
+	}
+}
+
+// View is a selectable flame graph of the page, a sample type of a profile.
+type View struct {
+	Name string `json:"name"`
+	Unit string `json:"unit"`
+	// Tree and Value index into the trees of the page and the values of their nodes.
+	Tree  int `json:"tree"`
+	Value int `json:"value"`
+}
+
+type pageData struct {
+	Names []string `json:"names"`
+	Views []View   `json:"views"`
+	// Trees are nested [name index, values, children] arrays, which keeps large profiles small.
+	Trees []any `json:"trees"`
+}
+
+// WriteHTML writes a page with a flame graph per profile and sample type. The
+// page has a search box and selects the sample type to show, starting with the
+// sample type at valueIndex of the first profile.
+func WriteHTML(w io.Writer, title string, profiles []pprof.Profile, valueIndex int) error {
+	data := pageData{Names: []string{}, Views: []View{}, Trees: []any{}}
+	names := map[string]int{}
+	var encode func(n *Node) []any
+	encode = func(n *Node) []any {
+		i, ok := names[n.Name]
+		if !ok {
+			i = len(data.Names)
+			names[n.Name] = i
+			data.Names = append(data.Names, n.Name)
+		}
+		children := make([]any, 0, len(n.Children))
+		for _, c := range n.Children {
+			children = append(children, encode(c))
+		}
+		return []any{i, n.Values, children}
+	}
+	for _, p := range profiles {
+		tree := len(data.Trees)
+		data.Trees = append(data.Trees, encode(NewTree(p.Profile)))
+		for i, st := range p.Profile.SampleType {
+			data.Views = append(data.Views, View{
+				Name:  fmt.Sprintf("%s: %s", p.Metric, p.Profile.StringTable[st.Type]),
+				Unit:  p.Profile.StringTable[st.Unit],
+				Tree:  tree,
+				Value: i,
+			})
+		}
+	}
+	initialView := 0
+	if len(profiles) > 0 {
+		initialView = max(0, min(valueIndex, len(profiles[0].Profile.SampleType)-1))
+	}
+	js, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return pageTemplate.Execute(w, struct {
+		Title       string
+		Data        template.JS
+		InitialView int
+	}{
+		Title: title,
+		// json.Marshal escapes <, > and &, the data can not end the script element
+		Data:        template.JS(js),
+		InitialView: initialView,
+	})
+}
@@ -0,0 +1,75 @@
+package flamegraph
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	profilev1 "github.com/grafana/pyroscope/api/gen/proto/go/google/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/jfr-parser/internal/testutil"
+	"github.com/grafana/jfr-parser/pprof"
+)
+
+func TestNewTree(t *testing.T) {
+	pi := &pprof.ParseInput{
+		StartTime:  time.Unix(1706241880, 0),
+		EndTime:    time.Unix(1706241890, 0),
+		SampleRate: 100,
+	}
+	profiles, err := pprof.ParseJFR(testutil.ReadGzipFile(t, "async-profiler.jfr.gz"), pi, nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, profiles.Profiles)
+	for _, p := range profiles.Profiles {
+		tree := NewTree(p.Profile)
+		expected := make([]int64, len(p.Profile.SampleType))
+		for _, s := range p.Profile.Sample {
+			addValues(expected, s.Value)
+		}
+		assert.Equal(t, expected, tree.Values)
+
+		var check func(n *Node)
+		check = func(n *Node) {
+			children := make([]int64, len(n.Values))
+			for i, c := range n.Children {
+				if i > 0 {
+					require.Less(t, n.Children[i-1].Name, c.Name)
+				}
+				addValues(children, c.Values)
+				check(c)
+			}
+			for i := range children {
+				require.LessOrEqual(t, children[i], n.Values[i])
+			}
+		}
+		check(tree)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	p := &profilev1.Profile{
+		SampleType:  []*profilev1.ValueType{{Type: 1, Unit: 2}},
+		StringTable: []string{"", "cpu", "nanoseconds", "main", "</script><b>"},
+		Function:    []*profilev1.Function{{Id: 1, Name: 3}, {Id: 2, Name: 4}},
+		Location:    []*profilev1.Location{{Id: 1, Line: []*profilev1.Line{{FunctionId: 1}}}, {Id: 2, Line: []*profilev1.Line{{FunctionId: 2}}}},
+		Sample: []*profilev1.Sample{
+			{LocationId: []uint64{2, 1}, Value: []int64{3}},
+			{LocationId: []uint64{1}, Value: []int64{1}},
+		},
+	}
+	tree := NewTree(p)
+	require.Len(t, tree.Children, 1)
+	assert.Equal(t, "main", tree.Children[0].Name)
+	assert.Equal(t, []int64{4}, tree.Children[0].Values)
+	assert.Equal(t, []int64{3}, tree.Children[0].Children[0].Values)
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, WriteHTML(buf, "a <title>", []pprof.Profile{{Profile: p, Metric: "process_cpu"}}, 0))
+	html := buf.String()
+	assert.Contains(t, html, "<title>a &lt;title&gt;</title>")
+	assert.Contains(t, html, `"name":"process_cpu: cpu","unit":"nanoseconds"`)
+	assert.Equal(t, 1, strings.Count(html, "</script>"), "frame names must not end the script element")
+}
@@ -0,0 +1,581 @@
+// Package gecko converts JFR recordings into the processed profile format of
+// the Firefox Profiler (https://profiler.firefox.com), with a thread per
+// java.lang.Thread and markers for lock contention and parking.
+package gecko
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grafana/jfr-parser/internal/panics"
+	"github.com/grafana/jfr-parser/parser"
+	"github.com/grafana/jfr-parser/parser/types"
+	"github.com/grafana/jfr-parser/parser/types/def"
+)
+
+// PreprocessedProfileVersion is the processed profile format version written.
+// The Firefox Profiler upgrades older versions when loading a profile.
+const PreprocessedProfileVersion = 47
+
+type geckoOptions struct {
+	disablePanicRecovery bool
+}
+
+type Option func(*geckoOptions)
+
+func WithDisablePanicRecovery(v bool) Option {
+	return func(o *geckoOptions) {
+		o.disablePanicRecovery = v
+	}
+}
+
+// Categories of frames, stacks and markers, indices into Meta.Categories.
+const (
+	CategoryOther = iota
+	CategoryJava
+	CategoryNative
+	CategoryLock
+)
+
+var categories = []Category{
+	CategoryOther:  {Name: "Other", Color: "grey", Subcategories: []string{"Other"}},
+	CategoryJava:   {Name: "Java", Color: "yellow", Subcategories: []string{"Other"}},
+	CategoryNative: {Name: "Native", Color: "blue", Subcategories: []string{"Other"}},
+	CategoryLock:   {Name: "Lock", Color: "red", Subcategories: []string{"Other"}},
+}
+
+// Marker data types, see Meta.MarkerSchema.
+const (
+	MarkerMonitorEnter = "JavaMonitorEnter"
+	MarkerThreadPark   = "ThreadPark"
+)
+
+var markerSchema = []MarkerSchema{
+	{
+		Name:         MarkerMonitorEnter,
+		TooltipLabel: "Monitor enter {marker.data.class}",
+		TableLabel:   "{marker.data.class}",
+		ChartLabel:   "{marker.data.class}",
+		Display:      []string{"marker-chart", "marker-table", "timeline-overview"},
+		Fields:       []MarkerField{{Key: "class", Label: "Monitor class", Format: "string"}},
+	},
+	{
+		Name:         MarkerThreadPark,
+		TooltipLabel: "Park {marker.data.class}",
+		TableLabel:   "{marker.data.class}",
+		ChartLabel:   "{marker.data.class}",
+		Display:      []string{"marker-chart", "marker-table", "timeline-overview"},
+		Fields:       []MarkerField{{Key: "class", Label: "Parked class", Format: "string"}},
+	},
+}
+
+// Profile is a processed profile. Times are in milliseconds, Meta.StartTime
+// is a unix timestamp and all other times are relative to it.
+type Profile struct {
+	Meta     Meta     `json:"meta"`
+	Libs     []any    `json:"libs"`
+	Pages    []any    `json:"pages"`
+	Counters []any    `json:"counters"`
+	Threads  []Thread `json:"threads"`
+}
+
+type Meta struct {
+	Interval                   float64        `json:"interval"`
+	StartTime                  float64        `json:"startTime"`
+	EndTime                    float64        `json:"endTime,omitempty"`
+	ProcessType                int            `json:"processType"`
+	Product                    string         `json:"product"`
+	Stackwalk                  int            `json:"stackwalk"`
+	Version                    int            `json:"version"`
+	PreprocessedProfileVersion int            `json:"preprocessedProfileVersion"`
+	Symbolicated               bool           `json:"symbolicated"`
+	Categories                 []Category     `json:"categories"`
+	MarkerSchema               []MarkerSchema `json:"markerSchema"`
+}
+
+type Category struct {
+	Name          string   `json:"name"`
+	Color         string   `json:"color"`
+	Subcategories []string `json:"subcategories"`
+}
+
+type MarkerSchema struct {
+	Name         string        `json:"name"`
+	TooltipLabel string        `json:"tooltipLabel,omitempty"`
+	TableLabel   string        `json:"tableLabel,omitempty"`
+	ChartLabel   string        `json:"chartLabel,omitempty"`
+	Display      []string      `json:"display"`
+	Fields       []MarkerField `json:"fields"`
+}
+
+type MarkerField struct {
+	Key    string `json:"key"`
+	Label  string `json:"label"`
+	Format string `json:"format"`
+}
+
+// Thread holds the tables of a thread. The tables are structs of arrays,
+// every column of a table has Length entries.
+type Thread struct {
+	Name                string        `json:"name"`
+	ProcessType         string        `json:"processType"`
+	ProcessStartupTime  float64       `json:"processStartupTime"`
+	ProcessShutdownTime *float64      `json:"processShutdownTime"`
+	RegisterTime        float64       `json:"registerTime"`
+	UnregisterTime      *float64      `json:"unregisterTime"`
+	PausedRanges        []any         `json:"pausedRanges"`
+	IsMainThread        bool          `json:"isMainThread"`
+	PID                 string        `json:"pid"`
+	TID                 uint64        `json:"tid"`
+	Samples             SamplesTable  `json:"samples"`
+	Markers             MarkersTable  `json:"markers"`
+	StackTable          StackTable    `json:"stackTable"`
+	FrameTable          FrameTable    `json:"frameTable"`
+	FuncTable           FuncTable     `json:"funcTable"`
+	ResourceTable       ResourceTable `json:"resourceTable"`
+	NativeSymbols       NativeSymbols `json:"nativeSymbols"`
+	StringArray         []string      `json:"stringArray"`
+}
+
+type SamplesTable struct {
+	Length     int       `json:"length"`
+	Stack      []*int    `json:"stack"`
+	Time       []float64 `json:"time"`
+	Weight     []int64   `json:"weight"`
+	WeightType string    `json:"weightType"`
+}
+
+// sort orders the samples by time, JFR events are not necessarily written in order.
+func (s *SamplesTable) sort() {
+	idx := make([]int, s.Length)
+	for i := range idx {
+		idx[i] = i
+	}
+	slices.SortStableFunc(idx, func(x, y int) int {
+		return cmp.Compare(s.Time[x], s.Time[y])
+	})
+	stack := make([]*int, s.Length)
+	times := make([]float64, s.Length)
+	for i, j := range idx {
+		stack[i] = s.Stack[j]
+		times[i] = s.Time[j]
+	}
+	s.Stack, s.Time = stack, times
+}
+
+type MarkersTable struct {
+	Length    int        `json:"length"`
+	Data      []any      `json:"data"`
+	Name      []int      `json:"name"`
+	StartTime []float64  `json:"startTime"`
+	EndTime   []*float64 `json:"endTime"`
+	Phase     []int      `json:"phase"`
+	Category  []int      `json:"category"`
+}
+
+type StackTable struct {
+	Length      int    `json:"length"`
+	Frame       []int  `json:"frame"`
+	Prefix      []*int `json:"prefix"`
+	Category    []int  `json:"category"`
+	Subcategory []int  `json:"subcategory"`
+}
+
+type FrameTable struct {
+	Length         int       `json:"length"`
+	Address        []int     `json:"address"`
+	InlineDepth    []int     `json:"inlineDepth"`
+	Category       []int     `json:"category"`
+	Subcategory    []int     `json:"subcategory"`
+	Func           []int     `json:"func"`
+	NativeSymbol   []*int    `json:"nativeSymbol"`
+	InnerWindowID  []*int    `json:"innerWindowID"`
+	Implementation []*string `json:"implementation"`
+	Line           []*int    `json:"line"`
+	Column         []*int    `json:"column"`
+}
+
+type FuncTable struct {
+	Length        int    `json:"length"`
+	Name          []int  `json:"name"`
+	IsJS          []bool `json:"isJS"`
+	RelevantForJS []bool `json:"relevantForJS"`
+	Resource      []int  `json:"resource"`
+	FileName      []*int `json:"fileName"`
+	LineNumber    []*int `json:"lineNumber"`
+	ColumnNumber  []*int `json:"columnNumber"`
+}
+
+type ResourceTable struct {
+	Length int   `json:"length"`
+	Lib    []int `json:"lib"`
+	Name   []int `json:"name"`
+	Host   []int `json:"host"`
+	Type   []int `json:"type"`
+}
+
+type NativeSymbols struct {
+	Length       int   `json:"length"`
+	LibIndex     []int `json:"libIndex"`
+	Address      []int `json:"address"`
+	Name         []int `json:"name"`
+	FunctionSize []int `json:"functionSize"`
+}
+
+// Marker phases.
+const (
+	PhaseInstant  = 0
+	PhaseInterval = 1
+)
+
+// Write writes the profile as JSON.
+func (p *Profile) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(p)
+}
+
+func ParseJFR(body []byte, opts ...Option) (res *Profile, err error) {
+	o := &geckoOptions{}
+	for i := range opts {
+		opts[i](o)
+	}
+	if !o.disablePanicRecovery {
+		defer panics.Recover(&err)
+	}
+	p := parser.NewParser(body, parser.Options{
+		SymbolProcessor: parser.ProcessSymbols,
+	})
+	return parse(p)
+}
+
+func parse(p *parser.Parser) (*Profile, error) {
+	b := newBuilder(p)
+	for {
+		typ, err := p.ParseEvent()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("jfr parser ParseEvent error: %w", err)
+		}
+		b.setStart()
+		switch typ {
+		case p.TypeMap.T_EXECUTION_SAMPLE:
+			e := &p.ExecutionSample
+			b.addSample(p.TimestampNanos(typ, "startTime", int64(e.StartTime)), e.SampledThread, e.StackTrace)
+		case p.TypeMap.T_MONITOR_ENTER:
+			e := &p.JavaMonitorEnter
+			b.addMarker(MarkerMonitorEnter, "monitor enter", p.TimestampNanos(typ, "startTime", int64(e.StartTime)),
+				p.TimespanNanos(typ, "duration", int64(e.Duration)), e.EventThread, e.MonitorClass)
+		case p.TypeMap.T_THREAD_PARK:
+			e := &p.ThreadPark
+			b.addMarker(MarkerThreadPark, "park", p.TimestampNanos(typ, "startTime", int64(e.StartTime)),
+				p.TimespanNanos(typ, "duration", int64(e.Duration)), e.EventThread, e.ParkedClass)
+		case p.TypeMap.T_ACTIVE_SETTING:
+			b.addSetting()
+		case p.TypeMap.T_JVM_INFORMATION:
+			b.pid = fmt.Sprintf("%d", p.JVMInformation.Pid)
+		}
+	}
+	return b.build(), nil
+}
+
+type builder struct {
+	parser     *parser.Parser
+	startNanos int64
+	endNanos   int64
+	interval   float64
+	pid        string
+	threads    map[parser.ThreadKey]*threadBuilder
+
+	chunk parser.ChunkHeader
+}
+
+func newBuilder(p *parser.Parser) *builder {
+	return &builder{
+		parser:   p,
+		interval: 10,
+		pid:      "0",
+		threads:  make(map[parser.ThreadKey]*threadBuilder),
+	}
+}
+
+// setStart uses the start of the first chunk as the origin of the profile.
+func (b *builder) setStart() {
+	h := b.parser.ChunkHeader()
+	if h == b.chunk {
+		return
+	}
+	b.chunk = h
+	if b.startNanos == 0 {
+		b.startNanos = int64(h.StartNanos)
+	}
+	b.endNanos = max(b.endNanos, int64(h.StartNanos)+int64(h.DurationNanos))
+}
+
+func (b *builder) ms(nanos int64) float64 {
+	return float64(nanos-b.startNanos) / 1e6
+}
+
+// addSetting reads the sampling interval from the jdk.ExecutionSample period, e.g. "20 ms".
+func (b *builder) addSetting() {
+	s := &b.parser.ActiveSetting
+	if s.Name != "period" || def.TypeID(s.Id) != b.parser.TypeMap.T_EXECUTION_SAMPLE {
+		return
+	}
+	d, err := time.ParseDuration(strings.ReplaceAll(s.Value, " ", ""))
+	if err == nil && d > 0 {
+		b.interval = float64(d.Nanoseconds()) / 1e6
+	}
+}
+
+func (b *builder) addSample(nanos int64, thread types.ThreadRef, ref types.StackTraceRef) {
+	t := b.thread(thread)
+	if t == nil {
+		return
+	}
+	s := &t.t.Samples
+	s.Stack = append(s.Stack, t.stack(b.parser, ref))
+	s.Time = append(s.Time, b.ms(nanos))
+	s.Length++
+}
+
+func (b *builder) addMarker(typ, name string, nanos, durationNanos int64, thread types.ThreadRef, class types.ClassRef) {
+	t := b.thread(thread)
+	if t == nil {
+		return
+	}
+	data := map[string]any{"type": typ}
+	if cls := b.parser.GetClass(class); cls != nil {
+		data["class"] = strings.ReplaceAll(b.parser.GetSymbolString(cls.Name), "/", ".")
+	}
+	end := b.ms(nanos + durationNanos)
+	m := &t.t.Markers
+	m.Data = append(m.Data, data)
+	m.Name = append(m.Name, t.str(name))
+	m.StartTime = append(m.StartTime, b.ms(nanos))
+	m.EndTime = append(m.EndTime, &end)
+	m.Phase = append(m.Phase, PhaseInterval)
+	m.Category = append(m.Category, CategoryLock)
+	m.Length++
+}
+
+// thread returns the builder of a Java thread.
+func (b *builder) thread(ref types.ThreadRef) *threadBuilder {
+	jt := b.parser.GetThread(ref)
+	if jt == nil {
+		return nil
+	}
+	k := parser.ThreadKeyOf(jt)
+	if t, ok := b.threads[k]; ok {
+		return t
+	}
+	name := jt.JavaName
+	if name == "" {
+		name = jt.OsName
+	}
+	t := newThreadBuilder(b.parser, name, k.ID)
+	b.threads[k] = t
+	return t
+}
+
+// build returns the profile with the threads ordered by parser.CompareThreadKeys.
+func (b *builder) build() *Profile {
+	res := &Profile{
+		Meta: Meta{
+			Interval:                   b.interval,
+			StartTime:                  float64(b.startNanos) / 1e6,
+			EndTime:                    float64(b.endNanos) / 1e6,
+			Product:                    "JFR",
+			Version:                    27,
+			PreprocessedProfileVersion: PreprocessedProfileVersion,
+			Symbolicated:               true,
+			Categories:                 categories,
+			MarkerSchema:               markerSchema,
+		},
+		Libs:     []any{},
+		Pages:    []any{},
+		Counters: []any{},
+		Threads:  []Thread{},
+	}
+	keys := make([]parser.ThreadKey, 0, len(b.threads))
+	for k := range b.threads {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, parser.CompareThreadKeys)
+	for _, k := range keys {
+		t := b.threads[k].t
+		t.Samples.sort()
+		t.PID = b.pid
+		t.IsMainThread = t.Name == "main"
+		res.Threads = append(res.Threads, t)
+	}
+	return res
+}
+
+type frameKey struct {
+	fn       int
+	line     int
+	category int
+}
+
+type stackKey struct {
+	prefix int
+	frame  int
+}
+
+type threadBuilder struct {
+	t       Thread
+	strings map[string]int
+	funcs   map[string]int
+	frames  map[frameKey]int
+	stacks  map[stackKey]int
+
+	// chunkStacks are the stack table indices of the stack trace refs of the current chunk
+	chunkStacks *parser.StackCache[*int]
+}
+
+func newThreadBuilder(p *parser.Parser, name string, tid uint64) *threadBuilder {
+	return &threadBuilder{
+		t: Thread{
+			Name:         name,
+			ProcessType:  "default",
+			PausedRanges: []any{},
+			TID:          tid,
+			Samples:      SamplesTable{Stack: []*int{}, Time: []float64{}, WeightType: "samples"},
+			Markers: MarkersTable{
+				Data: []any{}, Name: []int{}, StartTime: []float64{}, EndTime: []*float64{}, Phase: []int{}, Category: []int{},
+			},
+			StackTable: StackTable{Frame: []int{}, Prefix: []*int{}, Category: []int{}, Subcategory: []int{}},
+			FrameTable: FrameTable{
+				Address: []int{}, InlineDepth: []int{}, Category: []int{}, Subcategory: []int{}, Func: []int{},
+				NativeSymbol: []*int{}, InnerWindowID: []*int{}, Implementation: []*string{}, Line: []*int{}, Column: []*int{},
+			},
+			FuncTable: FuncTable{
+				Name: []int{}, IsJS: []bool{}, RelevantForJS: []bool{}, Resource: []int{},
+				FileName: []*int{}, LineNumber: []*int{}, ColumnNumber: []*int{},
+			},
+			ResourceTable: ResourceTable{Lib: []int{}, Name: []int{}, Host: []int{}, Type: []int{}},
+			NativeSymbols: NativeSymbols{LibIndex: []int{}, Address: []int{}, Name: []int{}, FunctionSize: []int{}},
+			StringArray:   []string{},
+		},
+		strings:     make(map[string]int),
+		funcs:       make(map[string]int),
+		frames:      make(map[frameKey]int),
+		stacks:      make(map[stackKey]int),
+		chunkStacks: parser.NewStackCache[*int](p),
+	}
+}
+
+func (t *threadBuilder) str(s string) int {
+	i, ok := t.strings[s]
+	if !ok {
+		i = len(t.t.StringArray)
+		t.strings[s] = i
+		t.t.StringArray = append(t.t.StringArray, s)
+	}
+	return i
+}
+
+// stack returns the stack table index of the leaf frame of ref, nil for an empty stack.
+func (t *threadBuilder) stack(p *parser.Parser, ref types.StackTraceRef) *int {
+	s, _ := t.chunkStacks.Get(ref, func(st *types.StackTrace) *int {
+		prefix := -1
+		for i := len(st.Frames) - 1; i >= 0; i-- {
+			f := st.Frames[i]
+			m := p.GetMethod(f.Method)
+			if m == nil {
+				continue
+			}
+			name, ok := p.FrameName(m)
+			if !ok {
+				continue
+			}
+			category := CategoryJava
+			if ft := p.GetFrameType(f.Type); ft != nil {
+				switch ft.Description {
+				case "Native", "C++", "Kernel":
+					category = CategoryNative
+				}
+			}
+			frame := t.frame(t.function(name, category == CategoryJava), int(f.LineNumber), category)
+			prefix = t.stackEntry(prefix, frame, category)
+		}
+		if prefix < 0 {
+			return nil
+		}
+		return &prefix
+	})
+	return s
+}
+
+func (t *threadBuilder) function(name string, java bool) int {
+	i, ok := t.funcs[name]
+	if ok {
+		return i
+	}
+	ft := &t.t.FuncTable
+	i = ft.Length
+	t.funcs[name] = i
+	ft.Name = append(ft.Name, t.str(name))
+	ft.IsJS = append(ft.IsJS, java)
+	ft.RelevantForJS = append(ft.RelevantForJS, false)
+	ft.Resource = append(ft.Resource, -1)
+	ft.FileName = append(ft.FileName, nil)
+	ft.LineNumber = append(ft.LineNumber, nil)
+	ft.ColumnNumber = append(ft.ColumnNumber, nil)
+	ft.Length++
+	return i
+}
+
+func (t *threadBuilder) frame(fn, line, category int) int {
+	k := frameKey{fn: fn, line: line, category: category}
+	i, ok := t.frames[k]
+	if ok {
+		return i
+	}
+	ft := &t.t.FrameTable
+	i = ft.Length
+	t.frames[k] = i
+	var l *int
+	if line > 0 {
+		l = &line
+	}
+	ft.Address = append(ft.Address, -1)
+	ft.InlineDepth = append(ft.InlineDepth, 0)
+	ft.Category = append(ft.Category, category)
+	ft.Subcategory = append(ft.Subcategory, 0)
+	ft.Func = append(ft.Func, fn)
+	ft.NativeSymbol = append(ft.NativeSymbol, nil)
+	ft.InnerWindowID = append(ft.InnerWindowID, nil)
+	ft.Implementation = append(ft.Implementation, nil)
+	ft.Line = append(ft.Line, l)
+	ft.Column = append(ft.Column, nil)
+	ft.Length++
+	return i
+}
+
+func (t *threadBuilder) stackEntry(prefix, frame, category int) int {
+	k := stackKey{prefix: prefix, frame: frame}
+	i, ok := t.stacks[k]
+	if ok {
+		return i
+	}
+	st := &t.t.StackTable
+	i = st.Length
+	t.stacks[k] = i
+	var pre *int
+	if prefix >= 0 {
+		pre = &prefix
+	}
+	st.Frame = append(st.Frame, frame)
+	st.Prefix = append(st.Prefix, pre)
+	st.Category = append(st.Category, category)
+	st.Subcategory = append(st.Subcategory, 0)
+	st.Length++
+	return i
+}
@@ -0,0 +1,94 @@
+package gecko
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/jfr-parser/internal/testutil"
+	"github.com/grafana/jfr-parser/parser"
+)
+
+func countEvents(t *testing.T, body []byte) (samples, locks int) {
+	p := parser.NewParser(body, parser.Options{})
+	for {
+		typ, err := p.ParseEvent()
+		if err == io.EOF {
+			return samples, locks
+		}
+		require.NoError(t, err)
+		switch typ {
+		case p.TypeMap.T_EXECUTION_SAMPLE:
+			samples++
+		case p.TypeMap.T_MONITOR_ENTER, p.TypeMap.T_THREAD_PARK:
+			locks++
+		}
+	}
+}
+
+func TestParseJFR(t *testing.T) {
+	for _, jfr := range []string{"async-profiler", "object-allocation-sample"} {
+		t.Run(jfr, func(t *testing.T) {
+			body := testutil.ReadGzipFile(t, jfr+".jfr.gz")
+			expectedSamples, expectedLocks := countEvents(t, body)
+			profile, err := ParseJFR(body)
+			require.NoError(t, err)
+			require.NotEmpty(t, profile.Threads)
+
+			samples, locks := 0, 0
+			for _, th := range profile.Threads {
+				s := th.Samples
+				require.Len(t, s.Stack, s.Length)
+				require.Len(t, s.Time, s.Length)
+				for i := 1; i < s.Length; i++ {
+					require.LessOrEqual(t, s.Time[i-1], s.Time[i])
+				}
+				samples += s.Length
+				locks += th.Markers.Length
+				require.Len(t, th.Markers.EndTime, th.Markers.Length)
+
+				st := th.StackTable
+				require.Len(t, st.Frame, st.Length)
+				require.Len(t, st.Prefix, st.Length)
+				for i := range st.Length {
+					if st.Prefix[i] != nil {
+						require.Less(t, *st.Prefix[i], i)
+					}
+					require.Less(t, st.Frame[i], th.FrameTable.Length)
+				}
+				require.Len(t, th.FrameTable.Line, th.FrameTable.Length)
+				for _, f := range th.FrameTable.Func {
+					require.Less(t, f, th.FuncTable.Length)
+				}
+				for _, n := range th.FuncTable.Name {
+					require.Less(t, n, len(th.StringArray))
+				}
+			}
+			assert.Equal(t, expectedSamples, samples)
+			assert.Equal(t, expectedLocks, locks)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	profile, err := ParseJFR(testutil.ReadGzipFile(t, "object-allocation-sample.jfr.gz"))
+	require.NoError(t, err)
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, profile.Write(buf))
+
+	var decoded struct {
+		Meta struct {
+			PreprocessedProfileVersion int     `json:"preprocessedProfileVersion"`
+			Interval                   float64 `json:"interval"`
+		} `json:"meta"`
+		Threads []map[string]any `json:"threads"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, PreprocessedProfileVersion, decoded.Meta.PreprocessedProfileVersion)
+	assert.Equal(t, 9.0, decoded.Meta.Interval)
+	assert.Len(t, decoded.Threads, len(profile.Threads))
+}
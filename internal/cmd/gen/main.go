@@ -18,7 +18,7 @@ func main() {
 	}))
 	write("types/stackframe.go", generate(&Type_jdk_types_StackFrame, options{
 		skipFields: []string{
-			"bytecodeIndex", "type",
+			"bytecodeIndex",
 		},
 		cpool: false,
 	}))
@@ -63,9 +63,41 @@ func main() {
 		cpool: true,
 	}))
 
+	write("types/virtual_space.go", generate(&Type_jdk_types_VirtualSpace, options{}))
+	write("types/gc_name.go", generate(&Type_jdk_types_GCName, options{
+		cpool: true,
+	}))
+	write("types/gc_cause.go", generate(&Type_jdk_types_GCCause, options{
+		cpool: true,
+	}))
+	write("types/gc_when.go", generate(&Type_jdk_types_GCWhen, options{
+		cpool: true,
+	}))
+	write("types/thread_group.go", generate(&Type_jdk_types_ThreadGroup, options{
+		cpool: true,
+	}))
+	write("types/compiler_type.go", generate(&Type_jdk_types_CompilerType, options{
+		cpool: true,
+	}))
+	write("types/deoptimization_reason.go", generate(&Type_jdk_types_DeoptimizationReason, options{
+		cpool: true,
+	}))
+	write("types/deoptimization_action.go", generate(&Type_jdk_types_DeoptimizationAction, options{
+		cpool: true,
+	}))
+	write("types/bytecode.go", generate(&Type_jdk_types_Bytecode, options{
+		cpool: true,
+	}))
+	write("types/vm_operation_type.go", generate(&Type_jdk_types_VMOperationType, options{
+		cpool: true,
+	}))
+
 	write("types/active_settings.go", generate(&Type_jdk_ActiveSetting, options{}))
 
 	write("types/execution_sample.go", generate(&Type_jdk_ExecutionSample, options{}))
+	write("types/native_method_sample.go", generate(&Type_jdk_NativeMethodSample, options{}))
+	write("types/cpu_time_sample.go", generate(&Type_jdk_CPUTimeSample, options{}))
+	write("types/cpu_time_samples_lost.go", generate(&Type_jdk_CPUTimeSamplesLost, options{}))
 	write("types/wall_clock_sample.go", generate(&Type_profiler_WallClockSample, options{}))
 	write("types/malloc.go", generate(&Type_profiler_Malloc, options{}))
 	write("types/free.go", generate(&Type_profiler_Free, options{}))
@@ -75,6 +107,35 @@ func main() {
 	write("types/monitor_enter.go", generate(&Type_jdk_JavaMonitorEnter, options{}))
 	write("types/thread_park.go", generate(&Type_jdk_ThreadPark, options{}))
 	write("types/live_object.go", generate(&Type_profiler_LiveObject, options{}))
+	write("types/cpu_load.go", generate(&Type_jdk_CPULoad, options{}))
+	write("types/thread_cpu_load.go", generate(&Type_jdk_ThreadCPULoad, options{}))
+	write("types/java_exception_throw.go", generate(&Type_jdk_JavaExceptionThrow, options{}))
+	write("types/java_error_throw.go", generate(&Type_jdk_JavaErrorThrow, options{}))
+	write("types/exception_statistics.go", generate(&Type_jdk_ExceptionStatistics, options{}))
+	write("types/socket_read.go", generate(&Type_jdk_SocketRead, options{}))
+	write("types/socket_write.go", generate(&Type_jdk_SocketWrite, options{}))
+	write("types/file_read.go", generate(&Type_jdk_FileRead, options{}))
+	write("types/file_write.go", generate(&Type_jdk_FileWrite, options{}))
+	write("types/virtual_thread_pinned.go", generate(&Type_jdk_VirtualThreadPinned, options{}))
+	write("types/virtual_thread_submit_failed.go", generate(&Type_jdk_VirtualThreadSubmitFailed, options{}))
+	write("types/virtual_thread_start.go", generate(&Type_jdk_VirtualThreadStart, options{}))
+	write("types/virtual_thread_end.go", generate(&Type_jdk_VirtualThreadEnd, options{}))
+	write("types/compilation.go", generate(&Type_jdk_Compilation, options{}))
+	write("types/compilation_failure.go", generate(&Type_jdk_CompilationFailure, options{}))
+	write("types/deoptimization.go", generate(&Type_jdk_Deoptimization, options{}))
+	write("types/safepoint_begin.go", generate(&Type_jdk_SafepointBegin, options{}))
+	write("types/safepoint_state_synchronization.go", generate(&Type_jdk_SafepointStateSynchronization, options{}))
+	write("types/safepoint_end.go", generate(&Type_jdk_SafepointEnd, options{}))
+	write("types/execute_vm_operation.go", generate(&Type_jdk_ExecuteVMOperation, options{}))
+	write("types/jvm_information.go", generate(&Type_jdk_JVMInformation, options{}))
+	write("types/os_information.go", generate(&Type_jdk_OSInformation, options{}))
+	write("types/cpu_information.go", generate(&Type_jdk_CPUInformation, options{}))
+	write("types/initial_system_property.go", generate(&Type_jdk_InitialSystemProperty, options{}))
+	write("types/garbage_collection.go", generate(&Type_jdk_GarbageCollection, options{}))
+	write("types/gc_heap_summary.go", generate(&Type_jdk_GCHeapSummary, options{}))
+	write("types/gc_phase_pause.go", generate(&Type_jdk_GCPhasePause, options{}))
+	write("types/young_garbage_collection.go", generate(&Type_jdk_YoungGarbageCollection, options{}))
+	write("types/old_garbage_collection.go", generate(&Type_jdk_OldGarbageCollection, options{}))
 	write("types/skipper.go", generate(&def.Class{
 		Name:   "SkipConstantPool",
 		ID:     0,
@@ -128,6 +189,26 @@ func TypeForCPoolID(ID def.TypeID) *def.Class {
 		return &Type_jdk_types_ClassLoader
 	case T_STACK_FRAME:
 		return &Type_jdk_types_StackFrame
+	case T_VIRTUAL_SPACE:
+		return &Type_jdk_types_VirtualSpace
+	case T_GC_NAME:
+		return &Type_jdk_types_GCName
+	case T_GC_CAUSE:
+		return &Type_jdk_types_GCCause
+	case T_GC_WHEN:
+		return &Type_jdk_types_GCWhen
+	case T_THREAD_GROUP:
+		return &Type_jdk_types_ThreadGroup
+	case T_COMPILER_TYPE:
+		return &Type_jdk_types_CompilerType
+	case T_DEOPTIMIZATION_REASON:
+		return &Type_jdk_types_DeoptimizationReason
+	case T_DEOPTIMIZATION_ACTION:
+		return &Type_jdk_types_DeoptimizationAction
+	case T_BYTECODE:
+		return &Type_jdk_types_Bytecode
+	case T_VM_OPERATION_TYPE:
+		return &Type_jdk_types_VMOperationType
 	default:
 		panic("unknown type " + TypeID2Sym(ID))
 	}
@@ -169,12 +250,12 @@ func generate(typ *def.Class, opt options) string {
 	res += fmt.Sprintf("\n")
 
 	var receiver string
+	extraBinds := ""
+	compoudBindings := getNonBasicFields(typ)
+	for _, binding := range compoudBindings {
+		extraBinds += fmt.Sprintf(", bind%s *%s", name(TypeForCPoolID(binding.Type)), bindName(TypeForCPoolID(binding.Type)))
+	}
 	if opt.cpool {
-		extraBinds := ""
-		compoudBindings := getNonBasicFields(typ)
-		for _, binding := range compoudBindings {
-			extraBinds += fmt.Sprintf(", bind%s *%s", name(TypeForCPoolID(binding.Type)), bindName(TypeForCPoolID(binding.Type)))
-		}
 		if opt.doNotKeepData {
 
 		} else {
@@ -189,7 +270,7 @@ func generate(typ *def.Class, opt options) string {
 	} else {
 		receiver = fmt.Sprintf("this")
 
-		res += fmt.Sprintf("func (this *%s) Parse(data []byte, bind *%s, typeMap *def.TypeMap) (pos int, err error) {\n", name(typ), bindName(typ))
+		res += fmt.Sprintf("func (this *%s) Parse(data []byte, bind *%s %s, typeMap *def.TypeMap) (pos int, err error) {\n", name(typ), bindName(typ), extraBinds)
 	}
 	_ = receiver
 
@@ -274,7 +355,7 @@ func generateBindLoop(typ *def.Class, bindName string, nestedAllowed bool) strin
 	res += fmt.Sprintf("	if %s.Fields[%sFieldIndex].Field.Array {\n", bindName, bindName)
 	res += emitReadI32()
 	res += fmt.Sprintf("		%sArraySize = int(v32_)\n", bindName)
-	if len(complexFields) > 0 {
+	if len(complexFields) > 0 && complexFields[0].Array {
 		res += fmt.Sprintf("		if %s.Fields[%sFieldIndex].Field.Type == typeMap.%s {\n", bindName, bindName, TypeID2Sym(complexFields[0].Type))
 		res += fmt.Sprintf("			*%s.Fields[%sFieldIndex].%s = make([]%s, 0, %sArraySize)\n",
 			bindName, bindName, name(TypeForCPoolID(complexFields[0].Type)), name(TypeForCPoolID(complexFields[0].Type)), bindName)
@@ -346,7 +427,7 @@ func generateBindLoop(typ *def.Class, bindName string, nestedAllowed bool) strin
 		res += fmt.Sprintf("			// skipping\n")
 	}
 	res += fmt.Sprintf("		case typeMap.T_FLOAT:\n")
-	res += emitReadI32()
+	res += emitReadF32()
 	if fieldsHas(fs, T_FLOAT) {
 		res += fmt.Sprintf("			if %s.Fields[%sFieldIndex].float32 != nil {\n", bindName, bindName)
 		res += fmt.Sprintf("				*%s.Fields[%sFieldIndex].float32 = *(*float32)(unsafe.Pointer(&v32_))\n", bindName, bindName)
@@ -354,6 +435,8 @@ func generateBindLoop(typ *def.Class, bindName string, nestedAllowed bool) strin
 	} else {
 		res += fmt.Sprintf("			// skipping\n")
 	}
+	res += fmt.Sprintf("		case typeMap.T_DOUBLE:\n")
+	res += emitSkip(8)
 	if nestedAllowed {
 		for _, field := range complexFields {
 			nestedType := TypeForCPoolID(field.Type)
@@ -364,7 +447,9 @@ func generateBindLoop(typ *def.Class, bindName string, nestedAllowed bool) strin
 				res += fmt.Sprintf("				*%s.Fields[%sFieldIndex].%s = append(*%s.Fields[%sFieldIndex].%s, bind%s.Temp)\n", bindName, bindName, name(nestedType), bindName, bindName, name(nestedType), name(nestedType))
 				res += fmt.Sprintf("			}\n")
 			} else {
-				panic("TODO " + field.String())
+				res += fmt.Sprintf("			if %s.Fields[%sFieldIndex].%s != nil {\n", bindName, bindName, name(nestedType))
+				res += fmt.Sprintf("				*%s.Fields[%sFieldIndex].%s = bind%s.Temp\n", bindName, bindName, name(nestedType), name(nestedType))
+				res += fmt.Sprintf("			}\n")
 			}
 		}
 	}
@@ -389,7 +474,9 @@ func generateBindLoop(typ *def.Class, bindName string, nestedAllowed bool) strin
 	res += fmt.Sprintf("					} else if %sSkipFieldType == typeMap.T_INT {\n", bindName)
 	res += emitReadI32()
 	res += fmt.Sprintf("					} else if %sSkipFieldType == typeMap.T_FLOAT {\n", bindName)
-	res += emitReadI32()
+	res += emitReadF32()
+	res += fmt.Sprintf("					} else if %sSkipFieldType == typeMap.T_DOUBLE {\n", bindName)
+	res += emitSkip(8)
 	res += fmt.Sprintf("					} else if %sSkipFieldType == typeMap.T_LONG {\n", bindName)
 	res += emitReadU64()
 	res += fmt.Sprintf("					} else if %sSkipFieldType == typeMap.T_SHORT {\n", bindName)
@@ -530,6 +617,12 @@ func emitString() string {
 	res += "case 1:\n"
 	res += "	break\n"
 
+	res += "case 2:\n"
+	res += emitReadU64()
+	res += "	if typeMap.StringConstant != nil {\n"
+	res += "		s_ = typeMap.StringConstant(v64_)\n"
+	res += "	}\n"
+
 	res += "case 3:\n"
 	res += emitReadI32()
 	res += "	if pos+int(v32_) > l {\n"
@@ -616,6 +709,26 @@ func emitReadI32() string {
 	return code
 }
 
+// emitReadF32 reads the raw bits of a float, which unlike integers are not varint encoded.
+func emitReadF32() string {
+	code := ""
+	code += "if pos+4 > l {\n"
+	code += "	return 0, io.ErrUnexpectedEOF\n"
+	code += "}\n"
+	code += "v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])\n"
+	code += "pos += 4\n"
+	return code
+}
+
+func emitSkip(n int) string {
+	code := ""
+	code += fmt.Sprintf("if pos+%d > l {\n", n)
+	code += "	return 0, io.ErrUnexpectedEOF\n"
+	code += "}\n"
+	code += fmt.Sprintf("pos += %d\n", n)
+	return code
+}
+
 func emitReadU64() string {
 	code := ""
 
@@ -660,6 +773,8 @@ func goTypeName(field def.Field) string {
 		return "bool"
 	case T_STACK_FRAME:
 		return "StackFrame" //todo make it generic
+	case T_VIRTUAL_SPACE:
+		return "VirtualSpace"
 	default:
 		panic("TODO " + field.String())
 	}
@@ -7,57 +7,94 @@ import (
 )
 
 var (
-	T_METADATA                = def.TypeID(0)
-	T_CPOOL                   = def.TypeID(1)
-	T_BOOLEAN                 = def.TypeID(4)
-	T_CHAR                    = def.TypeID(5)
-	T_FLOAT                   = def.TypeID(6)
-	T_DOUBLE                  = def.TypeID(7)
-	T_BYTE                    = def.TypeID(8)
-	T_SHORT                   = def.TypeID(9)
-	T_INT                     = def.TypeID(10)
-	T_LONG                    = def.TypeID(11)
-	T_STRING                  = def.TypeID(20)
-	T_CLASS                   = def.TypeID(21)
-	T_THREAD                  = def.TypeID(22)
-	T_CLASS_LOADER            = def.TypeID(23)
-	T_FRAME_TYPE              = def.TypeID(24)
-	T_THREAD_STATE            = def.TypeID(25)
-	T_STACK_TRACE             = def.TypeID(26)
-	T_STACK_FRAME             = def.TypeID(27)
-	T_METHOD                  = def.TypeID(28)
-	T_PACKAGE                 = def.TypeID(29)
-	T_SYMBOL                  = def.TypeID(30)
-	T_LOG_LEVEL               = def.TypeID(31)
-	T_EVENT                   = def.TypeID(100)
-	T_EXECUTION_SAMPLE        = def.TypeID(101)
-	T_ALLOC_IN_NEW_TLAB       = def.TypeID(102)
-	T_ALLOC_OUTSIDE_TLAB      = def.TypeID(103)
-	T_MONITOR_ENTER           = def.TypeID(104)
-	T_THREAD_PARK             = def.TypeID(105)
-	T_CPU_LOAD                = def.TypeID(106)
-	T_ACTIVE_RECORDING        = def.TypeID(107)
-	T_ACTIVE_SETTING          = def.TypeID(108)
-	T_OS_INFORMATION          = def.TypeID(109)
-	T_CPU_INFORMATION         = def.TypeID(110)
-	T_JVM_INFORMATION         = def.TypeID(111)
-	T_INITIAL_SYSTEM_PROPERTY = def.TypeID(112)
-	T_NATIVE_LIBRARY          = def.TypeID(113)
-	T_LOG                     = def.TypeID(114)
-	T_LIVE_OBJECT             = def.TypeID(115)
-	T_WALL_CLOCK_SAMPLE       = def.TypeID(118)
-	T_MALLOC                  = def.TypeID(119)
-	T_FREE                    = def.TypeID(120)
-	T_ANNOTATION              = def.TypeID(200)
-	T_LABEL                   = def.TypeID(201)
-	T_CATEGORY                = def.TypeID(202)
-	T_TIMESTAMP               = def.TypeID(203)
-	T_TIMESPAN                = def.TypeID(204)
-	T_DATA_AMOUNT             = def.TypeID(205)
-	T_MEMORY_ADDRESS          = def.TypeID(206)
-	T_UNSIGNED                = def.TypeID(207)
-	T_PERCENTAGE              = def.TypeID(208)
-	T_ALLOC_SAMPLE            = def.TypeID(209)
+	T_METADATA                        = def.TypeID(0)
+	T_CPOOL                           = def.TypeID(1)
+	T_BOOLEAN                         = def.TypeID(4)
+	T_CHAR                            = def.TypeID(5)
+	T_FLOAT                           = def.TypeID(6)
+	T_DOUBLE                          = def.TypeID(7)
+	T_BYTE                            = def.TypeID(8)
+	T_SHORT                           = def.TypeID(9)
+	T_INT                             = def.TypeID(10)
+	T_LONG                            = def.TypeID(11)
+	T_STRING                          = def.TypeID(20)
+	T_CLASS                           = def.TypeID(21)
+	T_THREAD                          = def.TypeID(22)
+	T_CLASS_LOADER                    = def.TypeID(23)
+	T_FRAME_TYPE                      = def.TypeID(24)
+	T_THREAD_STATE                    = def.TypeID(25)
+	T_STACK_TRACE                     = def.TypeID(26)
+	T_STACK_FRAME                     = def.TypeID(27)
+	T_METHOD                          = def.TypeID(28)
+	T_PACKAGE                         = def.TypeID(29)
+	T_SYMBOL                          = def.TypeID(30)
+	T_LOG_LEVEL                       = def.TypeID(31)
+	T_VIRTUAL_SPACE                   = def.TypeID(32)
+	T_GC_NAME                         = def.TypeID(33)
+	T_GC_CAUSE                        = def.TypeID(34)
+	T_GC_WHEN                         = def.TypeID(35)
+	T_THREAD_GROUP                    = def.TypeID(36)
+	T_COMPILER_TYPE                   = def.TypeID(37)
+	T_DEOPTIMIZATION_REASON           = def.TypeID(38)
+	T_DEOPTIMIZATION_ACTION           = def.TypeID(39)
+	T_BYTECODE                        = def.TypeID(40)
+	T_VM_OPERATION_TYPE               = def.TypeID(41)
+	T_EVENT                           = def.TypeID(100)
+	T_EXECUTION_SAMPLE                = def.TypeID(101)
+	T_ALLOC_IN_NEW_TLAB               = def.TypeID(102)
+	T_ALLOC_OUTSIDE_TLAB              = def.TypeID(103)
+	T_MONITOR_ENTER                   = def.TypeID(104)
+	T_THREAD_PARK                     = def.TypeID(105)
+	T_CPU_LOAD                        = def.TypeID(106)
+	T_ACTIVE_RECORDING                = def.TypeID(107)
+	T_ACTIVE_SETTING                  = def.TypeID(108)
+	T_OS_INFORMATION                  = def.TypeID(109)
+	T_CPU_INFORMATION                 = def.TypeID(110)
+	T_JVM_INFORMATION                 = def.TypeID(111)
+	T_INITIAL_SYSTEM_PROPERTY         = def.TypeID(112)
+	T_NATIVE_LIBRARY                  = def.TypeID(113)
+	T_LOG                             = def.TypeID(114)
+	T_LIVE_OBJECT                     = def.TypeID(115)
+	T_WALL_CLOCK_SAMPLE               = def.TypeID(118)
+	T_MALLOC                          = def.TypeID(119)
+	T_FREE                            = def.TypeID(120)
+	T_CPU_TIME_SAMPLE                 = def.TypeID(121)
+	T_CPU_TIME_SAMPLES_LOST           = def.TypeID(122)
+	T_NATIVE_METHOD_SAMPLE            = def.TypeID(123)
+	T_GARBAGE_COLLECTION              = def.TypeID(124)
+	T_GC_HEAP_SUMMARY                 = def.TypeID(125)
+	T_GC_PHASE_PAUSE                  = def.TypeID(126)
+	T_YOUNG_GC                        = def.TypeID(127)
+	T_OLD_GC                          = def.TypeID(128)
+	T_THREAD_CPU_LOAD                 = def.TypeID(129)
+	T_JAVA_EXCEPTION_THROW            = def.TypeID(130)
+	T_JAVA_ERROR_THROW                = def.TypeID(131)
+	T_EXCEPTION_STATISTICS            = def.TypeID(132)
+	T_SOCKET_READ                     = def.TypeID(133)
+	T_SOCKET_WRITE                    = def.TypeID(134)
+	T_FILE_READ                       = def.TypeID(135)
+	T_FILE_WRITE                      = def.TypeID(136)
+	T_VIRTUAL_THREAD_PINNED           = def.TypeID(137)
+	T_VIRTUAL_THREAD_SUBMIT_FAILED    = def.TypeID(138)
+	T_VIRTUAL_THREAD_START            = def.TypeID(139)
+	T_VIRTUAL_THREAD_END              = def.TypeID(140)
+	T_COMPILATION                     = def.TypeID(141)
+	T_COMPILATION_FAILURE             = def.TypeID(142)
+	T_DEOPTIMIZATION                  = def.TypeID(143)
+	T_SAFEPOINT_BEGIN                 = def.TypeID(144)
+	T_SAFEPOINT_STATE_SYNCHRONIZATION = def.TypeID(145)
+	T_SAFEPOINT_END                   = def.TypeID(146)
+	T_EXECUTE_VM_OPERATION            = def.TypeID(147)
+	T_ANNOTATION                      = def.TypeID(200)
+	T_LABEL                           = def.TypeID(201)
+	T_CATEGORY                        = def.TypeID(202)
+	T_TIMESTAMP                       = def.TypeID(203)
+	T_TIMESPAN                        = def.TypeID(204)
+	T_DATA_AMOUNT                     = def.TypeID(205)
+	T_MEMORY_ADDRESS                  = def.TypeID(206)
+	T_UNSIGNED                        = def.TypeID(207)
+	T_PERCENTAGE                      = def.TypeID(208)
+	T_ALLOC_SAMPLE                    = def.TypeID(209)
 )
 
 func TypeID2Sym(id def.TypeID) string {
@@ -106,6 +143,26 @@ func TypeID2Sym(id def.TypeID) string {
 		return "T_SYMBOL"
 	case T_LOG_LEVEL:
 		return "T_LOG_LEVEL"
+	case T_VIRTUAL_SPACE:
+		return "T_VIRTUAL_SPACE"
+	case T_GC_NAME:
+		return "T_GC_NAME"
+	case T_GC_CAUSE:
+		return "T_GC_CAUSE"
+	case T_GC_WHEN:
+		return "T_GC_WHEN"
+	case T_THREAD_GROUP:
+		return "T_THREAD_GROUP"
+	case T_COMPILER_TYPE:
+		return "T_COMPILER_TYPE"
+	case T_DEOPTIMIZATION_REASON:
+		return "T_DEOPTIMIZATION_REASON"
+	case T_DEOPTIMIZATION_ACTION:
+		return "T_DEOPTIMIZATION_ACTION"
+	case T_BYTECODE:
+		return "T_BYTECODE"
+	case T_VM_OPERATION_TYPE:
+		return "T_VM_OPERATION_TYPE"
 	case T_EVENT:
 		return "T_EVENT"
 	case T_EXECUTION_SAMPLE:
@@ -140,6 +197,60 @@ func TypeID2Sym(id def.TypeID) string {
 		return "T_LOG"
 	case T_LIVE_OBJECT:
 		return "T_LIVE_OBJECT"
+	case T_CPU_TIME_SAMPLE:
+		return "T_CPU_TIME_SAMPLE"
+	case T_CPU_TIME_SAMPLES_LOST:
+		return "T_CPU_TIME_SAMPLES_LOST"
+	case T_NATIVE_METHOD_SAMPLE:
+		return "T_NATIVE_METHOD_SAMPLE"
+	case T_GARBAGE_COLLECTION:
+		return "T_GARBAGE_COLLECTION"
+	case T_GC_HEAP_SUMMARY:
+		return "T_GC_HEAP_SUMMARY"
+	case T_GC_PHASE_PAUSE:
+		return "T_GC_PHASE_PAUSE"
+	case T_YOUNG_GC:
+		return "T_YOUNG_GC"
+	case T_OLD_GC:
+		return "T_OLD_GC"
+	case T_THREAD_CPU_LOAD:
+		return "T_THREAD_CPU_LOAD"
+	case T_JAVA_EXCEPTION_THROW:
+		return "T_JAVA_EXCEPTION_THROW"
+	case T_JAVA_ERROR_THROW:
+		return "T_JAVA_ERROR_THROW"
+	case T_EXCEPTION_STATISTICS:
+		return "T_EXCEPTION_STATISTICS"
+	case T_SOCKET_READ:
+		return "T_SOCKET_READ"
+	case T_SOCKET_WRITE:
+		return "T_SOCKET_WRITE"
+	case T_FILE_READ:
+		return "T_FILE_READ"
+	case T_FILE_WRITE:
+		return "T_FILE_WRITE"
+	case T_VIRTUAL_THREAD_PINNED:
+		return "T_VIRTUAL_THREAD_PINNED"
+	case T_VIRTUAL_THREAD_SUBMIT_FAILED:
+		return "T_VIRTUAL_THREAD_SUBMIT_FAILED"
+	case T_VIRTUAL_THREAD_START:
+		return "T_VIRTUAL_THREAD_START"
+	case T_VIRTUAL_THREAD_END:
+		return "T_VIRTUAL_THREAD_END"
+	case T_COMPILATION:
+		return "T_COMPILATION"
+	case T_COMPILATION_FAILURE:
+		return "T_COMPILATION_FAILURE"
+	case T_DEOPTIMIZATION:
+		return "T_DEOPTIMIZATION"
+	case T_SAFEPOINT_BEGIN:
+		return "T_SAFEPOINT_BEGIN"
+	case T_SAFEPOINT_STATE_SYNCHRONIZATION:
+		return "T_SAFEPOINT_STATE_SYNCHRONIZATION"
+	case T_SAFEPOINT_END:
+		return "T_SAFEPOINT_END"
+	case T_EXECUTE_VM_OPERATION:
+		return "T_EXECUTE_VM_OPERATION"
 	case T_ANNOTATION:
 		return "T_ANNOTATION"
 	case T_LABEL:
@@ -226,6 +337,16 @@ var Type_java_lang_Thread = def.Class{
 		{Name: "osThreadId", Type: T_LONG, ConstantPool: false},
 		{Name: "javaName", Type: T_STRING, ConstantPool: false},
 		{Name: "javaThreadId", Type: T_LONG, ConstantPool: false},
+		{Name: "group", Type: T_THREAD_GROUP, ConstantPool: true},
+		{Name: "virtual", Type: T_BOOLEAN, ConstantPool: false},
+	},
+}
+var Type_jdk_types_ThreadGroup = def.Class{
+	Name: "jdk.types.ThreadGroup",
+	ID:   T_THREAD_GROUP,
+	Fields: []def.Field{
+		{Name: "parent", Type: T_THREAD_GROUP, ConstantPool: true},
+		{Name: "name", Type: T_STRING, ConstantPool: false},
 	},
 }
 var Type_jdk_types_ClassLoader = def.Class{
@@ -314,6 +435,171 @@ var Type_jdk_ExecutionSample = def.Class{
 	},
 }
 
+var Type_jdk_NativeMethodSample = def.Class{
+	Name: "jdk.NativeMethodSample",
+	ID:   T_NATIVE_METHOD_SAMPLE,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "sampledThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "state", Type: T_THREAD_STATE, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+	},
+}
+
+var Type_jdk_types_VirtualSpace = def.Class{
+	Name: "jdk.types.VirtualSpace",
+	ID:   T_VIRTUAL_SPACE,
+	Fields: []def.Field{
+		{Name: "start", Type: T_LONG, ConstantPool: false},
+		{Name: "committedEnd", Type: T_LONG, ConstantPool: false},
+		{Name: "committedSize", Type: T_LONG, ConstantPool: false},
+		{Name: "reservedEnd", Type: T_LONG, ConstantPool: false},
+		{Name: "reservedSize", Type: T_LONG, ConstantPool: false},
+	},
+}
+
+var Type_jdk_types_GCName = def.Class{
+	Name: "jdk.types.GCName",
+	ID:   T_GC_NAME,
+	Fields: []def.Field{
+		{Name: "name", Type: T_STRING, ConstantPool: false},
+	},
+}
+
+var Type_jdk_types_GCCause = def.Class{
+	Name: "jdk.types.GCCause",
+	ID:   T_GC_CAUSE,
+	Fields: []def.Field{
+		{Name: "cause", Type: T_STRING, ConstantPool: false},
+	},
+}
+
+var Type_jdk_types_GCWhen = def.Class{
+	Name: "jdk.types.GCWhen",
+	ID:   T_GC_WHEN,
+	Fields: []def.Field{
+		{Name: "when", Type: T_STRING, ConstantPool: false},
+	},
+}
+
+var Type_jdk_types_CompilerType = def.Class{
+	Name: "jdk.types.CompilerType",
+	ID:   T_COMPILER_TYPE,
+	Fields: []def.Field{
+		{Name: "compiler", Type: T_STRING, ConstantPool: false},
+	},
+}
+var Type_jdk_types_DeoptimizationReason = def.Class{
+	Name: "jdk.types.DeoptimizationReason",
+	ID:   T_DEOPTIMIZATION_REASON,
+	Fields: []def.Field{
+		{Name: "reason", Type: T_STRING, ConstantPool: false},
+	},
+}
+var Type_jdk_types_DeoptimizationAction = def.Class{
+	Name: "jdk.types.DeoptimizationAction",
+	ID:   T_DEOPTIMIZATION_ACTION,
+	Fields: []def.Field{
+		{Name: "action", Type: T_STRING, ConstantPool: false},
+	},
+}
+var Type_jdk_types_Bytecode = def.Class{
+	Name: "jdk.types.Bytecode",
+	ID:   T_BYTECODE,
+	Fields: []def.Field{
+		{Name: "bytecode", Type: T_STRING, ConstantPool: false},
+	},
+}
+var Type_jdk_types_VMOperationType = def.Class{
+	Name: "jdk.types.VMOperationType",
+	ID:   T_VM_OPERATION_TYPE,
+	Fields: []def.Field{
+		{Name: "type", Type: T_STRING, ConstantPool: false},
+	},
+}
+
+var Type_jdk_GarbageCollection = def.Class{
+	Name: "jdk.GarbageCollection",
+	ID:   T_GARBAGE_COLLECTION,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "gcId", Type: T_INT, ConstantPool: false},
+		{Name: "name", Type: T_GC_NAME, ConstantPool: true},
+		{Name: "cause", Type: T_GC_CAUSE, ConstantPool: true},
+		{Name: "sumOfPauses", Type: T_LONG, ConstantPool: false},
+		{Name: "longestPause", Type: T_LONG, ConstantPool: false},
+	},
+}
+
+var Type_jdk_GCHeapSummary = def.Class{
+	Name: "jdk.GCHeapSummary",
+	ID:   T_GC_HEAP_SUMMARY,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "gcId", Type: T_INT, ConstantPool: false},
+		{Name: "when", Type: T_GC_WHEN, ConstantPool: true},
+		{Name: "heapSpace", Type: T_VIRTUAL_SPACE, ConstantPool: false},
+		{Name: "heapUsed", Type: T_LONG, ConstantPool: false},
+	},
+}
+
+var Type_jdk_GCPhasePause = def.Class{
+	Name: "jdk.GCPhasePause",
+	ID:   T_GC_PHASE_PAUSE,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "gcId", Type: T_INT, ConstantPool: false},
+		{Name: "name", Type: T_STRING, ConstantPool: false},
+	},
+}
+
+var Type_jdk_YoungGarbageCollection = def.Class{
+	Name: "jdk.YoungGarbageCollection",
+	ID:   T_YOUNG_GC,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "gcId", Type: T_INT, ConstantPool: false},
+		{Name: "tenuringThreshold", Type: T_INT, ConstantPool: false},
+	},
+}
+
+var Type_jdk_OldGarbageCollection = def.Class{
+	Name: "jdk.OldGarbageCollection",
+	ID:   T_OLD_GC,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "gcId", Type: T_INT, ConstantPool: false},
+	},
+}
+
+var Type_jdk_CPUTimeSample = def.Class{
+	Name: "jdk.CPUTimeSample",
+	ID:   T_CPU_TIME_SAMPLE,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "failed", Type: T_BOOLEAN, ConstantPool: false},
+		{Name: "samplingPeriod", Type: T_LONG, ConstantPool: false},
+		{Name: "biased", Type: T_BOOLEAN, ConstantPool: false},
+	},
+}
+
+var Type_jdk_CPUTimeSamplesLost = def.Class{
+	Name: "jdk.CPUTimeSamplesLost",
+	ID:   T_CPU_TIME_SAMPLES_LOST,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "lostSamples", Type: T_INT, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+	},
+}
+
 var Type_profiler_WallClockSample = def.Class{
 	Name: "profiler.WallClockSample",
 	ID:   T_WALL_CLOCK_SAMPLE,
@@ -409,6 +695,236 @@ var Type_jdk_CPULoad = def.Class{
 		{Name: "machineTotal", Type: T_FLOAT, ConstantPool: false},
 	},
 }
+var Type_jdk_ThreadCPULoad = def.Class{
+	Name: "jdk.ThreadCPULoad",
+	ID:   T_THREAD_CPU_LOAD,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "user", Type: T_FLOAT, ConstantPool: false},
+		{Name: "system", Type: T_FLOAT, ConstantPool: false},
+	},
+}
+var Type_jdk_JavaExceptionThrow = def.Class{
+	Name: "jdk.JavaExceptionThrow",
+	ID:   T_JAVA_EXCEPTION_THROW,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "message", Type: T_STRING, ConstantPool: false},
+		{Name: "thrownClass", Type: T_CLASS, ConstantPool: true},
+	},
+}
+var Type_jdk_JavaErrorThrow = def.Class{
+	Name: "jdk.JavaErrorThrow",
+	ID:   T_JAVA_ERROR_THROW,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "message", Type: T_STRING, ConstantPool: false},
+		{Name: "thrownClass", Type: T_CLASS, ConstantPool: true},
+	},
+}
+var Type_jdk_ExceptionStatistics = def.Class{
+	Name: "jdk.ExceptionStatistics",
+	ID:   T_EXCEPTION_STATISTICS,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "throwables", Type: T_LONG, ConstantPool: false},
+	},
+}
+var Type_jdk_SocketRead = def.Class{
+	Name: "jdk.SocketRead",
+	ID:   T_SOCKET_READ,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "host", Type: T_STRING, ConstantPool: false},
+		{Name: "address", Type: T_STRING, ConstantPool: false},
+		{Name: "port", Type: T_INT, ConstantPool: false},
+		{Name: "timeout", Type: T_LONG, ConstantPool: false},
+		{Name: "bytesRead", Type: T_LONG, ConstantPool: false},
+		{Name: "endOfStream", Type: T_BOOLEAN, ConstantPool: false},
+	},
+}
+var Type_jdk_SocketWrite = def.Class{
+	Name: "jdk.SocketWrite",
+	ID:   T_SOCKET_WRITE,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "host", Type: T_STRING, ConstantPool: false},
+		{Name: "address", Type: T_STRING, ConstantPool: false},
+		{Name: "port", Type: T_INT, ConstantPool: false},
+		{Name: "bytesWritten", Type: T_LONG, ConstantPool: false},
+	},
+}
+var Type_jdk_FileRead = def.Class{
+	Name: "jdk.FileRead",
+	ID:   T_FILE_READ,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "path", Type: T_STRING, ConstantPool: false},
+		{Name: "bytesRead", Type: T_LONG, ConstantPool: false},
+		{Name: "endOfFile", Type: T_BOOLEAN, ConstantPool: false},
+	},
+}
+var Type_jdk_FileWrite = def.Class{
+	Name: "jdk.FileWrite",
+	ID:   T_FILE_WRITE,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "path", Type: T_STRING, ConstantPool: false},
+		{Name: "bytesWritten", Type: T_LONG, ConstantPool: false},
+	},
+}
+var Type_jdk_VirtualThreadPinned = def.Class{
+	Name: "jdk.VirtualThreadPinned",
+	ID:   T_VIRTUAL_THREAD_PINNED,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+	},
+}
+var Type_jdk_VirtualThreadSubmitFailed = def.Class{
+	Name: "jdk.VirtualThreadSubmitFailed",
+	ID:   T_VIRTUAL_THREAD_SUBMIT_FAILED,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "javaThreadId", Type: T_LONG, ConstantPool: false},
+		{Name: "exceptionMessage", Type: T_STRING, ConstantPool: false},
+	},
+}
+var Type_jdk_VirtualThreadStart = def.Class{
+	Name: "jdk.VirtualThreadStart",
+	ID:   T_VIRTUAL_THREAD_START,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "javaThreadId", Type: T_LONG, ConstantPool: false},
+	},
+}
+var Type_jdk_VirtualThreadEnd = def.Class{
+	Name: "jdk.VirtualThreadEnd",
+	ID:   T_VIRTUAL_THREAD_END,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "javaThreadId", Type: T_LONG, ConstantPool: false},
+	},
+}
+var Type_jdk_Compilation = def.Class{
+	Name: "jdk.Compilation",
+	ID:   T_COMPILATION,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "compileId", Type: T_INT, ConstantPool: false},
+		{Name: "compiler", Type: T_COMPILER_TYPE, ConstantPool: true},
+		{Name: "method", Type: T_METHOD, ConstantPool: true},
+		{Name: "compileLevel", Type: T_SHORT, ConstantPool: false},
+		{Name: "succeded", Type: T_BOOLEAN, ConstantPool: false},
+		{Name: "isOsr", Type: T_BOOLEAN, ConstantPool: false},
+		{Name: "codeSize", Type: T_LONG, ConstantPool: false},
+		{Name: "inlinedBytes", Type: T_LONG, ConstantPool: false},
+	},
+}
+var Type_jdk_CompilationFailure = def.Class{
+	Name: "jdk.CompilationFailure",
+	ID:   T_COMPILATION_FAILURE,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "failureMessage", Type: T_STRING, ConstantPool: false},
+		{Name: "compileId", Type: T_INT, ConstantPool: false},
+	},
+}
+var Type_jdk_Deoptimization = def.Class{
+	Name: "jdk.Deoptimization",
+	ID:   T_DEOPTIMIZATION,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "compileId", Type: T_INT, ConstantPool: false},
+		{Name: "compiler", Type: T_COMPILER_TYPE, ConstantPool: true},
+		{Name: "method", Type: T_METHOD, ConstantPool: true},
+		{Name: "lineNumber", Type: T_INT, ConstantPool: false},
+		{Name: "bci", Type: T_INT, ConstantPool: false},
+		{Name: "instruction", Type: T_BYTECODE, ConstantPool: true},
+		{Name: "reason", Type: T_DEOPTIMIZATION_REASON, ConstantPool: true},
+		{Name: "action", Type: T_DEOPTIMIZATION_ACTION, ConstantPool: true},
+	},
+}
+var Type_jdk_SafepointBegin = def.Class{
+	Name: "jdk.SafepointBegin",
+	ID:   T_SAFEPOINT_BEGIN,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "safepointId", Type: T_LONG, ConstantPool: false},
+		{Name: "totalThreadCount", Type: T_INT, ConstantPool: false},
+		{Name: "jniCriticalThreadCount", Type: T_INT, ConstantPool: false},
+	},
+}
+var Type_jdk_SafepointStateSynchronization = def.Class{
+	Name: "jdk.SafepointStateSynchronization",
+	ID:   T_SAFEPOINT_STATE_SYNCHRONIZATION,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "safepointId", Type: T_LONG, ConstantPool: false},
+		{Name: "initialThreadCount", Type: T_INT, ConstantPool: false},
+		{Name: "runningThreadCount", Type: T_INT, ConstantPool: false},
+		{Name: "iterations", Type: T_INT, ConstantPool: false},
+	},
+}
+var Type_jdk_SafepointEnd = def.Class{
+	Name: "jdk.SafepointEnd",
+	ID:   T_SAFEPOINT_END,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "safepointId", Type: T_LONG, ConstantPool: false},
+	},
+}
+var Type_jdk_ExecuteVMOperation = def.Class{
+	Name: "jdk.ExecuteVMOperation",
+	ID:   T_EXECUTE_VM_OPERATION,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "operation", Type: T_VM_OPERATION_TYPE, ConstantPool: true},
+		{Name: "safepoint", Type: T_BOOLEAN, ConstantPool: false},
+		{Name: "blocking", Type: T_BOOLEAN, ConstantPool: false},
+		{Name: "caller", Type: T_THREAD, ConstantPool: true},
+		{Name: "safepointId", Type: T_LONG, ConstantPool: false},
+	},
+}
 var Type_jdk_ActiveRecording = def.Class{
 	Name: "jdk.ActiveRecording",
 	ID:   T_ACTIVE_RECORDING,
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/grafana/jfr-parser/jvmstats"
+)
+
+// Usage: ./jfrparser cpuload [--threads] /path/to/jfr > cpuload.csv
+func runCPULoad(args []string) {
+	fs := flag.NewFlagSet("cpuload", flag.ExitOnError)
+	threads := fs.Bool("threads", false, "print jdk.ThreadCPULoad per thread instead of jdk.CPULoad")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	buf, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		panic(err)
+	}
+	s, err := jvmstats.ParseJFR(buf)
+	if err != nil {
+		panic(err)
+	}
+	w := csv.NewWriter(os.Stdout)
+	if *threads {
+		_ = w.Write([]string{"time", "thread", "user", "system"})
+		for _, l := range s.ThreadCPULoad {
+			_ = w.Write([]string{formatTime(l.TimestampNanos), l.Thread, formatLoad(l.User), formatLoad(l.System)})
+		}
+	} else {
+		_ = w.Write([]string{"time", "jvm_user", "jvm_system", "machine_total"})
+		for _, l := range s.CPULoad {
+			_ = w.Write([]string{formatTime(l.TimestampNanos), formatLoad(l.JVMUser), formatLoad(l.JVMSystem), formatLoad(l.MachineTotal)})
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		panic(err)
+	}
+}
+
+func formatTime(nanos int64) string {
+	return time.Unix(0, nanos).UTC().Format(time.RFC3339Nano)
+}
+
+func formatLoad(v float32) string {
+	return strconv.FormatFloat(float64(v), 'f', 4, 32)
+}
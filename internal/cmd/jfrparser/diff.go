@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/grafana/jfr-parser/diff"
+	"github.com/grafana/jfr-parser/pprof"
+)
+
+// Usage: ./jfrparser diff [--top N] [--normalize] /path/to/before.jfr /path/to/after.jfr [/path/to/dest]
+//
+// Writes a differential pprof per profile, <metric>.<i>.<dest base>, and prints the
+// functions with the largest increase per sample type.
+func runDiff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	top := fs.Int("top", 10, "number of functions to print per sample type, 0 for all")
+	normalize := fs.Bool("normalize", false, "scale the before recording to the total of the after recording per sample type")
+	_ = fs.Parse(args)
+	if fs.NArg() != 2 && fs.NArg() != 3 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	dest := fs.Arg(1) + ".diff.pprof"
+	if fs.NArg() == 3 {
+		dest = fs.Arg(2)
+	}
+
+	before, err := parseProfiles(fs.Arg(0))
+	if err != nil {
+		panic(err)
+	}
+	after, err := parseProfiles(fs.Arg(1))
+	if err != nil {
+		panic(err)
+	}
+	diffs := diff.Compare(before, after, diff.WithNormalize(*normalize))
+
+	for i, d := range diffs {
+		bs, err := d.Profile.MarshalVT()
+		if err != nil {
+			panic(err)
+		}
+		filename := filepath.Join(filepath.Dir(dest), fmt.Sprintf("%s.%d.%s", d.Metric, i, filepath.Base(dest)))
+		if err := os.WriteFile(filename, bs, 0644); err != nil {
+			panic(err)
+		}
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	writeDiffReport(w, diffs, *top)
+}
+
+func parseProfiles(name string) (*pprof.Profiles, error) {
+	buf, err := readMaybeGzipped(name)
+	if err != nil {
+		return nil, err
+	}
+	pi := &pprof.ParseInput{
+		StartTime:  time.Now(),
+		EndTime:    time.Now(),
+		SampleRate: 100,
+	}
+	return pprof.ParseJFR(buf, pi, nil)
+}
+
+func writeDiffReport(w io.Writer, diffs []*diff.Diff, top int) {
+	for i, d := range diffs {
+		for vi, st := range d.SampleTypes {
+			if i > 0 || vi > 0 {
+				fmt.Fprintf(w, "\n")
+			}
+			fmt.Fprintf(w, "%s %s (%s): %s -> %s (%s)\n", d.Metric, st.Type, st.Unit,
+				formatValue(d.BeforeTotal[vi], st.Unit), formatValue(d.AfterTotal[vi], st.Unit),
+				formatChange(d.BeforeTotal[vi], d.AfterTotal[vi]))
+			n := top
+			if n <= 0 {
+				n = len(d.Functions)
+			}
+			regressions := d.Top(vi, n)
+			if len(regressions) == 0 {
+				continue
+			}
+			tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(tw, "Function\tSelf Before\tSelf After\tSelf Delta\tTotal Before\tTotal After\tTotal Delta\n")
+			fmt.Fprintf(tw, "========\t===========\t==========\t==========\t============\t===========\t===========\n")
+			for _, r := range regressions {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t+%s\t%s\t%s\t%s\n", r.Name,
+					formatValue(r.SelfBefore, st.Unit), formatValue(r.SelfAfter, st.Unit), formatValue(r.SelfDelta(), st.Unit),
+					formatValue(r.TotalBefore, st.Unit), formatValue(r.TotalAfter, st.Unit), formatDelta(r.TotalDelta(), st.Unit))
+			}
+			_ = tw.Flush()
+		}
+	}
+}
+
+func formatValue(v int64, unit string) string {
+	if unit == "nanoseconds" {
+		return time.Duration(v).String()
+	}
+	return fmt.Sprintf("%d", v)
+}
+
+func formatDelta(v int64, unit string) string {
+	if v > 0 {
+		return "+" + formatValue(v, unit)
+	}
+	return formatValue(v, unit)
+}
+
+func formatChange(before, after int64) string {
+	if before == 0 {
+		return "new"
+	}
+	return fmt.Sprintf("%+.1f%%", 100*float64(after-before)/float64(before))
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/jfr-parser/diff"
+)
+
+func TestWriteDiffReport(t *testing.T) {
+	before, err := parseProfiles(testdataDir + "cortex-dev-01__kafka-0__cpu__0.jfr.gz")
+	require.NoError(t, err)
+	after, err := parseProfiles(testdataDir + "cortex-dev-01__kafka-0__cpu__1.jfr.gz")
+	require.NoError(t, err)
+	diffs := diff.Compare(before, after)
+	require.NotEmpty(t, diffs)
+
+	var buf bytes.Buffer
+	writeDiffReport(&buf, diffs, 3)
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 6)
+	assert.Contains(t, string(lines[0]), "process_cpu cpu (nanoseconds): ")
+	assert.Contains(t, string(lines[1]), "Function")
+	assert.Contains(t, string(lines[1]), "Self Delta")
+	for _, l := range lines[3:] {
+		assert.Contains(t, string(l), "+")
+	}
+}
@@ -0,0 +1,25 @@
+package format
+
+import (
+	"bytes"
+
+	"github.com/grafana/jfr-parser/chrometrace"
+)
+
+type FormatterChromeTrace struct{}
+
+func NewFormatterChromeTrace() *FormatterChromeTrace {
+	return &FormatterChromeTrace{}
+}
+
+func (f *FormatterChromeTrace) Format(buf []byte, dest string) ([]string, [][]byte, error) {
+	trace, err := chrometrace.ParseJFR(buf)
+	if err != nil {
+		return nil, nil, err
+	}
+	bs := bytes.NewBuffer(nil)
+	if err := trace.Write(bs); err != nil {
+		return nil, nil, err
+	}
+	return []string{dest}, [][]byte{bs.Bytes()}, nil
+}
@@ -0,0 +1,53 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/grafana/jfr-parser/collapsed"
+)
+
+type FormatterCollapsed struct {
+	valueIndex int
+	opts       []collapsed.Option
+}
+
+func NewFormatterCollapsed(valueIndex int, opts ...collapsed.Option) *FormatterCollapsed {
+	return &FormatterCollapsed{
+		valueIndex: valueIndex,
+		opts:       opts,
+	}
+}
+
+func (f *FormatterCollapsed) Format(buf []byte, dest string) ([]string, [][]byte, error) {
+	profiles, err := collapsed.ParseJFR(buf, f.opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data := make([][]byte, 0)
+	dests := make([]string, 0)
+	destDir := filepath.Dir(dest)
+	destBase := filepath.Base(dest)
+	slices.SortFunc(profiles.Profiles, func(i, j collapsed.Profile) int {
+		if c := strings.Compare(i.Metric, j.Metric); c != 0 {
+			return c
+		}
+		return strings.Compare(i.SampleTypes[0].Type, j.SampleTypes[0].Type)
+	})
+	for i := 0; i < len(profiles.Profiles); i++ {
+		p := &profiles.Profiles[i]
+		filename := fmt.Sprintf("%s.%d.%s", p.Metric, i, destBase)
+		dests = append(dests, filepath.Join(destDir, filename))
+
+		bs := bytes.NewBuffer(nil)
+		if err := p.Write(bs, f.valueIndex); err != nil {
+			return nil, nil, err
+		}
+		data = append(data, bs.Bytes())
+	}
+	return dests, data, nil
+}
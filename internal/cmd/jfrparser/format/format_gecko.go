@@ -0,0 +1,25 @@
+package format
+
+import (
+	"bytes"
+
+	"github.com/grafana/jfr-parser/gecko"
+)
+
+type FormatterGecko struct{}
+
+func NewFormatterGecko() *FormatterGecko {
+	return &FormatterGecko{}
+}
+
+func (f *FormatterGecko) Format(buf []byte, dest string) ([]string, [][]byte, error) {
+	profile, err := gecko.ParseJFR(buf)
+	if err != nil {
+		return nil, nil, err
+	}
+	bs := bytes.NewBuffer(nil)
+	if err := profile.Write(bs); err != nil {
+		return nil, nil, err
+	}
+	return []string{dest}, [][]byte{bs.Bytes()}, nil
+}
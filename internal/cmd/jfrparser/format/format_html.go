@@ -0,0 +1,36 @@
+package format
+
+import (
+	"bytes"
+	"path/filepath"
+	"time"
+
+	"github.com/grafana/jfr-parser/flamegraph"
+	"github.com/grafana/jfr-parser/pprof"
+)
+
+type FormatterHTML struct {
+	valueIndex int
+}
+
+func NewFormatterHTML(valueIndex int) *FormatterHTML {
+	return &FormatterHTML{valueIndex: valueIndex}
+}
+
+func (f *FormatterHTML) Format(buf []byte, dest string) ([]string, [][]byte, error) {
+	pi := &pprof.ParseInput{
+		StartTime:  time.Now(),
+		EndTime:    time.Now(),
+		SampleRate: 100,
+	}
+	profiles, err := pprof.ParseJFR(buf, pi, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	sortProfiles(profiles.Profiles)
+	bs := bytes.NewBuffer(nil)
+	if err := flamegraph.WriteHTML(bs, filepath.Base(dest), profiles.Profiles, f.valueIndex); err != nil {
+		return nil, nil, err
+	}
+	return []string{dest}, [][]byte{bs.Bytes()}, nil
+}
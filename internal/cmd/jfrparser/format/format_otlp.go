@@ -0,0 +1,31 @@
+package format
+
+import (
+	"time"
+
+	"github.com/grafana/jfr-parser/otlp"
+	"github.com/grafana/jfr-parser/pprof"
+)
+
+type FormatterOTLP struct{}
+
+func NewFormatterOTLP() *FormatterOTLP {
+	return &FormatterOTLP{}
+}
+
+func (f *FormatterOTLP) Format(buf []byte, dest string) ([]string, [][]byte, error) {
+	pi := &pprof.ParseInput{
+		StartTime:  time.Now(),
+		EndTime:    time.Now(),
+		SampleRate: 100,
+	}
+	data, err := otlp.ParseJFR(buf, pi, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	bs, err := data.Marshal()
+	if err != nil {
+		return nil, nil, err
+	}
+	return []string{dest}, [][]byte{bs}, nil
+}
@@ -0,0 +1,25 @@
+package format
+
+import (
+	"bytes"
+
+	"github.com/grafana/jfr-parser/speedscope"
+)
+
+type FormatterSpeedscope struct{}
+
+func NewFormatterSpeedscope() *FormatterSpeedscope {
+	return &FormatterSpeedscope{}
+}
+
+func (f *FormatterSpeedscope) Format(buf []byte, dest string) ([]string, [][]byte, error) {
+	file, err := speedscope.ParseJFR(buf)
+	if err != nil {
+		return nil, nil, err
+	}
+	bs := bytes.NewBuffer(nil)
+	if err := file.Write(bs); err != nil {
+		return nil, nil, err
+	}
+	return []string{dest}, [][]byte{bs.Bytes()}, nil
+}
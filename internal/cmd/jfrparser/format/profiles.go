@@ -0,0 +1,21 @@
+package format
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/grafana/jfr-parser/pprof"
+)
+
+// sortProfiles orders profiles by metric and first sample type, the order of the output files.
+func sortProfiles(profiles []pprof.Profile) {
+	slices.SortFunc(profiles, func(i, j pprof.Profile) int {
+		if c := strings.Compare(i.Metric, j.Metric); c != 0 {
+			return c
+		}
+		return strings.Compare(
+			i.Profile.StringTable[i.Profile.SampleType[0].Type],
+			j.Profile.StringTable[j.Profile.SampleType[0].Type],
+		)
+	})
+}
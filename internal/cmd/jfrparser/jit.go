@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/grafana/jfr-parser/jvmstats"
+)
+
+// Usage: ./jfrparser jit [--top N] /path/to/jfr
+func runJIT(args []string) {
+	fs := flag.NewFlagSet("jit", flag.ExitOnError)
+	top := fs.Int("top", 20, "number of methods to print, 0 for all")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	buf, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		panic(err)
+	}
+	s, err := jvmstats.ParseJFR(buf)
+	if err != nil {
+		panic(err)
+	}
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	writeJITReport(w, s.JITReport(), *top)
+}
+
+func writeJITReport(w io.Writer, r jvmstats.JITReport, top int) {
+	methods := r.Methods
+	if top > 0 && len(methods) > top {
+		methods = methods[:top]
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Method\tLevel\tCount\tFailed\tTotal\tMax\tCode Size (bytes)\n")
+	fmt.Fprintf(tw, "======\t=====\t=====\t======\t=====\t===\t=================\n")
+	for _, m := range methods {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t%s\t%d\n", m.Method, m.Level, m.Count, m.Failed,
+			time.Duration(m.TotalNanos), time.Duration(m.MaxNanos), m.CodeSize)
+	}
+	_ = tw.Flush()
+	if len(methods) < len(r.Methods) {
+		fmt.Fprintf(w, "... %d more\n", len(r.Methods)-len(methods))
+	}
+
+	fmt.Fprintf(w, "\n")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Deoptimization Reason\tAction\tCount\n")
+	fmt.Fprintf(tw, "=====================\t======\t=====\n")
+	for _, d := range r.Deoptimizations {
+		fmt.Fprintf(tw, "%s\t%s\t%d\n", d.Reason, d.Action, d.Count)
+	}
+	_ = tw.Flush()
+}
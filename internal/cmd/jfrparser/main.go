@@ -3,13 +3,18 @@ package main
 import (
 	"flag"
 	"fmt"
-	"github.com/grafana/jfr-parser/internal/cmd/jfrparser/format"
 	"os"
+
+	"github.com/grafana/jfr-parser/collapsed"
+	"github.com/grafana/jfr-parser/internal/cmd/jfrparser/format"
 )
 
 type command struct {
 	// Opts
-	format string
+	format     string
+	valueIndex int
+	threads    bool
+	frameTypes bool
 
 	// Args
 	src  string
@@ -17,7 +22,10 @@ type command struct {
 }
 
 func parseCommand(c *command) {
-	c.format = "pprof"
+	flag.StringVar(&c.format, "format", "pprof", "output format: pprof, collapsed, chrometrace, speedscope, gecko, otlp, html")
+	flag.IntVar(&c.valueIndex, "value-index", 0, "collapsed: sample value index, e.g. 1 for allocated bytes, every profile must have it; html: index among the sample types of the first profile, selecting the view shown initially")
+	flag.BoolVar(&c.threads, "threads", false, "collapsed: add thread names as root frames")
+	flag.BoolVar(&c.frameTypes, "frame-types", false, "collapsed: annotate frames with their type, e.g. _[j] for JIT compiled")
 	flag.Parse()
 	args := flag.Args()
 	c.src = args[0]
@@ -34,7 +42,41 @@ type formatter interface {
 }
 
 // Usage: ./jfrparser [options] /path/to/jfr [/path/to/dest]
+//
+//	./jfrparser print [options] /path/to/jfr
+//	./jfrparser summary /path/to/jfr
+//	./jfrparser metadata [options] /path/to/jfr
+//	./jfrparser cpuload [options] /path/to/jfr
+//	./jfrparser jit [options] /path/to/jfr
+//	./jfrparser push [options] /path/to/jfr
+//	./jfrparser diff [options] /path/to/before.jfr /path/to/after.jfr [/path/to/dest]
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "print":
+			runPrint(os.Args[2:])
+			return
+		case "summary":
+			runSummary(os.Args[2:])
+			return
+		case "metadata":
+			runMetadata(os.Args[2:])
+			return
+		case "cpuload":
+			runCPULoad(os.Args[2:])
+			return
+		case "jit":
+			runJIT(os.Args[2:])
+			return
+		case "push":
+			runPush(os.Args[2:])
+			return
+		case "diff":
+			runDiff(os.Args[2:])
+			return
+		}
+	}
+
 	c := new(command)
 	parseCommand(c)
 
@@ -43,7 +85,28 @@ func main() {
 		panic(err)
 	}
 
-	var fmtr formatter = format.NewFormatterPprof()
+	var fmtr formatter
+	switch c.format {
+	case "pprof":
+		fmtr = format.NewFormatterPprof()
+	case "collapsed":
+		fmtr = format.NewFormatterCollapsed(c.valueIndex,
+			collapsed.WithThreadRoot(c.threads),
+			collapsed.WithFrameTypes(c.frameTypes),
+		)
+	case "chrometrace":
+		fmtr = format.NewFormatterChromeTrace()
+	case "speedscope":
+		fmtr = format.NewFormatterSpeedscope()
+	case "gecko":
+		fmtr = format.NewFormatterGecko()
+	case "otlp":
+		fmtr = format.NewFormatterOTLP()
+	case "html":
+		fmtr = format.NewFormatterHTML(c.valueIndex)
+	default:
+		panic(fmt.Errorf("unknown format %q", c.format))
+	}
 
 	dests, data, err := fmtr.Format(buf, c.dest)
 	if err != nil {
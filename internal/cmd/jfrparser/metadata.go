@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/grafana/jfr-parser/parser"
+	"github.com/grafana/jfr-parser/parser/types/def"
+)
+
+// Usage: ./jfrparser metadata [--events jdk.ExecutionSample,CPULoad] /path/to/jfr
+func runMetadata(args []string) {
+	fs := flag.NewFlagSet("metadata", flag.ExitOnError)
+	events := fs.String("events", "", "comma separated list of type names to print, e.g. jdk.ExecutionSample,CPULoad")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	buf, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		panic(err)
+	}
+	p := parser.NewParser(buf, parser.Options{ReadAnnotations: true})
+	// the first event read loads the metadata of the first chunk
+	if _, err := p.NextEvent(); err != nil && err != io.EOF {
+		panic(err)
+	}
+	var filter []string
+	if *events != "" {
+		filter = strings.Split(*events, ",")
+	}
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	writeMetadata(w, &p.TypeMap, filter)
+}
+
+func writeMetadata(w io.Writer, tm *def.TypeMap, filter []string) {
+	fmt.Fprintf(w, "// locale: %s, gmtOffset: %dms\n", tm.Region.Locale, tm.Region.GMTOffset)
+	classes := make([]*def.Class, 0, len(tm.IDMap))
+	for _, c := range tm.IDMap {
+		if matchEvent(filter, c.Name) {
+			classes = append(classes, c)
+		}
+	}
+	slices.SortFunc(classes, func(a, b *def.Class) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	for _, c := range classes {
+		fmt.Fprintf(w, "\n")
+		for _, a := range c.Annotations {
+			fmt.Fprintf(w, "%s\n", formatAnnotation(&a))
+		}
+		fmt.Fprintf(w, "class %s", c.Name)
+		if c.SuperType != "" {
+			fmt.Fprintf(w, " extends %s", c.SuperType)
+		}
+		fmt.Fprintf(w, " { // id %d\n", c.ID)
+		for _, s := range c.Settings {
+			fmt.Fprintf(w, "  setting %s %s = %q;\n", typeName(tm, s.Type), s.Name, s.DefaultValue)
+		}
+		for i := range c.Fields {
+			f := &c.Fields[i]
+			for _, a := range f.Annotations {
+				fmt.Fprintf(w, "  %s\n", formatAnnotation(&a))
+			}
+			array := ""
+			if f.Array {
+				array = "[]"
+			}
+			pool := ""
+			if f.ConstantPool {
+				pool = " // constant pool"
+			}
+			fmt.Fprintf(w, "  %s%s %s;%s\n", typeName(tm, f.Type), array, f.Name, pool)
+		}
+		fmt.Fprintf(w, "}\n")
+	}
+}
+
+func formatAnnotation(a *def.Annotation) string {
+	name := a.Name
+	if name == "" {
+		name = fmt.Sprintf("unknown(%d)", a.Type)
+	}
+	name = name[strings.LastIndexByte(name, '.')+1:]
+	switch len(a.Values) {
+	case 0:
+		return "@" + name
+	case 1:
+		return fmt.Sprintf("@%s(%q)", name, a.Values[0])
+	}
+	values := make([]string, 0, len(a.Values))
+	for _, v := range a.Values {
+		values = append(values, fmt.Sprintf("%q", v))
+	}
+	return fmt.Sprintf("@%s({%s})", name, strings.Join(values, ", "))
+}
+
+func typeName(tm *def.TypeMap, id def.TypeID) string {
+	if c := tm.IDMap[id]; c != nil {
+		return c.Name
+	}
+	return fmt.Sprintf("unknown(%d)", id)
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grafana/jfr-parser/parser"
+	"github.com/grafana/jfr-parser/parser/types/def"
+)
+
+const maxPrintDepth = 16
+
+// Usage: ./jfrparser print [--events jdk.ExecutionSample,CPULoad] [--stack-depth 5] /path/to/jfr
+func runPrint(args []string) {
+	fs := flag.NewFlagSet("print", flag.ExitOnError)
+	events := fs.String("events", "", "comma separated list of event names to print, e.g. jdk.ExecutionSample,CPULoad")
+	stackDepth := fs.Int("stack-depth", 5, "number of frames to print per stack trace")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	buf, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		panic(err)
+	}
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+
+	pr := &jsonPrinter{
+		p:          parser.NewParser(buf, parser.Options{IndexConstantPools: true}),
+		w:          w,
+		stackDepth: *stackDepth,
+	}
+	if *events != "" {
+		pr.events = strings.Split(*events, ",")
+	}
+	if err := pr.print(); err != nil {
+		panic(err)
+	}
+}
+
+// jsonPrinter writes events in a layout close to `jfr print --json`.
+type jsonPrinter struct {
+	p          *parser.Parser
+	w          io.Writer
+	events     []string
+	stackDepth int
+
+	indent int
+}
+
+func (pr *jsonPrinter) print() error {
+	pr.line("{")
+	pr.indent++
+	pr.line(`"recording": {`)
+	pr.indent++
+	pr.line(`"events": [`)
+	pr.indent++
+	first := true
+	for {
+		typ, err := pr.p.NextEvent()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		c := pr.p.TypeMap.IDMap[typ]
+		if c == nil || !matchEvent(pr.events, c.Name) {
+			continue
+		}
+		o, err := pr.p.DecodeEvent()
+		if err != nil {
+			return fmt.Errorf("decoding %s: %w", c.Name, err)
+		}
+		if !first {
+			pr.raw(",\n")
+		}
+		first = false
+		pr.line("{")
+		pr.indent++
+		pr.line(`"type": ` + quote(c.Name) + ",")
+		pr.pad()
+		pr.raw(`"values": `)
+		pr.object(o, 0)
+		pr.raw("\n")
+		pr.indent--
+		pr.pad()
+		pr.raw("}")
+	}
+	if !first {
+		pr.raw("\n")
+	}
+	pr.indent--
+	pr.line("]")
+	pr.indent--
+	pr.line("}")
+	pr.indent--
+	pr.line("}")
+	return nil
+}
+
+// matchEvent reports whether name matches one of the filters by full or simple name.
+// An empty filter matches everything.
+func matchEvent(filter []string, name string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	simple := name[strings.LastIndexByte(name, '.')+1:]
+	for _, e := range filter {
+		if e == name || e == simple {
+			return true
+		}
+	}
+	return false
+}
+
+func (pr *jsonPrinter) object(o *parser.Object, depth int) {
+	pr.raw("{")
+	pr.indent++
+	for i := range o.Class.Fields {
+		if i > 0 {
+			pr.raw(",")
+		}
+		pr.raw("\n")
+		pr.pad()
+		pr.raw(quote(o.Class.Fields[i].Name) + ": ")
+		pr.value(&o.Class.Fields[i], o.Values[i], depth+1)
+	}
+	pr.indent--
+	if len(o.Class.Fields) > 0 {
+		pr.raw("\n")
+		pr.pad()
+	}
+	pr.raw("}")
+}
+
+func (pr *jsonPrinter) value(f *def.Field, v any, depth int) {
+	if depth > maxPrintDepth {
+		pr.raw("null")
+		return
+	}
+	switch v := v.(type) {
+	case nil:
+		pr.raw("null")
+	case bool:
+		pr.raw(strconv.FormatBool(v))
+	case int8, int16, int32:
+		pr.raw(fmt.Sprintf("%d", v))
+	case int64:
+		h := pr.p.ChunkHeader()
+		switch f.Unit.ContentType {
+		case def.ContentTypeTimestamp, def.ContentTypeTimespan:
+			if v == math.MinInt64 {
+				// Long.MIN_VALUE marks a time that is not set
+				pr.raw("null")
+				return
+			}
+		}
+		switch f.Unit.ContentType {
+		case def.ContentTypeTimestamp:
+			pr.raw(quote(time.Unix(0, h.TimestampNanos(f.Unit, v)).Format(time.RFC3339Nano)))
+		case def.ContentTypeTimespan:
+			pr.raw(quote(isoDuration(time.Duration(h.TimespanNanos(f.Unit, v)))))
+		case def.ContentTypeDataAmount:
+			pr.raw(strconv.FormatInt(f.Unit.Bytes(v), 10))
+		default:
+			pr.raw(strconv.FormatInt(v, 10))
+		}
+	case float32:
+		pr.float(float64(v), 32)
+	case float64:
+		pr.float(v, 64)
+	case string:
+		pr.raw(quote(v))
+	case []any:
+		pr.raw("[")
+		pr.indent++
+		for i, e := range v {
+			if i > 0 {
+				pr.raw(",")
+			}
+			pr.raw("\n")
+			pr.pad()
+			pr.value(f, e, depth)
+		}
+		pr.indent--
+		if len(v) > 0 {
+			pr.raw("\n")
+			pr.pad()
+		}
+		pr.raw("]")
+	case parser.ConstantRef:
+		c, ok, err := pr.p.Constant(v)
+		if err != nil || !ok {
+			pr.raw("null")
+			return
+		}
+		if o, ok := c.(*parser.Object); ok && v.Type == pr.p.TypeMap.T_STACK_TRACE {
+			c = pr.limitFrames(o)
+		}
+		pr.value(f, c, depth)
+	case *parser.Object:
+		// single field types such as jdk.types.ThreadState or jdk.types.Symbol
+		// are printed as their only value, the way jfr does
+		if len(v.Values) == 1 {
+			pr.value(&v.Class.Fields[0], v.Values[0], depth)
+			return
+		}
+		pr.object(v, depth)
+	default:
+		pr.raw(quote(fmt.Sprint(v)))
+	}
+}
+
+func (pr *jsonPrinter) limitFrames(st *parser.Object) *parser.Object {
+	frames, ok := st.Get("frames").([]any)
+	if !ok || len(frames) <= pr.stackDepth {
+		return st
+	}
+	res := &parser.Object{Class: st.Class, Values: make([]any, len(st.Values))}
+	for i := range st.Class.Fields {
+		switch st.Class.Fields[i].Name {
+		case "frames":
+			res.Values[i] = frames[:pr.stackDepth]
+		case "truncated":
+			res.Values[i] = true
+		default:
+			res.Values[i] = st.Values[i]
+		}
+	}
+	return res
+}
+
+func (pr *jsonPrinter) float(v float64, bits int) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		pr.raw("null")
+		return
+	}
+	pr.raw(strconv.FormatFloat(v, 'g', -1, bits))
+}
+
+func (pr *jsonPrinter) line(s string) {
+	pr.pad()
+	pr.raw(s + "\n")
+}
+
+func (pr *jsonPrinter) pad() {
+	pr.raw(strings.Repeat("  ", pr.indent))
+}
+
+func (pr *jsonPrinter) raw(s string) {
+	_, _ = io.WriteString(pr.w, s)
+}
+
+// isoDuration formats d like java.time.Duration.toString, e.g. PT1M2.5S.
+func isoDuration(d time.Duration) string {
+	if d == 0 {
+		return "PT0S"
+	}
+	sb := strings.Builder{}
+	sb.WriteString("PT")
+	if d < 0 {
+		sb.WriteByte('-')
+		d = -d
+	}
+	if h := d / time.Hour; h > 0 {
+		sb.WriteString(strconv.FormatInt(int64(h), 10) + "H")
+		d -= h * time.Hour
+	}
+	if m := d / time.Minute; m > 0 {
+		sb.WriteString(strconv.FormatInt(int64(m), 10) + "M")
+		d -= m * time.Minute
+	}
+	if d > 0 {
+		s := strconv.FormatFloat(d.Seconds(), 'f', 9, 64)
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+		sb.WriteString(s + "S")
+	}
+	return sb.String()
+}
+
+func quote(s string) string {
+	sb := strings.Builder{}
+	sb.Grow(len(s) + 2)
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r < 0x20 || r == utf8.RuneError:
+			sb.WriteString(fmt.Sprintf(`\u%04x`, r))
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/jfr-parser/parser"
+)
+
+type printedRecording struct {
+	Recording struct {
+		Events []struct {
+			Type   string         `json:"type"`
+			Values map[string]any `json:"values"`
+		} `json:"events"`
+	} `json:"recording"`
+}
+
+func printTestFile(t *testing.T, events []string, stackDepth int) printedRecording {
+	buf, err := readMaybeGzipped(testdataDir + "example.jfr.gz")
+	require.NoError(t, err)
+	out := bytes.NewBuffer(nil)
+	pr := &jsonPrinter{
+		p:          parser.NewParser(buf, parser.Options{IndexConstantPools: true}),
+		w:          out,
+		events:     events,
+		stackDepth: stackDepth,
+	}
+	require.NoError(t, pr.print())
+	require.True(t, json.Valid(out.Bytes()), out.String())
+	var res printedRecording
+	require.NoError(t, json.Unmarshal(out.Bytes(), &res))
+	return res
+}
+
+func TestPrint(t *testing.T) {
+	all := printTestFile(t, nil, 5)
+	types := map[string]int{}
+	for _, e := range all.Recording.Events {
+		types[e.Type]++
+	}
+	require.NotZero(t, types["jdk.ExecutionSample"])
+	require.NotZero(t, types["jdk.CPULoad"])
+
+	filtered := printTestFile(t, []string{"jdk.ExecutionSample", "CPULoad"}, 2)
+	require.Len(t, filtered.Recording.Events, types["jdk.ExecutionSample"]+types["jdk.CPULoad"])
+	truncated := 0
+	for _, e := range filtered.Recording.Events {
+		assert.Contains(t, []string{"jdk.ExecutionSample", "jdk.CPULoad"}, e.Type)
+		_, err := time.Parse(time.RFC3339Nano, e.Values["startTime"].(string))
+		assert.NoError(t, err)
+		if e.Type != "jdk.ExecutionSample" {
+			continue
+		}
+		st := e.Values["stackTrace"].(map[string]any)
+		frames := st["frames"].([]any)
+		assert.LessOrEqual(t, len(frames), 2)
+		if st["truncated"] == true {
+			truncated++
+		}
+	}
+	assert.NotZero(t, truncated)
+}
+
+func TestIsoDuration(t *testing.T) {
+	for _, tc := range []struct {
+		d        time.Duration
+		expected string
+	}{
+		{0, "PT0S"},
+		{20 * time.Millisecond, "PT0.02S"},
+		{time.Second, "PT1S"},
+		{62*time.Second + 500*time.Millisecond, "PT1M2.5S"},
+		{2 * time.Hour, "PT2H"},
+		{time.Hour + time.Nanosecond, "PT1H0.000000001S"},
+		{-3 * time.Second, "PT-3S"},
+	} {
+		assert.Equal(t, tc.expected, isoDuration(tc.d), tc.d.String())
+	}
+}
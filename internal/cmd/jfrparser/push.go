@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/grafana/jfr-parser/parser"
+	"github.com/grafana/jfr-parser/pprof"
+	"github.com/grafana/jfr-parser/pprof/pyroscope"
+)
+
+// pushCommand sends a recording to a Pyroscope compatible server, either as is to
+// /ingest?format=jfr or converted to pprof through the push API.
+type pushCommand struct {
+	url        string
+	mode       string
+	app        string
+	spy        string
+	sampleRate int64
+	labels     map[string]string
+	header     http.Header
+	retries    int
+	retryWait  time.Duration
+	dryRun     bool
+
+	jfr []byte
+	// jfrLabels is the LabelsSnapshot protobuf of the recording, may be nil.
+	jfrLabels []byte
+
+	client *http.Client
+	out    io.Writer
+}
+
+// Usage: ./jfrparser push [options] /path/to/jfr
+func runPush(args []string) {
+	c := &pushCommand{labels: map[string]string{}, header: http.Header{}, client: http.DefaultClient, out: os.Stdout}
+	fs := flag.NewFlagSet("push", flag.ExitOnError)
+	fs.StringVar(&c.url, "url", "http://localhost:4040", "Pyroscope server URL")
+	fs.StringVar(&c.mode, "mode", "jfr", "jfr: send the recording to /ingest?format=jfr, pprof: convert it and send it through the push API")
+	fs.StringVar(&c.app, "app", "jfrparser", "application name, the service_name label")
+	fs.StringVar(&c.spy, "spy", "javaspy", "spy name, the pyroscope_spy label")
+	fs.Int64Var(&c.sampleRate, "sample-rate", 100, "CPU and wall sample rate in Hz")
+	labelsFile := fs.String("labels", "", "LabelsSnapshot protobuf with the context labels of the recording, may be gzipped")
+	fs.Func("label", "series label as name=value, repeatable", func(s string) error {
+		k, v, ok := strings.Cut(s, "=")
+		if !ok || k == "" {
+			return fmt.Errorf("label %q is not name=value", s)
+		}
+		c.labels[k] = v
+		return nil
+	})
+	fs.Func("header", "request header as \"Name: value\", repeatable, e.g. X-Scope-OrgID: tenant", func(s string) error {
+		k, v, ok := strings.Cut(s, ":")
+		if !ok || k == "" {
+			return fmt.Errorf("header %q is not \"Name: value\"", s)
+		}
+		c.header.Add(strings.TrimSpace(k), strings.TrimSpace(v))
+		return nil
+	})
+	token := fs.String("token", "", "bearer token for the Authorization header")
+	basicAuth := fs.String("basic-auth", "", "user:password for the Authorization header")
+	timeout := fs.Duration("timeout", 30*time.Second, "timeout of a request")
+	fs.IntVar(&c.retries, "retries", 3, "number of retries of requests that failed with a network error, 429 or 5xx")
+	fs.DurationVar(&c.retryWait, "retry-wait", time.Second, "wait before the first retry, doubled for every further retry")
+	fs.BoolVar(&c.dryRun, "dry-run", false, "print the requests instead of sending them")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	switch {
+	case *token != "" && *basicAuth != "":
+		panic(fmt.Errorf("-token and -basic-auth are mutually exclusive"))
+	case *token != "":
+		c.header.Set("Authorization", "Bearer "+*token)
+	case *basicAuth != "":
+		user, password, _ := strings.Cut(*basicAuth, ":")
+		r := &http.Request{Header: http.Header{}}
+		r.SetBasicAuth(user, password)
+		c.header.Set("Authorization", r.Header.Get("Authorization"))
+	}
+	c.client = &http.Client{Timeout: *timeout}
+
+	var err error
+	if c.jfr, err = readMaybeGzipped(fs.Arg(0)); err != nil {
+		panic(err)
+	}
+	if *labelsFile != "" {
+		if c.jfrLabels, err = readMaybeGzipped(*labelsFile); err != nil {
+			panic(err)
+		}
+	}
+	if err := c.run(context.Background()); err != nil {
+		panic(err)
+	}
+}
+
+func (c *pushCommand) run(ctx context.Context) error {
+	from, until, err := recordingSpan(c.jfr)
+	if err != nil {
+		return err
+	}
+	switch c.mode {
+	case "jfr":
+		return c.pushJFR(ctx, from, until)
+	case "pprof":
+		return c.pushPprof(ctx, from, until)
+	default:
+		return fmt.Errorf("unknown mode %q", c.mode)
+	}
+}
+
+// pushJFR sends the recording to /ingest, with the labels as multipart form if there are any.
+func (c *pushCommand) pushJFR(ctx context.Context, from, until time.Time) error {
+	q := url.Values{}
+	q.Set("name", c.ingestName())
+	q.Set("from", strconv.FormatInt(from.Unix(), 10))
+	q.Set("until", strconv.FormatInt(until.Unix(), 10))
+	q.Set("format", "jfr")
+	q.Set("sampleRate", strconv.FormatInt(c.sampleRate, 10))
+	q.Set("spyName", c.spy)
+	u := strings.TrimSuffix(c.url, "/") + "/ingest?" + q.Encode()
+
+	body, contentType := c.jfr, "application/octet-stream"
+	if c.jfrLabels != nil {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		for _, part := range []struct {
+			name string
+			data []byte
+		}{{"jfr", c.jfr}, {"labels", c.jfrLabels}} {
+			fw, err := w.CreateFormFile(part.name, part.name)
+			if err != nil {
+				return err
+			}
+			if _, err := fw.Write(part.data); err != nil {
+				return err
+			}
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		body, contentType = buf.Bytes(), w.FormDataContentType()
+	}
+
+	if c.dryRun {
+		c.printRequest(u)
+		fmt.Fprintf(c.out, "Content-Type: %s\n%d bytes\n", contentType, len(body))
+		return nil
+	}
+	return c.retry(ctx, func() error {
+		r, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		for k, vs := range c.header {
+			r.Header[k] = vs
+		}
+		r.Header.Set("Content-Type", contentType)
+		res, err := c.client.Do(r)
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+		if err := pyroscope.CheckResponse(res); err != nil {
+			return err
+		}
+		_, _ = io.Copy(io.Discard, res.Body)
+		return nil
+	})
+}
+
+// ingestName is the name parameter of /ingest, the app name followed by the labels, e.g. app{env=dev}.
+func (c *pushCommand) ingestName() string {
+	if len(c.labels) == 0 {
+		return c.app
+	}
+	names := make([]string, 0, len(c.labels))
+	for k := range c.labels {
+		names = append(names, k)
+	}
+	slices.Sort(names)
+	sb := strings.Builder{}
+	sb.WriteString(c.app)
+	sb.WriteByte('{')
+	for i, k := range names {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(c.labels[k])
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+// pushPprof converts the recording to pprof and sends the profiles through the push API.
+func (c *pushCommand) pushPprof(ctx context.Context, from, until time.Time) error {
+	var jfrLabels *pprof.LabelsSnapshot
+	if c.jfrLabels != nil {
+		jfrLabels = new(pprof.LabelsSnapshot)
+		if err := jfrLabels.UnmarshalVT(c.jfrLabels); err != nil {
+			return fmt.Errorf("labels: %w", err)
+		}
+	}
+	profiles, err := pprof.ParseJFR(c.jfr, &pprof.ParseInput{
+		StartTime:  from,
+		EndTime:    until,
+		SampleRate: c.sampleRate,
+	}, jfrLabels)
+	if err != nil {
+		return err
+	}
+	req, err := pyroscope.NewPushRequest(profiles, c.labels, c.app, c.spy)
+	if err != nil {
+		return err
+	}
+
+	if c.dryRun {
+		c.printRequest(strings.TrimSuffix(c.url, "/") + pyroscope.PushPath)
+		for _, s := range req.Series {
+			ls := make([]string, 0, len(s.Labels))
+			for _, l := range s.Labels {
+				ls = append(ls, fmt.Sprintf("%s=%q", l.Name, l.Value))
+			}
+			sizes := make([]string, 0, len(s.Samples))
+			for _, sample := range s.Samples {
+				sizes = append(sizes, strconv.Itoa(len(sample.RawProfile)))
+			}
+			fmt.Fprintf(c.out, "{%s} %s bytes\n", strings.Join(ls, ", "), strings.Join(sizes, ", "))
+		}
+		return nil
+	}
+	return c.retry(ctx, func() error {
+		return pyroscope.Push(ctx, c.client, c.url, req, c.header)
+	})
+}
+
+func (c *pushCommand) printRequest(u string) {
+	fmt.Fprintf(c.out, "POST %s\n", u)
+	names := make([]string, 0, len(c.header))
+	for k := range c.header {
+		names = append(names, k)
+	}
+	slices.Sort(names)
+	for _, k := range names {
+		v := strings.Join(c.header[k], ", ")
+		if k == "Authorization" {
+			v = "<redacted>"
+		}
+		fmt.Fprintf(c.out, "%s: %s\n", k, v)
+	}
+}
+
+// retry calls send until it succeeds, fails with an error that is not worth retrying
+// or c.retries retries failed.
+func (c *pushCommand) retry(ctx context.Context, send func() error) error {
+	wait := c.retryWait
+	for i := 0; ; i++ {
+		err := send()
+		if err == nil || i >= c.retries || !retryable(err) {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "retrying in %s: %v\n", wait, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// retryable reports whether err is a 429 or 5xx response or a transient network error,
+// a timeout, a failed dial, read or write or a connection closed mid-response. Other
+// errors, e.g. of a malformed URL or a TLS handshake, are not retried.
+func retryable(err error) bool {
+	var statusErr *pyroscope.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	// *url.Error is a net.Error as well, only the errors of the connection are transient
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return opErr.Op == "dial" || opErr.Op == "read" || opErr.Op == "write"
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// recordingSpan returns the start of the first and the end of the last chunk.
+func recordingSpan(buf []byte) (time.Time, time.Time, error) {
+	p := parser.NewParser(buf, parser.Options{})
+	var start, end int64
+	var last parser.ChunkHeader
+	for {
+		_, err := p.NextEvent()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		if h := p.ChunkHeader(); h != last {
+			last = h
+			if start == 0 || int64(h.StartNanos) < start {
+				start = int64(h.StartNanos)
+			}
+			end = max(end, int64(h.StartNanos+h.DurationNanos))
+		}
+	}
+	if start == 0 {
+		now := time.Now()
+		return now, now, nil
+	}
+	return time.Unix(0, start), time.Unix(0, end), nil
+}
+
+// readMaybeGzipped reads a file and decompresses it if it is gzipped.
+func readMaybeGzipped(name string) ([]byte, error) {
+	buf, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) < 2 || buf[0] != 0x1f || buf[1] != 0x8b {
+		return buf, nil
+	}
+	r, err := gzip.NewReader(bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	pushv1 "github.com/grafana/pyroscope/api/gen/proto/go/push/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/jfr-parser/pprof/pyroscope"
+)
+
+const testdataDir = "../../../parser/testdata/"
+
+func newTestPushCommand(t *testing.T, srvURL, mode string) *pushCommand {
+	jfr, err := readMaybeGzipped(testdataDir + "dump2.jfr.gz")
+	require.NoError(t, err)
+	labels, err := readMaybeGzipped(testdataDir + "dump2.labels.pb.gz")
+	require.NoError(t, err)
+	return &pushCommand{
+		url:        srvURL,
+		mode:       mode,
+		app:        "app",
+		spy:        "javaspy",
+		sampleRate: 100,
+		labels:     map[string]string{"env": "dev", "region": "eu"},
+		header:     http.Header{"Authorization": {"Bearer token"}},
+		retries:    2,
+		retryWait:  time.Millisecond,
+		jfr:        jfr,
+		jfrLabels:  labels,
+		client:     http.DefaultClient,
+		out:        io.Discard,
+	}
+}
+
+func TestPushJFR(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, "/ingest", r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		q := r.URL.Query()
+		assert.Equal(t, "app{env=dev,region=eu}", q.Get("name"))
+		assert.Equal(t, "jfr", q.Get("format"))
+		assert.Equal(t, "javaspy", q.Get("spyName"))
+		assert.Equal(t, "100", q.Get("sampleRate"))
+		assert.Less(t, q.Get("from"), q.Get("until"))
+		require.NoError(t, r.ParseMultipartForm(64<<20))
+		for _, name := range []string{"jfr", "labels"} {
+			f, _, err := r.FormFile(name)
+			require.NoError(t, err)
+			data, err := io.ReadAll(f)
+			require.NoError(t, err)
+			assert.NotEmpty(t, data)
+		}
+	}))
+	defer srv.Close()
+
+	c := newTestPushCommand(t, srv.URL, "jfr")
+	require.NoError(t, c.run(context.Background()))
+	assert.Equal(t, 2, calls)
+}
+
+func TestPushPprof(t *testing.T) {
+	var received *pushv1.PushRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, pyroscope.PushPath, r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		received = new(pushv1.PushRequest)
+		require.NoError(t, received.UnmarshalVT(body))
+	}))
+	defer srv.Close()
+
+	c := newTestPushCommand(t, srv.URL, "pprof")
+	require.NoError(t, c.run(context.Background()))
+	require.NotNil(t, received)
+	require.NotEmpty(t, received.Series)
+	for _, s := range received.Series {
+		names := map[string]string{}
+		for _, l := range s.Labels {
+			names[l.Name] = l.Value
+		}
+		assert.Equal(t, "dev", names["env"])
+		assert.Equal(t, "app", names["service_name"])
+		assert.NotEmpty(t, names["__name__"])
+	}
+}
+
+func TestPushNoRetry(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "bad request", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	c := newTestPushCommand(t, srv.URL, "pprof")
+	err := c.run(context.Background())
+	require.ErrorContains(t, err, "bad request")
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "unavailable", http.StatusBadGateway)
+	})
+	require.ErrorContains(t, c.run(context.Background()), "unavailable")
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetryable(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"too many requests", &pyroscope.StatusError{StatusCode: http.StatusTooManyRequests}, true},
+		{"bad gateway", &pyroscope.StatusError{StatusCode: http.StatusBadGateway}, true},
+		{"bad request", &pyroscope.StatusError{StatusCode: http.StatusBadRequest}, false},
+		{"connection refused", &url.Error{Op: "Post", Err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}}, true},
+		{"connection reset", &url.Error{Op: "Post", Err: syscall.ECONNRESET}, true},
+		{"unexpected EOF", fmt.Errorf("reading response: %w", io.ErrUnexpectedEOF), true},
+		{"timeout", &url.Error{Op: "Post", Err: &timeoutError{}}, true},
+		{"tls alert", &url.Error{Op: "Post", Err: &net.OpError{Op: "remote error", Err: errors.New("tls: bad certificate")}}, false},
+		{"unsupported scheme", &url.Error{Op: "Post", Err: errors.New(`unsupported protocol scheme "htp"`)}, false},
+		{"canceled", &url.Error{Op: "Post", Err: context.Canceled}, false},
+		{"request", errors.New("net/http: invalid method"), false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, retryable(tc.err))
+		})
+	}
+}
+
+func TestPushMalformedURL(t *testing.T) {
+	c := newTestPushCommand(t, "htp://localhost", "pprof")
+	// a retry would wait longer than the context lives
+	c.retryWait = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.ErrorContains(t, c.run(ctx), "unsupported protocol scheme")
+}
+
+type timeoutError struct{}
+
+func (*timeoutError) Error() string   { return "i/o timeout" }
+func (*timeoutError) Timeout() bool   { return true }
+func (*timeoutError) Temporary() bool { return true }
+
+func TestPushDryRun(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s", r.URL)
+	}))
+	defer srv.Close()
+
+	for _, mode := range []string{"jfr", "pprof"} {
+		c := newTestPushCommand(t, srv.URL, mode)
+		out := &bytes.Buffer{}
+		c.out = out
+		c.dryRun = true
+		require.NoError(t, c.run(context.Background()))
+		assert.True(t, strings.HasPrefix(out.String(), "POST "+srv.URL))
+		assert.Contains(t, out.String(), "Authorization: <redacted>")
+		assert.NotContains(t, out.String(), "token")
+	}
+}
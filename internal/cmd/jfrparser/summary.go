@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/grafana/jfr-parser/parser"
+)
+
+// Usage: ./jfrparser summary /path/to/jfr
+func runSummary(args []string) {
+	fs := flag.NewFlagSet("summary", flag.ExitOnError)
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	buf, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		panic(err)
+	}
+	s, err := summarize(buf)
+	if err != nil {
+		panic(err)
+	}
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	s.write(w)
+}
+
+type chunkSummary struct {
+	header    parser.ChunkHeader
+	constants map[string]int
+}
+
+type eventSummary struct {
+	name  string
+	count int
+	size  int
+}
+
+type summary struct {
+	chunks []chunkSummary
+	events []*eventSummary
+}
+
+func summarize(buf []byte) (*summary, error) {
+	s := &summary{}
+	var p *parser.Parser
+	p = parser.NewParser(buf, parser.Options{
+		IndexConstantPools: true,
+		OnChunk: func(h parser.ChunkHeader) {
+			c := chunkSummary{header: h, constants: map[string]int{}}
+			for id, n := range p.ConstantPoolSizes() {
+				if cls := p.TypeMap.IDMap[id]; cls != nil {
+					c.constants[cls.Name] = n
+				}
+			}
+			s.chunks = append(s.chunks, c)
+		},
+	})
+	events := map[string]*eventSummary{}
+	for {
+		typ, err := p.NextEvent()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := fmt.Sprintf("unknown(%d)", typ)
+		if c := p.TypeMap.IDMap[typ]; c != nil {
+			name = c.Name
+		}
+		e := events[name]
+		if e == nil {
+			e = &eventSummary{name: name}
+			events[name] = e
+			s.events = append(s.events, e)
+		}
+		e.count++
+		e.size += p.EventSize()
+	}
+	slices.SortFunc(s.events, func(a, b *eventSummary) int {
+		if a.count != b.count {
+			return b.count - a.count
+		}
+		return strings.Compare(a.name, b.name)
+	})
+	return s, nil
+}
+
+func (s *summary) write(w io.Writer) {
+	var start, end int64
+	for i, c := range s.chunks {
+		if i == 0 || int64(c.header.StartNanos) < start {
+			start = int64(c.header.StartNanos)
+		}
+		end = max(end, int64(c.header.StartNanos+c.header.DurationNanos))
+	}
+	fmt.Fprintf(w, "Start: %s\n", formatNanos(start))
+	fmt.Fprintf(w, "End: %s\n", formatNanos(end))
+	fmt.Fprintf(w, "Duration: %s\n", time.Duration(end-start))
+	fmt.Fprintf(w, "Chunks: %d\n", len(s.chunks))
+
+	for i, c := range s.chunks {
+		h := c.header
+		fmt.Fprintf(w, "\nChunk %d\n", i)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "  Version:\t%d.%d\n", h.Version>>16, h.Version&0xffff)
+		fmt.Fprintf(tw, "  Size:\t%d\n", h.Size)
+		fmt.Fprintf(tw, "  Start:\t%s\n", formatNanos(int64(h.StartNanos)))
+		fmt.Fprintf(tw, "  Duration:\t%s\n", time.Duration(h.DurationNanos))
+		fmt.Fprintf(tw, "  Start ticks:\t%d\n", h.StartTicks)
+		fmt.Fprintf(tw, "  Ticks per second:\t%d\n", h.TicksPerSecond)
+		fmt.Fprintf(tw, "  Features:\t%d\n", h.Features)
+		_ = tw.Flush()
+
+		names := make([]string, 0, len(c.constants))
+		for name := range c.constants {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		fmt.Fprintf(w, "\n")
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "  Constant Pool\tCount\n")
+		for _, name := range names {
+			fmt.Fprintf(tw, "  %s\t%d\n", name, c.constants[name])
+		}
+		_ = tw.Flush()
+	}
+
+	fmt.Fprintf(w, "\n")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Event Type\tCount\tSize (bytes)\n")
+	fmt.Fprintf(tw, "==========\t=====\t============\n")
+	for _, e := range s.events {
+		fmt.Fprintf(tw, "%s\t%d\t%d\n", e.name, e.count, e.size)
+	}
+	_ = tw.Flush()
+}
+
+func formatNanos(nanos int64) string {
+	return time.Unix(0, nanos).UTC().Format("2006-01-02 15:04:05.000 (MST)")
+}
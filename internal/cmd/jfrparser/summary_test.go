@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummaryChunks(t *testing.T) {
+	buf, err := readMaybeGzipped(testdataDir + "example.jfr.gz")
+	require.NoError(t, err)
+	single, err := summarize(buf)
+	require.NoError(t, err)
+	require.Len(t, single.chunks, 1)
+	assert.NotEmpty(t, single.chunks[0].constants)
+
+	// both chunks have the same header, each is reported
+	s, err := summarize(slices.Concat(buf, buf))
+	require.NoError(t, err)
+	require.Len(t, s.chunks, 2)
+	assert.Equal(t, s.chunks[0], s.chunks[1])
+	assert.Equal(t, single.chunks[0], s.chunks[0])
+	for i, e := range s.events {
+		assert.Equal(t, 2*single.events[i].count, e.count)
+	}
+
+	out := bytes.NewBuffer(nil)
+	s.write(out)
+	assert.Contains(t, out.String(), "Chunks: 2\n")
+	assert.Contains(t, out.String(), "\nChunk 1\n")
+}
@@ -0,0 +1,13 @@
+// Package panics turns panics of the parser into errors for the converters.
+package panics
+
+import "fmt"
+
+// Recover sets *err to the value of a panic. It must be deferred directly:
+//
+//	defer panics.Recover(&err)
+func Recover(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("jfr parser panic: %v", r)
+	}
+}
@@ -0,0 +1,32 @@
+// Package testutil holds helpers shared by the tests of the exporters.
+package testutil
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestdataDir returns the directory of the recordings in parser/testdata.
+func TestdataDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "parser", "testdata")
+}
+
+// ReadGzipFile returns the decompressed content of the gzipped file name in TestdataDir.
+func ReadGzipFile(t testing.TB, name string) []byte {
+	f, err := os.Open(filepath.Join(TestdataDir(), name))
+	require.NoError(t, err)
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	defer r.Close()
+	bs, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return bs
+}
@@ -0,0 +1,100 @@
+package jvmstats
+
+import (
+	"fmt"
+
+	"github.com/grafana/jfr-parser/parser"
+	"github.com/grafana/jfr-parser/parser/types"
+	"github.com/grafana/jfr-parser/parser/types/def"
+)
+
+// CPULoad is a jdk.CPULoad sample. Loads are fractions of all CPUs, in [0, 1].
+type CPULoad struct {
+	TimestampNanos int64
+	JVMUser        float32
+	JVMSystem      float32
+	MachineTotal   float32
+}
+
+// ThreadCPULoad is a jdk.ThreadCPULoad sample. Loads are fractions of all CPUs, in [0, 1].
+type ThreadCPULoad struct {
+	TimestampNanos int64
+	Thread         string
+	User           float32
+	System         float32
+}
+
+func newCPULoad(p *parser.Parser, typ def.TypeID) CPULoad {
+	e := &p.CPULoad
+	return CPULoad{
+		TimestampNanos: p.TimestampNanos(typ, "startTime", int64(e.StartTime)),
+		JVMUser:        e.JvmUser,
+		JVMSystem:      e.JvmSystem,
+		MachineTotal:   e.MachineTotal,
+	}
+}
+
+func newThreadCPULoad(p *parser.Parser, typ def.TypeID) ThreadCPULoad {
+	e := &p.ThreadCPULoad
+	return ThreadCPULoad{
+		TimestampNanos: p.TimestampNanos(typ, "startTime", int64(e.StartTime)),
+		Thread:         threadName(p, e.EventThread),
+		User:           e.User,
+		System:         e.System,
+	}
+}
+
+func threadName(p *parser.Parser, ref types.ThreadRef) string {
+	t := p.GetThread(ref)
+	switch {
+	case t == nil:
+		return ""
+	case t.JavaName != "":
+		return t.JavaName
+	case t.OsName != "":
+		return t.OsName
+	default:
+		return fmt.Sprintf("tid=%d", t.OsThreadId)
+	}
+}
+
+// CPUSeries returns the CPU load time series:
+//
+//	jvm_cpu_load{mode="user"|"system"}           gauge, JVM user and system load
+//	jvm_cpu_machine_load                         gauge, total load of the machine
+//	jvm_thread_cpu_load{thread, mode}            gauge, per thread user and system load
+func (s *Stats) CPUSeries() []Series {
+	var res []Series
+	if len(s.CPULoad) > 0 {
+		user := Series{Name: "jvm_cpu_load", Help: "CPU load of the JVM.", Type: Gauge, Labels: []Label{{Name: "mode", Value: "user"}}}
+		system := Series{Name: "jvm_cpu_load", Help: "CPU load of the JVM.", Type: Gauge, Labels: []Label{{Name: "mode", Value: "system"}}}
+		machine := Series{Name: "jvm_cpu_machine_load", Help: "CPU load of the machine.", Type: Gauge}
+		for _, l := range s.CPULoad {
+			user.Samples = append(user.Samples, Sample{TimestampNanos: l.TimestampNanos, Value: float64(l.JVMUser)})
+			system.Samples = append(system.Samples, Sample{TimestampNanos: l.TimestampNanos, Value: float64(l.JVMSystem)})
+			machine.Samples = append(machine.Samples, Sample{TimestampNanos: l.TimestampNanos, Value: float64(l.MachineTotal)})
+		}
+		res = append(res, user, system, machine)
+	}
+
+	index := map[string]int{}
+	for _, l := range s.ThreadCPULoad {
+		i, ok := index[l.Thread]
+		if !ok {
+			i = len(res)
+			index[l.Thread] = i
+			for _, mode := range []string{"user", "system"} {
+				res = append(res, Series{
+					Name:   "jvm_thread_cpu_load",
+					Help:   "CPU load of a JVM thread.",
+					Type:   Gauge,
+					Labels: []Label{{Name: "thread", Value: l.Thread}, {Name: "mode", Value: mode}},
+				})
+			}
+		}
+		res[i].Samples = append(res[i].Samples, Sample{TimestampNanos: l.TimestampNanos, Value: float64(l.User)})
+		res[i+1].Samples = append(res[i+1].Samples, Sample{TimestampNanos: l.TimestampNanos, Value: float64(l.System)})
+	}
+	sortSamples(res)
+	return res
+}
@@ -0,0 +1,37 @@
+package jvmstats
+
+import (
+	"github.com/grafana/jfr-parser/parser"
+	"github.com/grafana/jfr-parser/parser/types/def"
+)
+
+// ExceptionStatistics is a jdk.ExceptionStatistics sample. Throwables is the
+// number of throwables created since the JVM started.
+type ExceptionStatistics struct {
+	TimestampNanos int64
+	Throwables     uint64
+}
+
+func newExceptionStatistics(p *parser.Parser, typ def.TypeID) ExceptionStatistics {
+	e := &p.ExceptionStatistics
+	return ExceptionStatistics{
+		TimestampNanos: p.TimestampNanos(typ, "startTime", int64(e.StartTime)),
+		Throwables:     e.Throwables,
+	}
+}
+
+// ExceptionSeries returns the exception time series:
+//
+//	jvm_exceptions_thrown                        counter, throwables created since JVM start
+func (s *Stats) ExceptionSeries() []Series {
+	if len(s.ExceptionStatistics) == 0 {
+		return nil
+	}
+	thrown := Series{Name: "jvm_exceptions_thrown", Help: "Number of throwables created.", Type: Counter}
+	for _, e := range s.ExceptionStatistics {
+		thrown.Samples = append(thrown.Samples, Sample{TimestampNanos: e.TimestampNanos, Value: float64(e.Throwables)})
+	}
+	res := []Series{thrown}
+	sortSamples(res)
+	return res
+}
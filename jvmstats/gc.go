@@ -0,0 +1,220 @@
+package jvmstats
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/grafana/jfr-parser/parser"
+	"github.com/grafana/jfr-parser/parser/types/def"
+)
+
+type Generation int
+
+const (
+	GenerationUnknown Generation = iota
+	GenerationYoung
+	GenerationOld
+)
+
+func (g Generation) String() string {
+	switch g {
+	case GenerationYoung:
+		return "young"
+	case GenerationOld:
+		return "old"
+	default:
+		return "unknown"
+	}
+}
+
+// Collection is a single garbage collection, joined by GC id from
+// jdk.GarbageCollection, jdk.GCPhasePause, jdk.GCHeapSummary and
+// jdk.YoungGarbageCollection / jdk.OldGarbageCollection.
+type Collection struct {
+	ID uint32
+	// Name is the collector, e.g. G1New or G1Old.
+	Name       string
+	Cause      string
+	Generation Generation
+
+	StartNanos   int64
+	Duration     time.Duration
+	SumOfPauses  time.Duration
+	LongestPause time.Duration
+	Pauses       []Pause
+
+	// Before and After are nil when jdk.GCHeapSummary is disabled.
+	Before *HeapUsage
+	After  *HeapUsage
+}
+
+type Pause struct {
+	Name       string
+	StartNanos int64
+	Duration   time.Duration
+}
+
+type HeapUsage struct {
+	TimestampNanos int64
+	Used           uint64
+	Committed      uint64
+}
+
+type collections struct {
+	byID map[uint32]*Collection
+}
+
+func newCollections() *collections {
+	return &collections{byID: make(map[uint32]*Collection)}
+}
+
+func (c *collections) get(id uint32) *Collection {
+	gc := c.byID[id]
+	if gc == nil {
+		gc = &Collection{ID: id}
+		c.byID[id] = gc
+	}
+	return gc
+}
+
+func (c *collections) addGarbageCollection(p *parser.Parser, typ def.TypeID) {
+	e := &p.GarbageCollection
+	gc := c.get(e.GcId)
+	if n := p.GetGCName(e.Name); n != nil {
+		gc.Name = n.Name
+	}
+	if cause := p.GetGCCause(e.Cause); cause != nil {
+		gc.Cause = cause.Cause
+	}
+	gc.StartNanos = p.TimestampNanos(typ, "startTime", int64(e.StartTime))
+	gc.Duration = time.Duration(p.TimespanNanos(typ, "duration", int64(e.Duration)))
+	gc.SumOfPauses = time.Duration(p.TimespanNanos(typ, "sumOfPauses", int64(e.SumOfPauses)))
+	gc.LongestPause = time.Duration(p.TimespanNanos(typ, "longestPause", int64(e.LongestPause)))
+}
+
+func (c *collections) addPhasePause(p *parser.Parser, typ def.TypeID) {
+	e := &p.GCPhasePause
+	gc := c.get(e.GcId)
+	gc.Pauses = append(gc.Pauses, Pause{
+		Name:       e.Name,
+		StartNanos: p.TimestampNanos(typ, "startTime", int64(e.StartTime)),
+		Duration:   time.Duration(p.TimespanNanos(typ, "duration", int64(e.Duration))),
+	})
+}
+
+func (c *collections) addHeapSummary(p *parser.Parser, typ def.TypeID) {
+	e := &p.GCHeapSummary
+	u := &HeapUsage{
+		TimestampNanos: p.TimestampNanos(typ, "startTime", int64(e.StartTime)),
+		Used:           e.HeapUsed,
+		Committed:      e.HeapSpace.CommittedSize,
+	}
+	gc := c.get(e.GcId)
+	when := p.GetGCWhen(e.When)
+	switch {
+	case when == nil:
+	case when.When == "Before GC":
+		gc.Before = u
+	case when.When == "After GC":
+		gc.After = u
+	}
+}
+
+func (c *collections) build() []Collection {
+	res := make([]Collection, 0, len(c.byID))
+	for _, gc := range c.byID {
+		res = append(res, *gc)
+	}
+	slices.SortFunc(res, func(a, b Collection) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return res
+}
+
+// GCSeries returns the garbage collection time series:
+//
+//	jvm_gc_pause_seconds{gc}            counter, total pause time per collector
+//	jvm_gc_collections{generation}      counter, number of young and old collections
+//	jvm_gc_heap_used_bytes{when}        gauge, heap used before and after GC
+//	jvm_gc_heap_committed_bytes{when}   gauge, heap committed before and after GC
+func (s *Stats) GCSeries() []Series {
+	var (
+		pauses      []Series
+		pauseIndex  = map[string]int{}
+		pauseTotals []time.Duration
+		counts      [3]*Series
+		heap        [4]*Series
+	)
+	for i := range s.Collections {
+		gc := &s.Collections[i]
+		if gc.Name != "" {
+			j, ok := pauseIndex[gc.Name]
+			if !ok {
+				j = len(pauses)
+				pauseIndex[gc.Name] = j
+				pauses = append(pauses, Series{
+					Name:   "jvm_gc_pause_seconds",
+					Help:   "Total time the application was paused by garbage collections.",
+					Type:   Counter,
+					Labels: []Label{{Name: "gc", Value: gc.Name}},
+				})
+				pauseTotals = append(pauseTotals, 0)
+			}
+			pauseTotals[j] += gc.SumOfPauses
+			pauses[j].Samples = append(pauses[j].Samples, Sample{TimestampNanos: gc.StartNanos, Value: pauseTotals[j].Seconds()})
+
+			c := counts[gc.Generation]
+			if c == nil {
+				c = &Series{
+					Name:   "jvm_gc_collections",
+					Help:   "Number of garbage collections.",
+					Type:   Counter,
+					Labels: []Label{{Name: "generation", Value: gc.Generation.String()}},
+				}
+				counts[gc.Generation] = c
+			}
+			n := float64(len(c.Samples) + 1)
+			c.Samples = append(c.Samples, Sample{TimestampNanos: gc.StartNanos, Value: n})
+		}
+		for k, u := range [2]*HeapUsage{gc.Before, gc.After} {
+			if u == nil {
+				continue
+			}
+			when := "before"
+			if k == 1 {
+				when = "after"
+			}
+			if heap[k] == nil {
+				heap[k] = &Series{
+					Name:   "jvm_gc_heap_used_bytes",
+					Help:   "Java heap used around garbage collections.",
+					Type:   Gauge,
+					Labels: []Label{{Name: "when", Value: when}},
+				}
+				heap[k+2] = &Series{
+					Name:   "jvm_gc_heap_committed_bytes",
+					Help:   "Java heap committed around garbage collections.",
+					Type:   Gauge,
+					Labels: []Label{{Name: "when", Value: when}},
+				}
+			}
+			heap[k].Samples = append(heap[k].Samples, Sample{TimestampNanos: u.TimestampNanos, Value: float64(u.Used)})
+			heap[k+2].Samples = append(heap[k+2].Samples, Sample{TimestampNanos: u.TimestampNanos, Value: float64(u.Committed)})
+		}
+	}
+
+	res := pauses
+	for _, c := range counts {
+		if c != nil {
+			res = append(res, *c)
+		}
+	}
+	for _, h := range heap {
+		if h != nil {
+			res = append(res, *h)
+		}
+	}
+	sortSamples(res)
+	return res
+}
@@ -0,0 +1,218 @@
+package jvmstats
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/grafana/jfr-parser/parser"
+	"github.com/grafana/jfr-parser/parser/types"
+	"github.com/grafana/jfr-parser/parser/types/def"
+)
+
+// Compilation is a jdk.Compilation event, joined with the jdk.CompilationFailure
+// event of the same compile id if the compilation failed.
+type Compilation struct {
+	StartNanos    int64
+	DurationNanos int64
+	CompileID     uint32
+	// Compiler is e.g. "c1" or "c2".
+	Compiler string
+	// Method is the compiled method, e.g. java.lang.String.hashCode.
+	Method string
+	// Level is the tiered compilation level, 1-3 are C1 and 4 is C2.
+	Level          int
+	Succeeded      bool
+	OSR            bool
+	CodeSize       uint64
+	InlinedBytes   uint64
+	FailureMessage string
+}
+
+// Deoptimization is a jdk.Deoptimization event.
+type Deoptimization struct {
+	TimestampNanos int64
+	CompileID      uint32
+	Compiler       string
+	Method         string
+	LineNumber     int32
+	BCI            int32
+	// Instruction is the bytecode that triggered the deoptimization, e.g. "invokevirtual".
+	Instruction string
+	// Reason is e.g. "unstable_if" or "class_check".
+	Reason string
+	// Action is e.g. "reinterpret" or "make_not_entrant".
+	Action string
+}
+
+type compilations struct {
+	compilations []Compilation
+	failures     map[uint32]string
+}
+
+func (c *compilations) addCompilation(p *parser.Parser, typ def.TypeID) {
+	e := &p.Compilation
+	c.compilations = append(c.compilations, Compilation{
+		StartNanos:    p.TimestampNanos(typ, "startTime", int64(e.StartTime)),
+		DurationNanos: p.TimespanNanos(typ, "duration", int64(e.Duration)),
+		CompileID:     e.CompileId,
+		Compiler:      compilerName(p, e.Compiler),
+		Method:        methodName(p, e.Method),
+		Level:         int(e.CompileLevel),
+		Succeeded:     e.Succeded,
+		OSR:           e.IsOsr,
+		CodeSize:      e.CodeSize,
+		InlinedBytes:  e.InlinedBytes,
+	})
+}
+
+func (c *compilations) addFailure(p *parser.Parser) {
+	if c.failures == nil {
+		c.failures = make(map[uint32]string)
+	}
+	c.failures[p.CompilationFailure.CompileId] = p.CompilationFailure.FailureMessage
+}
+
+// build returns the compilations ordered by start time. Failures are joined once all
+// events are read, they are not necessarily recorded next to their compilation.
+func (c *compilations) build() []Compilation {
+	for i := range c.compilations {
+		if msg, ok := c.failures[c.compilations[i].CompileID]; ok {
+			c.compilations[i].FailureMessage = msg
+		}
+	}
+	slices.SortStableFunc(c.compilations, func(a, b Compilation) int {
+		return cmp.Compare(a.StartNanos, b.StartNanos)
+	})
+	return c.compilations
+}
+
+func newDeoptimization(p *parser.Parser, typ def.TypeID) Deoptimization {
+	e := &p.Deoptimization
+	res := Deoptimization{
+		TimestampNanos: p.TimestampNanos(typ, "startTime", int64(e.StartTime)),
+		CompileID:      e.CompileId,
+		Compiler:       compilerName(p, e.Compiler),
+		Method:         methodName(p, e.Method),
+		LineNumber:     int32(e.LineNumber),
+		BCI:            int32(e.Bci),
+	}
+	if b := p.GetBytecode(e.Instruction); b != nil {
+		res.Instruction = b.Bytecode
+	}
+	if r := p.GetDeoptimizationReason(e.Reason); r != nil {
+		res.Reason = r.Reason
+	}
+	if a := p.GetDeoptimizationAction(e.Action); a != nil {
+		res.Action = a.Action
+	}
+	return res
+}
+
+func compilerName(p *parser.Parser, ref types.CompilerTypeRef) string {
+	if c := p.GetCompilerType(ref); c != nil {
+		return c.Compiler
+	}
+	return ""
+}
+
+// methodName returns the method in Java notation, e.g. java.lang.String.hashCode.
+func methodName(p *parser.Parser, ref types.MethodRef) string {
+	m := p.GetMethod(ref)
+	if m == nil {
+		return ""
+	}
+	name := p.GetSymbolString(m.Name)
+	if cls := p.GetClass(m.Type); cls != nil {
+		name = strings.ReplaceAll(p.GetSymbolString(cls.Name), "/", ".") + "." + name
+	}
+	return name
+}
+
+// JITReport aggregates the compilations and deoptimizations of a recording.
+type JITReport struct {
+	// Methods are ordered by total compile time, longest first.
+	Methods []MethodCompilations
+	// Deoptimizations are ordered by count, most frequent first.
+	Deoptimizations []DeoptimizationCount
+}
+
+// MethodCompilations is the compile time of a method at a tiered compilation level.
+type MethodCompilations struct {
+	Method     string
+	Level      int
+	Count      int
+	Failed     int
+	TotalNanos int64
+	MaxNanos   int64
+	CodeSize   uint64
+}
+
+type DeoptimizationCount struct {
+	Reason string
+	Action string
+	Count  int
+}
+
+// JITReport returns the compile time per method and level and the deoptimization
+// counts per reason and action.
+func (s *Stats) JITReport() JITReport {
+	type methodLevel struct {
+		method string
+		level  int
+	}
+	res := JITReport{}
+	methods := map[methodLevel]int{}
+	for _, c := range s.Compilations {
+		k := methodLevel{c.Method, c.Level}
+		i, ok := methods[k]
+		if !ok {
+			i = len(res.Methods)
+			methods[k] = i
+			res.Methods = append(res.Methods, MethodCompilations{Method: c.Method, Level: c.Level})
+		}
+		m := &res.Methods[i]
+		m.Count++
+		if !c.Succeeded {
+			m.Failed++
+		}
+		m.TotalNanos += c.DurationNanos
+		m.MaxNanos = max(m.MaxNanos, c.DurationNanos)
+		m.CodeSize += c.CodeSize
+	}
+	slices.SortStableFunc(res.Methods, func(a, b MethodCompilations) int {
+		if c := cmp.Compare(b.TotalNanos, a.TotalNanos); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Method, b.Method); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Level, b.Level)
+	})
+
+	type reasonAction struct {
+		reason string
+		action string
+	}
+	deopts := map[reasonAction]int{}
+	for _, d := range s.Deoptimizations {
+		k := reasonAction{d.Reason, d.Action}
+		i, ok := deopts[k]
+		if !ok {
+			i = len(res.Deoptimizations)
+			deopts[k] = i
+			res.Deoptimizations = append(res.Deoptimizations, DeoptimizationCount{Reason: d.Reason, Action: d.Action})
+		}
+		res.Deoptimizations[i].Count++
+	}
+	slices.SortStableFunc(res.Deoptimizations, func(a, b DeoptimizationCount) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Reason, b.Reason); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Action, b.Action)
+	})
+	return res
+}
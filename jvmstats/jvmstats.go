@@ -0,0 +1,148 @@
+// Package jvmstats converts JVM runtime events from JFR recordings, such as
+// garbage collections, into plain structs and time series.
+package jvmstats
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/grafana/jfr-parser/internal/panics"
+	"github.com/grafana/jfr-parser/parser"
+	"github.com/grafana/jfr-parser/parser/types/def"
+)
+
+type jvmstatsOptions struct {
+	disablePanicRecovery bool
+}
+
+type Option func(*jvmstatsOptions)
+
+func WithDisablePanicRecovery(v bool) Option {
+	return func(o *jvmstatsOptions) {
+		o.disablePanicRecovery = v
+	}
+}
+
+// Stats holds the JVM runtime events of a recording.
+type Stats struct {
+	// Collections are ordered by GC id.
+	Collections []Collection
+	// CPULoad and ThreadCPULoad are in recording order.
+	CPULoad       []CPULoad
+	ThreadCPULoad []ThreadCPULoad
+	// ExceptionStatistics are in recording order.
+	ExceptionStatistics []ExceptionStatistics
+	// Compilations are ordered by start time, Deoptimizations are in recording order.
+	Compilations    []Compilation
+	Deoptimizations []Deoptimization
+	// Safepoints are ordered by safepoint id, VMOperations are in recording order.
+	Safepoints   []Safepoint
+	VMOperations []VMOperation
+}
+
+func ParseJFR(body []byte, opts ...Option) (res *Stats, err error) {
+	o := &jvmstatsOptions{}
+	for i := range opts {
+		opts[i](o)
+	}
+	if !o.disablePanicRecovery {
+		defer panics.Recover(&err)
+	}
+	p := parser.NewParser(body, parser.Options{})
+	return parse(p)
+}
+
+func parse(p *parser.Parser) (*Stats, error) {
+	b := NewBuilder()
+	for {
+		typ, err := p.ParseEvent()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("jfr parser ParseEvent error: %w", err)
+		}
+		b.Add(p, typ)
+	}
+	return b.Build(), nil
+}
+
+// Builder collects Stats from events read by a caller iterating over a recording
+// with ParseEvent, so the stats can be gathered in the same pass as other data.
+type Builder struct {
+	res        Stats
+	gcs        *collections
+	jit        compilations
+	safepoints safepoints
+}
+
+func NewBuilder() *Builder {
+	return &Builder{gcs: newCollections()}
+}
+
+// Add records the current event of p if it is one of the JVM runtime events.
+// It reports whether the event was used.
+func (b *Builder) Add(p *parser.Parser, typ def.TypeID) bool {
+	res := &b.res
+	switch typ {
+	case p.TypeMap.T_GARBAGE_COLLECTION:
+		b.gcs.addGarbageCollection(p, typ)
+	case p.TypeMap.T_GC_PHASE_PAUSE:
+		b.gcs.addPhasePause(p, typ)
+	case p.TypeMap.T_GC_HEAP_SUMMARY:
+		b.gcs.addHeapSummary(p, typ)
+	case p.TypeMap.T_YOUNG_GC:
+		b.gcs.get(p.YoungGarbageCollection.GcId).Generation = GenerationYoung
+	case p.TypeMap.T_OLD_GC:
+		b.gcs.get(p.OldGarbageCollection.GcId).Generation = GenerationOld
+	case p.TypeMap.T_CPU_LOAD:
+		res.CPULoad = append(res.CPULoad, newCPULoad(p, typ))
+	case p.TypeMap.T_THREAD_CPU_LOAD:
+		res.ThreadCPULoad = append(res.ThreadCPULoad, newThreadCPULoad(p, typ))
+	case p.TypeMap.T_EXCEPTION_STATISTICS:
+		res.ExceptionStatistics = append(res.ExceptionStatistics, newExceptionStatistics(p, typ))
+	case p.TypeMap.T_COMPILATION:
+		b.jit.addCompilation(p, typ)
+	case p.TypeMap.T_COMPILATION_FAILURE:
+		b.jit.addFailure(p)
+	case p.TypeMap.T_DEOPTIMIZATION:
+		res.Deoptimizations = append(res.Deoptimizations, newDeoptimization(p, typ))
+	case p.TypeMap.T_SAFEPOINT_BEGIN:
+		b.safepoints.addBegin(p, typ)
+	case p.TypeMap.T_SAFEPOINT_STATE_SYNCHRONIZATION:
+		b.safepoints.addStateSynchronization(p, typ)
+	case p.TypeMap.T_SAFEPOINT_END:
+		b.safepoints.addEnd(p, typ)
+	case p.TypeMap.T_EXECUTE_VM_OPERATION:
+		res.VMOperations = append(res.VMOperations, b.safepoints.addVMOperation(p, typ))
+	default:
+		return false
+	}
+	return true
+}
+
+// Build joins the events added so far. The Builder must not be used afterwards.
+func (b *Builder) Build() *Stats {
+	res := &b.res
+	res.Collections = b.gcs.build()
+	res.Compilations = b.jit.build()
+	res.Safepoints = b.safepoints.build()
+	return res
+}
+
+// Series returns all time series derived from the recording, see GCSeries,
+// CPUSeries and ExceptionSeries.
+func (s *Stats) Series() []Series {
+	res := append(s.GCSeries(), s.CPUSeries()...)
+	return append(res, s.ExceptionSeries()...)
+}
+
+func sortSamples(series []Series) {
+	for i := range series {
+		slices.SortStableFunc(series[i].Samples, func(a, b Sample) int {
+			return cmp.Compare(a.TimestampNanos, b.TimestampNanos)
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package jvmstats
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/jfr-parser/internal/testutil"
+)
+
+func TestGarbageCollections(t *testing.T) {
+	s, err := ParseJFR(testutil.ReadGzipFile(t, "object-allocation-sample.jfr.gz"))
+	require.NoError(t, err)
+	require.Len(t, s.Collections, 114)
+	for _, gc := range s.Collections {
+		assert.Equal(t, "G1New", gc.Name)
+		assert.Equal(t, GenerationYoung, gc.Generation)
+		assert.NotEmpty(t, gc.Cause)
+		assert.Positive(t, gc.SumOfPauses)
+		assert.LessOrEqual(t, gc.LongestPause, gc.SumOfPauses)
+		require.NotEmpty(t, gc.Pauses)
+		require.NotNil(t, gc.Before)
+		require.NotNil(t, gc.After)
+		assert.Less(t, gc.After.Used, gc.Before.Used)
+		assert.LessOrEqual(t, gc.Before.Used, gc.Before.Committed)
+		assert.LessOrEqual(t, gc.StartNanos, gc.After.TimestampNanos)
+	}
+}
+
+func TestGCSeries(t *testing.T) {
+	s, err := ParseJFR(testutil.ReadGzipFile(t, "object-allocation-sample.jfr.gz"))
+	require.NoError(t, err)
+	series := s.GCSeries()
+	names := make([]string, 0, len(series))
+	for _, ts := range series {
+		names = append(names, ts.Name+formatLabels(ts.Labels))
+		assert.Len(t, ts.Samples, 114)
+		for i := 1; i < len(ts.Samples); i++ {
+			assert.LessOrEqual(t, ts.Samples[i-1].TimestampNanos, ts.Samples[i].TimestampNanos)
+		}
+	}
+	assert.Equal(t, []string{
+		`jvm_gc_pause_seconds{gc="G1New"}`,
+		`jvm_gc_collections{generation="young"}`,
+		`jvm_gc_heap_used_bytes{when="before"}`,
+		`jvm_gc_heap_used_bytes{when="after"}`,
+		`jvm_gc_heap_committed_bytes{when="before"}`,
+		`jvm_gc_heap_committed_bytes{when="after"}`,
+	}, names)
+	assert.Equal(t, float64(114), series[1].Samples[113].Value)
+}
+
+func TestCPULoad(t *testing.T) {
+	s, err := ParseJFR(testutil.ReadGzipFile(t, "object-allocation-sample.jfr.gz"))
+	require.NoError(t, err)
+	require.Len(t, s.CPULoad, 60)
+	for _, l := range s.CPULoad {
+		for _, v := range []float32{l.JVMUser, l.JVMSystem, l.MachineTotal} {
+			assert.True(t, v >= 0 && v <= 1, v)
+		}
+		assert.LessOrEqual(t, l.JVMUser+l.JVMSystem, l.MachineTotal+0.01)
+	}
+	require.NotEmpty(t, s.ThreadCPULoad)
+	threads := map[string]bool{}
+	for _, l := range s.ThreadCPULoad {
+		assert.NotEmpty(t, l.Thread)
+		assert.True(t, l.User >= 0 && l.User <= 1, l.User)
+		assert.True(t, l.System >= 0 && l.System <= 1, l.System)
+		threads[l.Thread] = true
+	}
+	assert.True(t, threads["JFR Recorder Thread"])
+
+	series := s.CPUSeries()
+	require.Len(t, series, 3+2*len(threads))
+	assert.Equal(t, "jvm_cpu_machine_load", series[2].Name)
+	assert.Len(t, series[2].Samples, 60)
+}
+
+func TestExceptionStatistics(t *testing.T) {
+	s, err := ParseJFR(testutil.ReadGzipFile(t, "event-with-type-zero.jfr.gz"))
+	require.NoError(t, err)
+	require.Len(t, s.ExceptionStatistics, 118)
+
+	s, err = ParseJFR(testutil.ReadGzipFile(t, "cpool-uint64-constant-index.jfr.gz"))
+	require.NoError(t, err)
+	require.Len(t, s.ExceptionStatistics, 5)
+	series := s.ExceptionSeries()
+	require.Len(t, series, 1)
+	assert.Equal(t, "jvm_exceptions_thrown", series[0].Name)
+	assert.Equal(t, Counter, series[0].Type)
+	samples := series[0].Samples
+	require.Len(t, samples, 5)
+	assert.Positive(t, samples[0].Value)
+	for i := 1; i < len(samples); i++ {
+		assert.LessOrEqual(t, samples[i-1].Value, samples[i].Value)
+	}
+}
+
+func TestJITReport(t *testing.T) {
+	s, err := ParseJFR(testutil.ReadGzipFile(t, "event-with-type-zero.jfr.gz"))
+	require.NoError(t, err)
+	require.Len(t, s.Compilations, 1)
+	c := s.Compilations[0]
+	assert.Equal(t, "sun.nio.ch.SelectorImpl.lockAndDoSelect", c.Method)
+	assert.Equal(t, "c2", c.Compiler)
+	assert.Equal(t, 4, c.Level)
+	assert.True(t, c.Succeeded)
+	assert.Positive(t, c.DurationNanos)
+
+	require.Len(t, s.Deoptimizations, 68)
+	for _, d := range s.Deoptimizations {
+		assert.NotEmpty(t, d.Method)
+		assert.NotEmpty(t, d.Reason)
+		assert.NotEmpty(t, d.Action)
+	}
+	r := s.JITReport()
+	require.Len(t, r.Methods, 1)
+	assert.Equal(t, c.DurationNanos, r.Methods[0].TotalNanos)
+	require.NotEmpty(t, r.Deoptimizations)
+	assert.Equal(t, DeoptimizationCount{Reason: "unstable_if", Action: "reinterpret", Count: 32}, r.Deoptimizations[0])
+	total := 0
+	for _, d := range r.Deoptimizations {
+		total += d.Count
+	}
+	assert.Equal(t, 68, total)
+
+	s, err = ParseJFR(testutil.ReadGzipFile(t, "object-allocation-sample.jfr.gz"))
+	require.NoError(t, err)
+	require.Len(t, s.Compilations, 1)
+	assert.False(t, s.Compilations[0].Succeeded)
+	assert.Equal(t, "Jvmti state change invalidated dependencies", s.Compilations[0].FailureMessage)
+	assert.Equal(t, 1, s.JITReport().Methods[0].Failed)
+}
+
+func TestSafepoints(t *testing.T) {
+	s, err := ParseJFR(testutil.ReadGzipFile(t, "object-allocation-sample.jfr.gz"))
+	require.NoError(t, err)
+	require.Len(t, s.Safepoints, 146)
+	require.Len(t, s.VMOperations, 146)
+	for i, sp := range s.Safepoints {
+		assert.Equal(t, s.Safepoints[0].ID+int64(i), sp.ID)
+		if i == len(s.Safepoints)-1 {
+			// the recording ends before the last safepoint
+			assert.Zero(t, sp.TotalNanos)
+			continue
+		}
+		assert.NotEmpty(t, sp.Operation)
+		assert.LessOrEqual(t, sp.TimeToSafepointNanos, sp.TotalNanos)
+		assert.LessOrEqual(t, sp.OperationNanos, sp.TotalNanos)
+	}
+	sp := s.Safepoints[2]
+	assert.Equal(t, int64(138), sp.ID)
+	assert.Equal(t, "G1CollectForAllocation", sp.Operation)
+	assert.Equal(t, int64(88272), sp.TimeToSafepointNanos)
+	assert.Equal(t, int64(1802432), sp.TotalNanos)
+
+	op := s.VMOperations[0]
+	assert.Equal(t, "RedefineClasses", op.Name)
+	assert.Equal(t, "VM Thread", op.Thread)
+	assert.Equal(t, "dd-profiler-recording-scheduler", op.Caller)
+	assert.Equal(t, int64(136), op.SafepointID)
+}
+
+func TestWriteOpenMetrics(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	err := WriteOpenMetrics(buf, []Series{
+		{Name: "jvm_gc_collections", Help: "Number of garbage collections.", Type: Counter,
+			Labels:  []Label{{Name: "generation", Value: "young"}},
+			Samples: []Sample{{TimestampNanos: 1_500_000_000, Value: 1}, {TimestampNanos: 2_000_000_000, Value: 2}}},
+		{Name: "jvm_gc_heap_used_bytes", Type: Gauge,
+			Labels:  []Label{{Name: "when", Value: "a \"b\"\n"}},
+			Samples: []Sample{{TimestampNanos: 1_000_000, Value: 1024}}},
+		{Name: "jvm_gc_collections", Type: Counter,
+			Labels:  []Label{{Name: "generation", Value: "old"}},
+			Samples: []Sample{{TimestampNanos: 3_000_000_000, Value: 1}}},
+	})
+	require.NoError(t, err)
+	expected := strings.Join([]string{
+		"# HELP jvm_gc_collections Number of garbage collections.",
+		"# TYPE jvm_gc_collections counter",
+		`jvm_gc_collections_total{generation="young"} 1 1.500`,
+		`jvm_gc_collections_total{generation="young"} 2 2.000`,
+		`jvm_gc_collections_total{generation="old"} 1 3.000`,
+		"# TYPE jvm_gc_heap_used_bytes gauge",
+		`jvm_gc_heap_used_bytes{when="a \"b\"\n"} 1024 0.001`,
+		"# EOF",
+		"",
+	}, "\n")
+	assert.Equal(t, expected, buf.String())
+}
@@ -0,0 +1,124 @@
+package jvmstats
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type MetricType string
+
+const (
+	Counter MetricType = "counter"
+	Gauge   MetricType = "gauge"
+)
+
+type Label struct {
+	Name  string
+	Value string
+}
+
+type Sample struct {
+	TimestampNanos int64
+	Value          float64
+}
+
+// Series is a metric time series. Name is the metric family name, counters
+// get the _total suffix when written.
+type Series struct {
+	Name    string
+	Help    string
+	Type    MetricType
+	Labels  []Label
+	Samples []Sample
+}
+
+// WriteOpenMetrics writes series in the OpenMetrics text format with a
+// timestamp on every sample, the input format of
+// `promtool tsdb create-blocks-from openmetrics`.
+// Series of the same family are grouped under a single TYPE line.
+func WriteOpenMetrics(w io.Writer, series []Series) error {
+	var families []string
+	byFamily := map[string][]*Series{}
+	for i := range series {
+		s := &series[i]
+		if _, ok := byFamily[s.Name]; !ok {
+			families = append(families, s.Name)
+		}
+		byFamily[s.Name] = append(byFamily[s.Name], s)
+	}
+	sb := strings.Builder{}
+	for _, name := range families {
+		fs := byFamily[name]
+		sb.Reset()
+		if fs[0].Help != "" {
+			sb.WriteString("# HELP " + name + " " + escapeHelp(fs[0].Help) + "\n")
+		}
+		sb.WriteString("# TYPE " + name + " " + string(fs[0].Type) + "\n")
+		sample := name
+		if fs[0].Type == Counter {
+			sample += "_total"
+		}
+		for _, s := range fs {
+			labels := formatLabels(s.Labels)
+			for _, v := range s.Samples {
+				sb.WriteString(sample + labels + " " + formatValue(v.Value) + " " + formatTimestamp(v.TimestampNanos) + "\n")
+			}
+		}
+		if _, err := io.WriteString(w, sb.String()); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "# EOF\n")
+	return err
+}
+
+func formatLabels(ls []Label) string {
+	if len(ls) == 0 {
+		return ""
+	}
+	sb := strings.Builder{}
+	sb.WriteByte('{')
+	for i, l := range ls {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(l.Name + `="` + escapeLabelValue(l.Value) + `"`)
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+var (
+	labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpReplacer       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabelValue(s string) string {
+	return labelValueReplacer.Replace(s)
+}
+
+func escapeHelp(s string) string {
+	return helpReplacer.Replace(s)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+// formatTimestamp formats nanoseconds since epoch as seconds with millisecond precision.
+func formatTimestamp(nanos int64) string {
+	ms := nanos / 1e6
+	return fmt.Sprintf("%d.%03d", ms/1000, ms%1000)
+}
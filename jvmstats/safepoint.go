@@ -0,0 +1,121 @@
+package jvmstats
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/grafana/jfr-parser/parser"
+	"github.com/grafana/jfr-parser/parser/types/def"
+)
+
+// Safepoint joins the jdk.SafepointBegin, jdk.SafepointStateSynchronization,
+// jdk.SafepointEnd and jdk.ExecuteVMOperation events of a safepoint id.
+type Safepoint struct {
+	ID         int64
+	StartNanos int64
+	// TimeToSafepointNanos is the time it took to bring all Java threads to a stop.
+	TimeToSafepointNanos int64
+	// TotalNanos is the time from the start of the safepoint until the threads were resumed.
+	// It is 0 if the recording ends before the safepoint.
+	TotalNanos int64
+	// Operation is the VM operation executed at the safepoint, e.g. "G1CollectForAllocation".
+	Operation          string
+	OperationNanos     int64
+	TotalThreads       int
+	JNICriticalThreads int
+}
+
+// VMOperation is a jdk.ExecuteVMOperation event.
+type VMOperation struct {
+	StartNanos    int64
+	DurationNanos int64
+	Name          string
+	Thread        string
+	Caller        string
+	Blocking      bool
+	// SafepointID is the id of the safepoint the operation executed at, 0 if it did not need one.
+	SafepointID int64
+}
+
+type safepoints struct {
+	byID map[int64]*Safepoint
+	// endNanos holds the jdk.SafepointEnd end time per id, jdk.SafepointBegin is not necessarily read first
+	endNanos map[int64]int64
+}
+
+func (s *safepoints) get(id int64) *Safepoint {
+	if s.byID == nil {
+		s.byID = make(map[int64]*Safepoint)
+	}
+	sp := s.byID[id]
+	if sp == nil {
+		sp = &Safepoint{ID: id}
+		s.byID[id] = sp
+	}
+	return sp
+}
+
+func (s *safepoints) addBegin(p *parser.Parser, typ def.TypeID) {
+	e := &p.SafepointBegin
+	sp := s.get(int64(e.SafepointId))
+	sp.StartNanos = p.TimestampNanos(typ, "startTime", int64(e.StartTime))
+	sp.TotalThreads = int(e.TotalThreadCount)
+	sp.JNICriticalThreads = int(e.JniCriticalThreadCount)
+	if sp.TimeToSafepointNanos == 0 {
+		sp.TimeToSafepointNanos = p.TimespanNanos(typ, "duration", int64(e.Duration))
+	}
+}
+
+func (s *safepoints) addStateSynchronization(p *parser.Parser, typ def.TypeID) {
+	e := &p.SafepointStateSynchronization
+	s.get(int64(e.SafepointId)).TimeToSafepointNanos = p.TimespanNanos(typ, "duration", int64(e.Duration))
+}
+
+func (s *safepoints) addEnd(p *parser.Parser, typ def.TypeID) {
+	e := &p.SafepointEnd
+	if s.endNanos == nil {
+		s.endNanos = make(map[int64]int64)
+	}
+	start := p.TimestampNanos(typ, "startTime", int64(e.StartTime))
+	s.endNanos[int64(e.SafepointId)] = start + p.TimespanNanos(typ, "duration", int64(e.Duration))
+}
+
+func (s *safepoints) addVMOperation(p *parser.Parser, typ def.TypeID) VMOperation {
+	e := &p.ExecuteVMOperation
+	op := VMOperation{
+		StartNanos:    p.TimestampNanos(typ, "startTime", int64(e.StartTime)),
+		DurationNanos: p.TimespanNanos(typ, "duration", int64(e.Duration)),
+		Thread:        threadName(p, e.EventThread),
+		Caller:        threadName(p, e.Caller),
+		Blocking:      e.Blocking,
+	}
+	if t := p.GetVMOperationType(e.Operation); t != nil {
+		op.Name = t.Type
+	}
+	if e.Safepoint {
+		op.SafepointID = int64(e.SafepointId)
+		sp := s.get(op.SafepointID)
+		sp.Operation = op.Name
+		sp.OperationNanos = op.DurationNanos
+	}
+	return op
+}
+
+// build returns the safepoints ordered by id. Safepoints cut by a chunk
+// boundary without a jdk.SafepointBegin event are dropped.
+func (s *safepoints) build() []Safepoint {
+	res := make([]Safepoint, 0, len(s.byID))
+	for _, sp := range s.byID {
+		if sp.StartNanos == 0 {
+			continue
+		}
+		if end, ok := s.endNanos[sp.ID]; ok {
+			sp.TotalNanos = end - sp.StartNanos
+		}
+		res = append(res, *sp)
+	}
+	slices.SortFunc(res, func(a, b Safepoint) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return res
+}
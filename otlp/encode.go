@@ -0,0 +1,245 @@
+package otlp
+
+import (
+	"fmt"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Marshal encodes d as an opentelemetry.proto.profiles.v1development.ProfilesData
+// message. The bytes can be checked against the proto definitions with e.g.
+// protoc --decode opentelemetry.proto.profiles.v1development.ProfilesData.
+func (d *ProfilesData) Marshal() ([]byte, error) {
+	var b []byte
+	for i := range d.ResourceProfiles {
+		b = appendMessage(b, 1, d.ResourceProfiles[i].marshal)
+	}
+	b = appendMessage(b, 2, d.Dictionary.marshal)
+	return b, nil
+}
+
+func (d *ProfilesDictionary) marshal(b []byte) []byte {
+	for i := range d.MappingTable {
+		b = appendMessage(b, 1, d.MappingTable[i].marshal)
+	}
+	for i := range d.LocationTable {
+		b = appendMessage(b, 2, d.LocationTable[i].marshal)
+	}
+	for i := range d.FunctionTable {
+		b = appendMessage(b, 3, d.FunctionTable[i].marshal)
+	}
+	for i := range d.LinkTable {
+		b = appendMessage(b, 4, d.LinkTable[i].marshal)
+	}
+	for _, s := range d.StringTable {
+		// repeated strings keep empty entries, the string table starts with ""
+		b = protowire.AppendTag(b, 5, protowire.BytesType)
+		b = protowire.AppendString(b, s)
+	}
+	for i := range d.AttributeTable {
+		b = appendMessage(b, 6, d.AttributeTable[i].marshal)
+	}
+	return b
+}
+
+func (r *ResourceProfiles) marshal(b []byte) []byte {
+	b = appendMessage(b, 1, r.Resource.marshal)
+	for i := range r.ScopeProfiles {
+		b = appendMessage(b, 2, r.ScopeProfiles[i].marshal)
+	}
+	return appendString(b, 3, r.SchemaURL)
+}
+
+func (r *Resource) marshal(b []byte) []byte {
+	for i := range r.Attributes {
+		b = appendMessage(b, 1, r.Attributes[i].marshal)
+	}
+	return b
+}
+
+func (s *ScopeProfiles) marshal(b []byte) []byte {
+	b = appendMessage(b, 1, s.Scope.marshal)
+	for i := range s.Profiles {
+		b = appendMessage(b, 2, s.Profiles[i].marshal)
+	}
+	return appendString(b, 3, s.SchemaURL)
+}
+
+func (s *InstrumentationScope) marshal(b []byte) []byte {
+	b = appendString(b, 1, s.Name)
+	return appendString(b, 2, s.Version)
+}
+
+func (p *Profile) marshal(b []byte) []byte {
+	for i := range p.SampleType {
+		b = appendMessage(b, 1, p.SampleType[i].marshal)
+	}
+	for i := range p.Sample {
+		b = appendMessage(b, 2, p.Sample[i].marshal)
+	}
+	b = appendPackedInt32(b, 3, p.LocationIndices)
+	b = appendVarint(b, 4, uint64(p.TimeNanos))
+	b = appendVarint(b, 5, uint64(p.DurationNanos))
+	if p.PeriodType != (ValueType{}) {
+		b = appendMessage(b, 6, p.PeriodType.marshal)
+	}
+	b = appendVarint(b, 7, uint64(p.Period))
+	b = appendVarint(b, 9, uint64(p.DefaultSampleTypeIndex))
+	b = appendBytes(b, 10, p.ProfileID)
+	b = appendString(b, 12, p.OriginalPayloadFormat)
+	b = appendBytes(b, 13, p.OriginalPayload)
+	return appendPackedInt32(b, 14, p.AttributeIndices)
+}
+
+func (v *ValueType) marshal(b []byte) []byte {
+	b = appendVarint(b, 1, uint64(v.TypeStrindex))
+	b = appendVarint(b, 2, uint64(v.UnitStrindex))
+	return appendVarint(b, 3, uint64(v.AggregationTemporality))
+}
+
+func (s *Sample) marshal(b []byte) []byte {
+	b = appendVarint(b, 1, uint64(s.LocationsStartIndex))
+	b = appendVarint(b, 2, uint64(s.LocationsLength))
+	if len(s.Value) > 0 {
+		var packed []byte
+		for _, v := range s.Value {
+			packed = protowire.AppendVarint(packed, uint64(v))
+		}
+		b = appendBytes(b, 3, packed)
+	}
+	b = appendPackedInt32(b, 4, s.AttributeIndices)
+	if s.LinkIndex != 0 {
+		b = protowire.AppendTag(b, 5, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(s.LinkIndex))
+	}
+	if len(s.TimestampsUnixNano) > 0 {
+		var packed []byte
+		for _, v := range s.TimestampsUnixNano {
+			packed = protowire.AppendVarint(packed, v)
+		}
+		b = appendBytes(b, 6, packed)
+	}
+	return b
+}
+
+func (m *Mapping) marshal(b []byte) []byte {
+	b = appendVarint(b, 1, m.MemoryStart)
+	b = appendVarint(b, 2, m.MemoryLimit)
+	b = appendVarint(b, 3, m.FileOffset)
+	b = appendVarint(b, 4, uint64(m.FilenameStrindex))
+	b = appendPackedInt32(b, 5, m.AttributeIndices)
+	b = appendBool(b, 6, m.HasFunctions)
+	b = appendBool(b, 7, m.HasFilenames)
+	b = appendBool(b, 8, m.HasLineNumbers)
+	return appendBool(b, 9, m.HasInlineFrames)
+}
+
+func (l *Location) marshal(b []byte) []byte {
+	if l.MappingIndex != 0 {
+		b = protowire.AppendTag(b, 1, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(l.MappingIndex))
+	}
+	b = appendVarint(b, 2, l.Address)
+	for i := range l.Line {
+		b = appendMessage(b, 3, l.Line[i].marshal)
+	}
+	b = appendBool(b, 4, l.IsFolded)
+	return appendPackedInt32(b, 5, l.AttributeIndices)
+}
+
+func (l *Line) marshal(b []byte) []byte {
+	b = appendVarint(b, 1, uint64(l.FunctionIndex))
+	b = appendVarint(b, 2, uint64(l.Line))
+	return appendVarint(b, 3, uint64(l.Column))
+}
+
+func (f *Function) marshal(b []byte) []byte {
+	b = appendVarint(b, 1, uint64(f.NameStrindex))
+	b = appendVarint(b, 2, uint64(f.SystemNameStrindex))
+	b = appendVarint(b, 3, uint64(f.FilenameStrindex))
+	return appendVarint(b, 4, uint64(f.StartLine))
+}
+
+func (l *Link) marshal(b []byte) []byte {
+	b = appendBytes(b, 1, l.TraceID)
+	return appendBytes(b, 2, l.SpanID)
+}
+
+func (kv *KeyValue) marshal(b []byte) []byte {
+	b = appendString(b, 1, kv.Key)
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	return protowire.AppendBytes(b, appendAnyValue(nil, kv.Value))
+}
+
+// appendAnyValue encodes an opentelemetry.proto.common.v1.AnyValue.
+func appendAnyValue(b []byte, v any) []byte {
+	switch v := v.(type) {
+	case string:
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		return protowire.AppendString(b, v)
+	case bool:
+		b = protowire.AppendTag(b, 2, protowire.VarintType)
+		return protowire.AppendVarint(b, protowire.EncodeBool(v))
+	case int64:
+		b = protowire.AppendTag(b, 3, protowire.VarintType)
+		return protowire.AppendVarint(b, uint64(v))
+	case float64:
+		b = protowire.AppendTag(b, 4, protowire.Fixed64Type)
+		return protowire.AppendFixed64(b, math.Float64bits(v))
+	case []byte:
+		b = protowire.AppendTag(b, 7, protowire.BytesType)
+		return protowire.AppendBytes(b, v)
+	case nil:
+		return b
+	default:
+		panic(fmt.Sprintf("otlp: unsupported attribute value type %T", v))
+	}
+}
+
+// The append helpers omit fields with the zero value, as proto3 does for
+// fields without presence.
+
+func appendMessage(b []byte, num protowire.Number, marshal func([]byte) []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, marshal(nil))
+}
+
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func appendBool(b []byte, num protowire.Number, v bool) []byte {
+	return appendVarint(b, num, protowire.EncodeBool(v))
+}
+
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendPackedInt32(b []byte, num protowire.Number, vs []int32) []byte {
+	if len(vs) == 0 {
+		return b
+	}
+	var packed []byte
+	for _, v := range vs {
+		packed = protowire.AppendVarint(packed, uint64(v))
+	}
+	return appendBytes(b, num, packed)
+}
@@ -0,0 +1,132 @@
+package otlp
+
+// The types below mirror the messages of
+// opentelemetry/proto/profiles/v1development/profiles.proto and the common and
+// resource protos it references, as of opentelemetry-proto v1.7.0. Field
+// comments name the proto field where the Go name differs.
+
+type ProfilesData struct {
+	ResourceProfiles []ResourceProfiles
+	Dictionary       ProfilesDictionary
+}
+
+// ProfilesDictionary holds the tables shared by all profiles. Entry 0 of every
+// table is the zero value, indices of 0 mean unset.
+type ProfilesDictionary struct {
+	MappingTable   []Mapping
+	LocationTable  []Location
+	FunctionTable  []Function
+	LinkTable      []Link
+	StringTable    []string
+	AttributeTable []KeyValue
+}
+
+type ResourceProfiles struct {
+	Resource      Resource
+	ScopeProfiles []ScopeProfiles
+	SchemaURL     string
+}
+
+type Resource struct {
+	Attributes []KeyValue
+}
+
+type ScopeProfiles struct {
+	Scope     InstrumentationScope
+	Profiles  []Profile
+	SchemaURL string
+}
+
+type InstrumentationScope struct {
+	Name    string
+	Version string
+}
+
+type Profile struct {
+	SampleType []ValueType
+	Sample     []Sample
+	// LocationIndices are indices into ProfilesDictionary.LocationTable,
+	// samples reference a range of them.
+	LocationIndices        []int32
+	TimeNanos              int64
+	DurationNanos          int64
+	PeriodType             ValueType
+	Period                 int64
+	DefaultSampleTypeIndex int32
+	ProfileID              []byte
+	OriginalPayloadFormat  string
+	OriginalPayload        []byte
+	AttributeIndices       []int32
+}
+
+type AggregationTemporality int32
+
+const (
+	AggregationTemporalityUnspecified AggregationTemporality = iota
+	AggregationTemporalityDelta
+	AggregationTemporalityCumulative
+)
+
+type ValueType struct {
+	TypeStrindex           int32
+	UnitStrindex           int32
+	AggregationTemporality AggregationTemporality
+}
+
+type Sample struct {
+	LocationsStartIndex int32
+	LocationsLength     int32
+	Value               []int64
+	AttributeIndices    []int32
+	// LinkIndex is an index into ProfilesDictionary.LinkTable, 0 if the sample has no link.
+	LinkIndex          int32
+	TimestampsUnixNano []uint64
+}
+
+type Mapping struct {
+	MemoryStart      uint64
+	MemoryLimit      uint64
+	FileOffset       uint64
+	FilenameStrindex int32
+	AttributeIndices []int32
+	HasFunctions     bool
+	HasFilenames     bool
+	HasLineNumbers   bool
+	HasInlineFrames  bool
+}
+
+type Location struct {
+	// MappingIndex is an index into ProfilesDictionary.MappingTable, 0 if unset.
+	MappingIndex     int32
+	Address          uint64
+	Line             []Line
+	IsFolded         bool
+	AttributeIndices []int32
+}
+
+type Line struct {
+	FunctionIndex int32
+	Line          int64
+	Column        int64
+}
+
+type Function struct {
+	NameStrindex       int32
+	SystemNameStrindex int32
+	FilenameStrindex   int32
+	StartLine          int64
+}
+
+// Link references the span a sample was taken in. TraceID is 16 bytes and
+// SpanID is 8 bytes.
+type Link struct {
+	TraceID []byte
+	SpanID  []byte
+}
+
+// KeyValue is an attribute. Value holds a string, bool, int64, float64 or []byte,
+// the AnyValue variants attributes of this package use.
+type KeyValue struct {
+	Key   string
+	Value any
+}
@@ -0,0 +1,297 @@
+// Package otlp converts JFR recordings into the OpenTelemetry profiles signal,
+// the ProfilesData message of opentelemetry/proto/profiles/v1development.
+package otlp
+
+import (
+	"encoding/binary"
+	"slices"
+
+	"github.com/grafana/jfr-parser/pprof"
+)
+
+// ScopeName is the instrumentation scope of the converted profiles.
+const ScopeName = "github.com/grafana/jfr-parser/otlp"
+
+// Attribute keys, following the OpenTelemetry semantic conventions.
+const (
+	AttributeProcessPID            = "process.pid"
+	AttributeProcessRuntimeName    = "process.runtime.name"
+	AttributeProcessRuntimeVersion = "process.runtime.version"
+	AttributeProcessCommandArgs    = "process.command_args"
+	AttributeHostCPUModelName      = "host.cpu.model.name"
+	AttributeThreadName            = "thread.name"
+	// AttributeSpanID is the hex encoded id of the span a sample was taken in. JFR does not
+	// record trace ids, so samples carry the span id as an attribute rather than a Link.
+	AttributeSpanID = "span_id"
+)
+
+// labelSpanID is the pprof label of the span id, named after the pyroscope profile_id label.
+const labelSpanID = "profile_id"
+
+type otlpOptions struct {
+	disablePanicRecovery bool
+}
+
+type Option func(*otlpOptions)
+
+func WithDisablePanicRecovery(v bool) Option {
+	return func(o *otlpOptions) {
+		o.disablePanicRecovery = v
+	}
+}
+
+// ParseJFR converts a recording into the profiles of pprof.ParseJFR, with the same sample
+// types and units. Samples of the same stack, thread and labels are merged and keep the
+// timestamps of all merged events. jfrLabels resolves the ContextId of events into sample
+// attributes, it may be nil.
+func ParseJFR(body []byte, pi *pprof.ParseInput, jfrLabels *pprof.LabelsSnapshot, opts ...Option) (*ProfilesData, error) {
+	o := &otlpOptions{}
+	for i := range opts {
+		opts[i](o)
+	}
+	s := NewSink()
+	err := pprof.WalkJFR(body, pi, jfrLabels, s,
+		pprof.WithThreadNameLabel(true),
+		pprof.WithRecordingInfo(true),
+		pprof.WithDisablePanicRecovery(o.disablePanicRecovery),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return s.ProfilesData(), nil
+}
+
+// Sink is a pprof.Sink that builds OpenTelemetry profiles. Functions and locations are
+// shared by all profiles, the ids the sink returns are indices into the dictionary tables.
+type Sink struct {
+	dict     ProfilesDictionary
+	profiles []*profileBuilder
+	result   *ProfilesData
+
+	strings    map[string]int32
+	functions  map[string]int32
+	locations  map[locationKey]int32
+	attributes map[attributeKey]int32
+}
+
+type locationKey struct {
+	function int32
+	line     int64
+}
+
+type attributeKey struct {
+	key   string
+	value string
+}
+
+type profileBuilder struct {
+	profile Profile
+	// stacks maps the location indices of a stack, joined as a string, to its start in LocationIndices
+	stacks map[string]int32
+	// samples maps a pprof.Sample StackID to an index into Sample
+	samples map[uint64]int
+}
+
+const javaMapping = 1
+
+func NewSink() *Sink {
+	s := &Sink{
+		dict: ProfilesDictionary{
+			MappingTable:   []Mapping{{}},
+			LocationTable:  []Location{{}},
+			FunctionTable:  []Function{{}},
+			LinkTable:      []Link{{}},
+			StringTable:    []string{""},
+			AttributeTable: []KeyValue{{}},
+		},
+		strings:    map[string]int32{"": 0},
+		functions:  make(map[string]int32),
+		locations:  make(map[locationKey]int32),
+		attributes: make(map[attributeKey]int32),
+	}
+	// all frames are Java methods, resolved by the JVM
+	s.dict.MappingTable = append(s.dict.MappingTable, Mapping{
+		FilenameStrindex: s.str("java"),
+		HasFunctions:     true,
+		HasLineNumbers:   true,
+	})
+	return s
+}
+
+func (s *Sink) str(v string) int32 {
+	i, ok := s.strings[v]
+	if !ok {
+		i = int32(len(s.dict.StringTable))
+		s.strings[v] = i
+		s.dict.StringTable = append(s.dict.StringTable, v)
+	}
+	return i
+}
+
+func (s *Sink) valueType(t pprof.ValueType) ValueType {
+	return ValueType{
+		TypeStrindex:           s.str(t.Type),
+		UnitStrindex:           s.str(t.Unit),
+		AggregationTemporality: AggregationTemporalityDelta,
+	}
+}
+
+func (s *Sink) AddProfile(t *pprof.ProfileType) int {
+	pb := &profileBuilder{
+		profile: Profile{
+			TimeNanos:             t.TimeNanos,
+			DurationNanos:         t.DurationNanos,
+			OriginalPayloadFormat: "jfr",
+		},
+		stacks:  make(map[string]int32),
+		samples: make(map[uint64]int),
+	}
+	for _, st := range t.SampleTypes {
+		pb.profile.SampleType = append(pb.profile.SampleType, s.valueType(st))
+	}
+	if t.PeriodType != (pprof.ValueType{}) {
+		pb.profile.PeriodType = s.valueType(t.PeriodType)
+	}
+	s.profiles = append(s.profiles, pb)
+	return len(s.profiles) - 1
+}
+
+func (s *Sink) AddFunction(_ int, name string) uint64 {
+	fn, ok := s.functions[name]
+	if !ok {
+		fn = int32(len(s.dict.FunctionTable))
+		s.functions[name] = fn
+		nameIndex := s.str(name)
+		s.dict.FunctionTable = append(s.dict.FunctionTable, Function{NameStrindex: nameIndex, SystemNameStrindex: nameIndex})
+	}
+	return uint64(fn)
+}
+
+func (s *Sink) AddLocation(_ int, function uint64, line int64) uint64 {
+	k := locationKey{function: int32(function), line: line}
+	loc, ok := s.locations[k]
+	if !ok {
+		loc = int32(len(s.dict.LocationTable))
+		s.locations[k] = loc
+		s.dict.LocationTable = append(s.dict.LocationTable, Location{
+			MappingIndex: javaMapping,
+			Line:         []Line{{FunctionIndex: k.function, Line: line}},
+		})
+	}
+	return uint64(loc)
+}
+
+// Timestamps implements pprof.TimestampSink, samples keep the start time of every event.
+func (s *Sink) Timestamps() bool {
+	return true
+}
+
+func (s *Sink) AddSample(profile int, sample *pprof.Sample) {
+	pb := s.profiles[profile]
+	i, ok := pb.samples[sample.StackID]
+	if !ok {
+		stack := make([]int32, len(sample.Locations))
+		for j, loc := range sample.Locations {
+			stack[j] = int32(loc)
+		}
+		stackKey := string(int32sToBytes(stack))
+		start, ok := pb.stacks[stackKey]
+		if !ok {
+			start = int32(len(pb.profile.LocationIndices))
+			pb.stacks[stackKey] = start
+			pb.profile.LocationIndices = append(pb.profile.LocationIndices, stack...)
+		}
+		i = len(pb.profile.Sample)
+		pb.samples[sample.StackID] = i
+		pb.profile.Sample = append(pb.profile.Sample, Sample{
+			LocationsStartIndex: start,
+			LocationsLength:     int32(len(stack)),
+			Value:               make([]int64, len(pb.profile.SampleType)),
+			AttributeIndices:    s.sampleAttributes(sample.Labels),
+		})
+	}
+	ps := &pb.profile.Sample[i]
+	for j, v := range sample.Values {
+		ps.Value[j] += v
+	}
+	ps.TimestampsUnixNano = append(ps.TimestampsUnixNano, uint64(sample.TimestampNanos))
+}
+
+// sampleAttributes returns the sorted attribute indices of the labels of a sample: the
+// thread name, the span and the labels of the pyroscope context.
+func (s *Sink) sampleAttributes(labels []pprof.Label) []int32 {
+	if len(labels) == 0 {
+		return nil
+	}
+	res := make([]int32, 0, len(labels))
+	for _, l := range labels {
+		key := l.Key
+		switch key {
+		case pprof.LabelThreadName:
+			key = AttributeThreadName
+		case labelSpanID:
+			key = AttributeSpanID
+		}
+		res = append(res, s.attribute(key, l.Value))
+	}
+	slices.Sort(res)
+	return res
+}
+
+func (s *Sink) attribute(key, value string) int32 {
+	k := attributeKey{key: key, value: value}
+	i, ok := s.attributes[k]
+	if !ok {
+		i = int32(len(s.dict.AttributeTable))
+		s.attributes[k] = i
+		s.dict.AttributeTable = append(s.dict.AttributeTable, KeyValue{Key: key, Value: value})
+	}
+	return i
+}
+
+// Finish builds the profiles with the JVM information as resource attributes, see
+// pprof.WithRecordingInfo.
+func (s *Sink) Finish(summary *pprof.Summary) error {
+	scope := ScopeProfiles{Scope: InstrumentationScope{Name: ScopeName}}
+	for i, pb := range s.profiles {
+		if slices.Contains(summary.Discarded, i) {
+			continue
+		}
+		scope.Profiles = append(scope.Profiles, pb.profile)
+	}
+	var resource Resource
+	if info := summary.RecordingInfo; info != nil {
+		if info.JVM.PID != 0 {
+			resource.Attributes = append(resource.Attributes, KeyValue{Key: AttributeProcessPID, Value: int64(info.JVM.PID)})
+		}
+		for _, kv := range []KeyValue{
+			{Key: AttributeProcessRuntimeName, Value: info.JVM.Name},
+			{Key: AttributeProcessRuntimeVersion, Value: info.JVM.Version},
+			{Key: AttributeProcessCommandArgs, Value: info.JVM.Arguments},
+			{Key: AttributeHostCPUModelName, Value: info.CPU.CPU},
+		} {
+			if kv.Value != "" {
+				resource.Attributes = append(resource.Attributes, kv)
+			}
+		}
+	}
+	s.result = &ProfilesData{
+		ResourceProfiles: []ResourceProfiles{{Resource: resource, ScopeProfiles: []ScopeProfiles{scope}}},
+		Dictionary:       s.dict,
+	}
+	s.profiles = nil
+	return nil
+}
+
+// ProfilesData returns the profiles once the walk finished.
+func (s *Sink) ProfilesData() *ProfilesData {
+	return s.result
+}
+
+func int32sToBytes(vs []int32) []byte {
+	res := make([]byte, 0, 4*len(vs))
+	for _, v := range vs {
+		res = binary.LittleEndian.AppendUint32(res, uint32(v))
+	}
+	return res
+}
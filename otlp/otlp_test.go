@@ -0,0 +1,253 @@
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/grafana/jfr-parser/internal/testutil"
+	"github.com/grafana/jfr-parser/pprof"
+)
+
+func TestParseJFR(t *testing.T) {
+	body := testutil.ReadGzipFile(t, "dump2.jfr.gz")
+	labels := new(pprof.LabelsSnapshot)
+	require.NoError(t, labels.UnmarshalVT(testutil.ReadGzipFile(t, "dump2.labels.pb.gz")))
+	pi := &pprof.ParseInput{StartTime: time.Unix(1706241880, 0), EndTime: time.Unix(1706241890, 0), SampleRate: 100}
+
+	expected, err := pprof.ParseJFR(body, pi, labels)
+	require.NoError(t, err)
+	expectedTotals := map[string]int64{}
+	for _, p := range expected.Profiles {
+		for i, st := range p.Profile.SampleType {
+			for _, s := range p.Profile.Sample {
+				expectedTotals[p.Profile.StringTable[st.Type]] += s.Value[i]
+			}
+		}
+	}
+
+	d, err := ParseJFR(body, pi, labels)
+	require.NoError(t, err)
+	dict := d.Dictionary
+	assert.Equal(t, "", dict.StringTable[0])
+	require.Len(t, d.ResourceProfiles, 1)
+	assert.Contains(t, d.ResourceProfiles[0].Resource.Attributes, KeyValue{Key: AttributeProcessPID, Value: int64(22592)})
+
+	profiles := d.ResourceProfiles[0].ScopeProfiles[0].Profiles
+	require.Len(t, profiles, len(expected.Profiles))
+	totals := map[string]int64{}
+	for _, p := range profiles {
+		assert.Equal(t, pi.StartTime.UnixNano(), p.TimeNanos)
+		for _, s := range p.Sample {
+			require.LessOrEqual(t, int(s.LocationsStartIndex+s.LocationsLength), len(p.LocationIndices))
+			for _, a := range s.AttributeIndices {
+				require.Less(t, int(a), len(dict.AttributeTable))
+			}
+			assert.Zero(t, s.LinkIndex)
+			assert.NotEmpty(t, s.TimestampsUnixNano)
+			for i, st := range p.SampleType {
+				totals[dict.StringTable[st.TypeStrindex]] += s.Value[i]
+			}
+		}
+		for _, loc := range p.LocationIndices {
+			l := dict.LocationTable[loc]
+			assert.Equal(t, int32(javaMapping), l.MappingIndex)
+			assert.NotEmpty(t, dict.StringTable[dict.FunctionTable[l.Line[0].FunctionIndex].NameStrindex])
+		}
+	}
+	// sleeping jdk.ExecutionSample events are not CPU samples, as in pprof
+	assert.NotZero(t, totals["cpu"])
+	assert.Equal(t, expectedTotals, totals)
+	assert.Len(t, dict.LinkTable, 1)
+
+	keys := map[string]bool{}
+	for _, a := range dict.AttributeTable[1:] {
+		keys[a.Key] = true
+	}
+	// the pyroscope contexts of the recording hold profile_id and thread_name labels
+	assert.Equal(t, map[string]bool{AttributeThreadName: true, AttributeSpanID: true}, keys)
+
+	// check the encoding against the field numbers of the profiles.proto messages
+	b, err := d.Marshal()
+	require.NoError(t, err)
+	decoded := decode(t, b)
+	dictMsg := decoded.message(t, 2)
+	assert.Equal(t, dict.StringTable, dictMsg.strings(5))
+	assert.Len(t, dictMsg[2], len(dict.LocationTable))
+	assert.Len(t, dictMsg[6], len(dict.AttributeTable))
+	decodedProfiles := decoded.message(t, 1).message(t, 2).messages(t, 2)
+	require.Len(t, decodedProfiles, len(profiles))
+	for i, p := range decodedProfiles {
+		samples := p.messages(t, 2)
+		require.Len(t, samples, len(profiles[i].Sample))
+		for j, s := range samples {
+			values := make([]int64, 0, len(profiles[i].Sample[j].Value))
+			for _, v := range s.packed(t, 3) {
+				values = append(values, int64(v))
+			}
+			assert.Equal(t, profiles[i].Sample[j].Value, values)
+			assert.Equal(t, profiles[i].Sample[j].TimestampsUnixNano, s.packed(t, 6))
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	d := &ProfilesData{
+		ResourceProfiles: []ResourceProfiles{{
+			Resource: Resource{Attributes: []KeyValue{{Key: "process.pid", Value: int64(42)}}},
+			ScopeProfiles: []ScopeProfiles{{
+				Scope: InstrumentationScope{Name: ScopeName},
+				Profiles: []Profile{{
+					SampleType: []ValueType{{TypeStrindex: 1, UnitStrindex: 2, AggregationTemporality: AggregationTemporalityDelta}},
+					Sample: []Sample{{
+						LocationsLength:    1,
+						Value:              []int64{3},
+						AttributeIndices:   []int32{1},
+						LinkIndex:          1,
+						TimestampsUnixNano: []uint64{1, 2, 3},
+					}},
+					LocationIndices: []int32{1},
+					TimeNanos:       100,
+				}},
+			}},
+		}},
+		Dictionary: ProfilesDictionary{
+			MappingTable:   []Mapping{{}, {FilenameStrindex: 3, HasFunctions: true}},
+			LocationTable:  []Location{{}, {MappingIndex: 1, Line: []Line{{FunctionIndex: 1, Line: 7}}}},
+			FunctionTable:  []Function{{}, {NameStrindex: 4, SystemNameStrindex: 4}},
+			LinkTable:      []Link{{}, {TraceID: make([]byte, 16), SpanID: []byte{0, 0, 0, 0, 0, 0, 0, 9}}},
+			StringTable:    []string{"", "cpu", "nanoseconds", "java", "App.main"},
+			AttributeTable: []KeyValue{{}, {Key: AttributeThreadName, Value: "main"}},
+		},
+	}
+	b, err := d.Marshal()
+	require.NoError(t, err)
+
+	decoded := decode(t, b)
+	// ProfilesData.dictionary
+	dict := decoded.message(t, 2)
+	assert.Equal(t, d.Dictionary.StringTable, dict.strings(5))
+	// mapping_table, filename_strindex and has_functions
+	mappings := dict.messages(t, 1)
+	require.Len(t, mappings, 2)
+	assert.Equal(t, uint64(3), mappings[1].varint(t, 4))
+	assert.Equal(t, uint64(1), mappings[1].varint(t, 6))
+	// location_table, mapping_index and line
+	locations := dict.messages(t, 2)
+	require.Len(t, locations, 2)
+	assert.Equal(t, uint64(1), locations[1].varint(t, 1))
+	assert.Equal(t, uint64(7), locations[1].message(t, 3).varint(t, 2))
+	// function_table, name_strindex
+	functions := dict.messages(t, 3)
+	require.Len(t, functions, 2)
+	assert.Equal(t, uint64(4), functions[1].varint(t, 1))
+	// link_table, span_id
+	links := dict.messages(t, 4)
+	require.Len(t, links, 2)
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 9}, links[1].bytes(t, 2))
+	// attribute_table, key and the string_value of the AnyValue
+	attributes := dict.messages(t, 6)
+	require.Len(t, attributes, 2)
+	assert.Equal(t, []string{AttributeThreadName}, attributes[1].strings(1))
+	assert.Equal(t, []string{"main"}, attributes[1].message(t, 2).strings(1))
+
+	// ProfilesData.resource_profiles, the int_value of the process.pid attribute
+	resources := decoded.messages(t, 1)
+	require.Len(t, resources, 1)
+	r := resources[0]
+	assert.Equal(t, uint64(42), r.message(t, 1).message(t, 1).message(t, 2).varint(t, 3))
+	// scope_profiles, the scope name
+	scope := r.message(t, 2)
+	assert.Equal(t, []string{ScopeName}, scope.message(t, 1).strings(1))
+	p := scope.message(t, 2)
+	// Profile.time_nanos, location_indices and sample_type.aggregation_temporality
+	assert.Equal(t, uint64(100), p.varint(t, 4))
+	assert.Equal(t, []uint64{1}, p.packed(t, 3))
+	assert.Equal(t, uint64(AggregationTemporalityDelta), p.message(t, 1).varint(t, 3))
+	// Sample.locations_length, value, attribute_indices, link_index and timestamps_unix_nano
+	s := p.message(t, 2)
+	assert.Equal(t, uint64(1), s.varint(t, 2))
+	assert.Equal(t, []uint64{3}, s.packed(t, 3))
+	assert.Equal(t, []uint64{1}, s.packed(t, 4))
+	assert.Equal(t, uint64(1), s.varint(t, 5))
+	assert.Equal(t, []uint64{1, 2, 3}, s.packed(t, 6))
+}
+
+// field is a decoded protobuf field, varint values are in v and length-delimited values in b.
+type field struct {
+	v uint64
+	b []byte
+}
+
+// message holds the fields of a decoded protobuf message by field number.
+type message map[protowire.Number][]field
+
+func decode(t *testing.T, b []byte) message {
+	m := message{}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+		var f field
+		switch typ {
+		case protowire.VarintType:
+			f.v, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			f.b, n = protowire.ConsumeBytes(b)
+		default:
+			t.Fatalf("unexpected wire type %d of field %d", typ, num)
+		}
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+		m[num] = append(m[num], f)
+	}
+	return m
+}
+
+func (m message) messages(t *testing.T, num protowire.Number) []message {
+	res := make([]message, 0, len(m[num]))
+	for _, f := range m[num] {
+		res = append(res, decode(t, f.b))
+	}
+	return res
+}
+
+// message returns the single message of field num.
+func (m message) message(t *testing.T, num protowire.Number) message {
+	require.Len(t, m[num], 1, "field %d", num)
+	return decode(t, m[num][0].b)
+}
+
+func (m message) strings(num protowire.Number) []string {
+	var res []string
+	for _, f := range m[num] {
+		res = append(res, string(f.b))
+	}
+	return res
+}
+
+func (m message) bytes(t *testing.T, num protowire.Number) []byte {
+	require.Len(t, m[num], 1, "field %d", num)
+	return m[num][0].b
+}
+
+func (m message) varint(t *testing.T, num protowire.Number) uint64 {
+	require.Len(t, m[num], 1, "field %d", num)
+	return m[num][0].v
+}
+
+// packed returns the values of a packed repeated varint field.
+func (m message) packed(t *testing.T, num protowire.Number) []uint64 {
+	b := m.bytes(t, num)
+	var res []uint64
+	for len(b) > 0 {
+		v, n := protowire.ConsumeVarint(b)
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+		res = append(res, v)
+	}
+	return res
+}
@@ -8,6 +8,9 @@ import (
 )
 
 func (p *Parser) readConstantPool(pos int) error {
+	if p.options.IndexConstantPools {
+		p.constants = make(map[def.TypeID]map[uint64]int)
+	}
 	for {
 		if err := p.seek(pos); err != nil {
 			return err
@@ -76,6 +79,11 @@ func (p *Parser) readConstantPool(pos int) error {
 }
 
 func (p *Parser) readConstants(c *def.Class) error {
+	if p.constants != nil && c.Name != "jdk.types.ChunkHeader" {
+		if err := p.indexConstants(c); err != nil {
+			return err
+		}
+	}
 	switch c.Name {
 	case "jdk.types.ChunkHeader":
 		p.pos += chunkHeaderSize
@@ -123,6 +131,42 @@ func (p *Parser) readConstants(c *def.Class) error {
 		o, err := p.Strings.Parse(p.buf[p.pos:], p.bindString, &p.TypeMap)
 		p.pos += o
 		return err
+	case "jdk.types.GCName":
+		o, err := p.GCNames.Parse(p.buf[p.pos:], p.bindGCName, &p.TypeMap)
+		p.pos += o
+		return err
+	case "jdk.types.GCCause":
+		o, err := p.GCCauses.Parse(p.buf[p.pos:], p.bindGCCause, &p.TypeMap)
+		p.pos += o
+		return err
+	case "jdk.types.GCWhen":
+		o, err := p.GCWhens.Parse(p.buf[p.pos:], p.bindGCWhen, &p.TypeMap)
+		p.pos += o
+		return err
+	case "jdk.types.ThreadGroup":
+		o, err := p.ThreadGroups.Parse(p.buf[p.pos:], p.bindThreadGroup, &p.TypeMap)
+		p.pos += o
+		return err
+	case "jdk.types.CompilerType":
+		o, err := p.CompilerTypes.Parse(p.buf[p.pos:], p.bindCompilerType, &p.TypeMap)
+		p.pos += o
+		return err
+	case "jdk.types.DeoptimizationReason":
+		o, err := p.DeoptimizationReasons.Parse(p.buf[p.pos:], p.bindDeoptimizationReason, &p.TypeMap)
+		p.pos += o
+		return err
+	case "jdk.types.DeoptimizationAction":
+		o, err := p.DeoptimizationActions.Parse(p.buf[p.pos:], p.bindDeoptimizationAction, &p.TypeMap)
+		p.pos += o
+		return err
+	case "jdk.types.Bytecode":
+		o, err := p.Bytecodes.Parse(p.buf[p.pos:], p.bindBytecode, &p.TypeMap)
+		p.pos += o
+		return err
+	case "jdk.types.VMOperationType":
+		o, err := p.VMOperationTypes.Parse(p.buf[p.pos:], p.bindVMOperationType, &p.TypeMap)
+		p.pos += o
+		return err
 	default:
 		b := types.NewBindSkipConstantPool(c, &p.TypeMap)
 		skipper := types.SkipConstantPoolList{}
@@ -0,0 +1,317 @@
+package parser
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/grafana/jfr-parser/parser/types"
+	"github.com/grafana/jfr-parser/parser/types/def"
+)
+
+const maxDecodeDepth = 32
+
+// Object is an instance of a metadata class decoded without generated bindings.
+// Values are parallel to Class.Fields and hold one of: bool, int8, int16, int32,
+// int64, float32, float64, string, *Object, ConstantRef, []any or nil.
+type Object struct {
+	Class  *def.Class
+	Values []any
+}
+
+// Get returns the value of the named field, or nil if the class has no such field.
+func (o *Object) Get(name string) any {
+	for i := range o.Class.Fields {
+		if o.Class.Fields[i].Name == name {
+			return o.Values[i]
+		}
+	}
+	return nil
+}
+
+// ConstantRef is an unresolved reference into a constant pool of the current chunk.
+type ConstantRef struct {
+	Type def.TypeID
+	ID   uint64
+}
+
+// DecodeEvent decodes all fields of the event most recently returned by
+// ParseEvent or NextEvent using the chunk metadata.
+func (p *Parser) DecodeEvent() (*Object, error) {
+	c := p.TypeMap.IDMap[p.eventType]
+	if c == nil {
+		return nil, fmt.Errorf("unknown event type %d", p.eventType)
+	}
+	d := decoder{p: p, buf: p.buf[:p.eventEnd], pos: p.eventPos}
+	return d.object(c, 0)
+}
+
+// Constant decodes the constant pool entry ref points to. It requires
+// Options.IndexConstantPools and returns false if the entry does not exist.
+func (p *Parser) Constant(ref ConstantRef) (any, bool, error) {
+	pool := p.constants[ref.Type]
+	if pool == nil {
+		return nil, false, nil
+	}
+	pos, ok := pool[ref.ID]
+	if !ok {
+		return nil, false, nil
+	}
+	c := p.TypeMap.IDMap[ref.Type]
+	if c == nil {
+		return nil, false, fmt.Errorf("unknown type %d", ref.Type)
+	}
+	d := decoder{p: p, buf: p.buf[:p.chunkEnd], pos: pos}
+	v, err := d.value(&def.Field{Type: ref.Type}, true, 0)
+	if err != nil {
+		return nil, false, err
+	}
+	return v, true, nil
+}
+
+// indexConstants records the position of every entry of a constant pool of type c.
+func (p *Parser) indexConstants(c *def.Class) error {
+	d := decoder{p: p, buf: p.buf[:p.chunkEnd], pos: p.pos}
+	n, err := d.varInt()
+	if err != nil {
+		return err
+	}
+	pool := p.constants[c.ID]
+	if pool == nil {
+		pool = make(map[uint64]int, n)
+		p.constants[c.ID] = pool
+	}
+	f := &def.Field{Type: c.ID}
+	for i := 0; i < int(n); i++ {
+		id, err := d.varLong()
+		if err != nil {
+			return err
+		}
+		pool[id] = d.pos
+		if _, err = d.value(f, false, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type decoder struct {
+	p   *Parser
+	buf []byte
+	pos int
+}
+
+func (d *decoder) object(c *def.Class, depth int) (*Object, error) {
+	if depth > maxDecodeDepth {
+		return nil, fmt.Errorf("max decode depth exceeded at %s", c.Name)
+	}
+	o := &Object{Class: c, Values: make([]any, len(c.Fields))}
+	for i := range c.Fields {
+		v, err := d.value(&c.Fields[i], true, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		o.Values[i] = v
+	}
+	return o, nil
+}
+
+// value reads a single field. When keep is false the value is skipped and nil is returned.
+func (d *decoder) value(f *def.Field, keep bool, depth int) (any, error) {
+	if f.Array {
+		n, err := d.varInt()
+		if err != nil {
+			return nil, err
+		}
+		if int(n) > len(d.buf)-d.pos {
+			return nil, io.ErrUnexpectedEOF
+		}
+		elem := *f
+		elem.Array = false
+		var arr []any
+		if keep {
+			arr = make([]any, 0, n)
+		}
+		for i := 0; i < int(n); i++ {
+			v, err := d.value(&elem, keep, depth)
+			if err != nil {
+				return nil, err
+			}
+			if keep {
+				arr = append(arr, v)
+			}
+		}
+		return arr, nil
+	}
+	if f.ConstantPool {
+		id, err := d.varLong()
+		if err != nil {
+			return nil, err
+		}
+		if !keep {
+			return nil, nil
+		}
+		return ConstantRef{Type: f.Type, ID: id}, nil
+	}
+	tm := &d.p.TypeMap
+	switch f.Type {
+	case tm.T_BOOLEAN:
+		b, err := d.byte()
+		return b != 0, err
+	case tm.T_BYTE:
+		b, err := d.byte()
+		return int8(b), err
+	case tm.T_CHAR:
+		v, err := d.varInt()
+		return string(rune(v)), err
+	case tm.T_SHORT:
+		v, err := d.varInt()
+		return int16(v), err
+	case tm.T_INT:
+		v, err := d.varInt()
+		return int32(v), err
+	case tm.T_LONG:
+		v, err := d.varLong()
+		return int64(v), err
+	case tm.T_FLOAT:
+		if d.pos+4 > len(d.buf) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		v := math.Float32frombits(binary.BigEndian.Uint32(d.buf[d.pos:]))
+		d.pos += 4
+		return v, nil
+	case tm.T_DOUBLE:
+		if d.pos+8 > len(d.buf) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		v := math.Float64frombits(binary.BigEndian.Uint64(d.buf[d.pos:]))
+		d.pos += 8
+		return v, nil
+	case tm.T_STRING:
+		return d.string(keep)
+	}
+	c := tm.IDMap[f.Type]
+	if c == nil {
+		return nil, fmt.Errorf("unknown type %d", f.Type)
+	}
+	if !keep {
+		if depth > maxDecodeDepth {
+			return nil, fmt.Errorf("max decode depth exceeded at %s", c.Name)
+		}
+		for i := range c.Fields {
+			if _, err := d.value(&c.Fields[i], false, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}
+	return d.object(c, depth)
+}
+
+func (d *decoder) byte() (byte, error) {
+	if d.pos >= len(d.buf) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	b := d.buf[d.pos]
+	d.pos++
+	return b, nil
+}
+
+func (d *decoder) varInt() (uint32, error) {
+	v := uint32(0)
+	for shift := uint(0); ; shift += 7 {
+		if shift >= 35 {
+			return 0, def.ErrIntOverflow
+		}
+		b, err := d.byte()
+		if err != nil {
+			return 0, err
+		}
+		v |= uint32(b&0x7F) << shift
+		if b < 0x80 {
+			return v, nil
+		}
+	}
+}
+
+func (d *decoder) varLong() (uint64, error) {
+	v := uint64(0)
+	for shift := uint(0); shift <= 56; shift += 7 {
+		b, err := d.byte()
+		if err != nil {
+			return 0, err
+		}
+		if shift == 56 {
+			v |= uint64(b) << shift
+			break
+		}
+		v |= uint64(b&0x7F) << shift
+		if b < 0x80 {
+			break
+		}
+	}
+	return v, nil
+}
+
+func (d *decoder) string(keep bool) (any, error) {
+	enc, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	switch enc {
+	case 0:
+		return nil, nil
+	case 1:
+		return "", nil
+	case 2:
+		id, err := d.varLong()
+		if err != nil || !keep {
+			return nil, err
+		}
+		idx, ok := d.p.Strings.IDMap[types.StringRef(id)]
+		if !ok {
+			return nil, nil
+		}
+		return d.p.Strings.String[idx].String, nil
+	case 3, 5:
+		n, err := d.varInt()
+		if err != nil {
+			return nil, err
+		}
+		if int(n) > len(d.buf)-d.pos {
+			return nil, io.ErrUnexpectedEOF
+		}
+		bs := d.buf[d.pos : d.pos+int(n)]
+		d.pos += int(n)
+		if !keep {
+			return nil, nil
+		}
+		if enc == 5 {
+			bs, _ = d.p.TypeMap.ISO8859_1Decoder.Bytes(bs)
+		}
+		return string(bs), nil
+	case 4:
+		n, err := d.varInt()
+		if err != nil {
+			return nil, err
+		}
+		if int(n) > len(d.buf)-d.pos {
+			return nil, io.ErrUnexpectedEOF
+		}
+		runes := make([]rune, 0, n)
+		for i := 0; i < int(n); i++ {
+			c, err := d.varInt()
+			if err != nil {
+				return nil, err
+			}
+			runes = append(runes, rune(c))
+		}
+		if !keep {
+			return nil, nil
+		}
+		return string(runes), nil
+	default:
+		return nil, fmt.Errorf("unknown string type %d at %d", enc, d.pos)
+	}
+}
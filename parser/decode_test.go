@@ -0,0 +1,230 @@
+package parser
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	types2 "github.com/grafana/jfr-parser/parser/types"
+)
+
+func readGzipFile(t testing.TB, fname string) []byte {
+	f, err := os.Open(fname)
+	require.NoError(t, err)
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	defer r.Close()
+	bs, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return bs
+}
+
+func TestDecodeEventMatchesBindings(t *testing.T) {
+	buf := readGzipFile(t, "testdata/dd-trace-java.jfr.gz")
+	p := NewParser(buf, Options{IndexConstantPools: true})
+	n := 0
+	for {
+		typ, err := p.ParseEvent()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if typ != p.TypeMap.T_EXECUTION_SAMPLE {
+			continue
+		}
+		n++
+		o, err := p.DecodeEvent()
+		require.NoError(t, err)
+		assert.Equal(t, "jdk.ExecutionSample", o.Class.Name)
+		assert.Equal(t, int64(p.ExecutionSample.StartTime), o.Get("startTime"))
+
+		ref, ok := o.Get("stackTrace").(ConstantRef)
+		require.True(t, ok)
+		assert.Equal(t, uint64(p.ExecutionSample.StackTrace), ref.ID)
+		st, ok, err := p.Constant(ref)
+		require.NoError(t, err)
+		require.True(t, ok)
+		frames := st.(*Object).Get("frames").([]any)
+		expected := p.GetStacktrace(p.ExecutionSample.StackTrace)
+		require.Len(t, frames, len(expected.Frames))
+		for i, f := range frames {
+			frame := f.(*Object)
+			assert.Equal(t, int32(expected.Frames[i].LineNumber), frame.Get("lineNumber"))
+			assert.Equal(t, uint64(expected.Frames[i].Method), frame.Get("method").(ConstantRef).ID)
+		}
+	}
+	assert.NotZero(t, n)
+}
+
+func TestNextEventDecodesAllEvents(t *testing.T) {
+	buf := readGzipFile(t, "testdata/object-allocation-sample.jfr.gz")
+	p := NewParser(buf, Options{IndexConstantPools: true})
+	counts := map[string]int{}
+	for {
+		_, err := p.NextEvent()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		o, err := p.DecodeEvent()
+		require.NoError(t, err)
+		counts[o.Class.Name]++
+		if o.Class.Name == "jdk.JVMInformation" {
+			assert.Contains(t, o.Get("jvmName"), "VM")
+		}
+		if o.Class.Name == "jdk.CPULoad" {
+			load := o.Get("machineTotal").(float32)
+			assert.True(t, load >= 0 && load <= 1, load)
+		}
+	}
+	assert.Equal(t, 1, counts["jdk.JVMInformation"])
+	assert.NotZero(t, counts["jdk.CPULoad"])
+	assert.NotZero(t, counts["jdk.GarbageCollection"])
+}
+
+func TestConstantRequiresIndex(t *testing.T) {
+	buf := readGzipFile(t, "testdata/example.jfr.gz")
+	p := NewParser(buf, Options{})
+	_, err := p.NextEvent()
+	require.NoError(t, err)
+	_, ok, err := p.Constant(ConstantRef{Type: p.TypeMap.T_THREAD, ID: 1})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestEventSizesAndConstantPoolSizes(t *testing.T) {
+	buf := readGzipFile(t, "testdata/object-allocation-sample.jfr.gz")
+	p := NewParser(buf, Options{IndexConstantPools: true})
+	total := 0
+	for {
+		_, err := p.NextEvent()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		assert.Positive(t, p.EventSize())
+		total += p.EventSize()
+	}
+	assert.Less(t, total, len(buf))
+	sizes := p.ConstantPoolSizes()
+	assert.Equal(t, len(p.Symbols.Symbol), sizes[p.TypeMap.T_SYMBOL])
+	assert.Equal(t, len(p.Stacktrace.StackTrace), sizes[p.TypeMap.T_STACK_TRACE])
+}
+
+func TestFloatBindingsMatchDecode(t *testing.T) {
+	buf := readGzipFile(t, "testdata/object-allocation-sample.jfr.gz")
+	p := NewParser(buf, Options{})
+	n := 0
+	for {
+		typ, err := p.ParseEvent()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if typ != p.TypeMap.T_CPU_LOAD {
+			continue
+		}
+		n++
+		o, err := p.DecodeEvent()
+		require.NoError(t, err)
+		assert.Equal(t, p.CPULoad.JvmUser, o.Get("jvmUser"))
+		assert.Equal(t, p.CPULoad.JvmSystem, o.Get("jvmSystem"))
+		assert.Equal(t, p.CPULoad.MachineTotal, o.Get("machineTotal"))
+	}
+	assert.NotZero(t, n)
+}
+
+func TestThreadGroups(t *testing.T) {
+	buf := readGzipFile(t, "testdata/uint64-ref-id.jfr.gz")
+	p := NewParser(buf, Options{})
+	for {
+		_, err := p.ParseEvent()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+	groups := map[string]int{}
+	parents := map[string]string{}
+	for _, th := range p.Threads.Thread {
+		assert.False(t, th.Virtual)
+		g := p.GetThreadGroup(th.Group)
+		if g == nil {
+			continue
+		}
+		groups[g.Name]++
+		if parent := p.GetThreadGroup(g.Parent); parent != nil {
+			parents[g.Name] = parent.Name
+		}
+	}
+	assert.Equal(t, 5, groups["main"])
+	assert.Equal(t, 9, groups["system"])
+	assert.Equal(t, "system", parents["main"])
+}
+
+func TestVirtualThreadEvents(t *testing.T) {
+	buf := readGzipFile(t, "testdata/virtual-threads.jfr.gz")
+	p := NewParser(buf, Options{})
+	var started, ended []uint64
+	for {
+		typ, err := p.ParseEvent()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		switch typ {
+		case p.TypeMap.T_VIRTUAL_THREAD_START:
+			started = append(started, p.VirtualThreadStart.JavaThreadId)
+		case p.TypeMap.T_VIRTUAL_THREAD_END:
+			ended = append(ended, p.VirtualThreadEnd.JavaThreadId)
+		}
+	}
+	assert.Equal(t, []uint64{40, 41}, started)
+	assert.Equal(t, []uint64{40, 41}, ended)
+	for _, th := range p.Threads.Thread {
+		assert.Equal(t, th.JavaThreadId == 40 || th.JavaThreadId == 41, th.Virtual, th.JavaName)
+	}
+}
+
+func TestThreadKeyOf(t *testing.T) {
+	java := ThreadKeyOf(&types2.Thread{JavaThreadId: 7, OsThreadId: 1234})
+	native := ThreadKeyOf(&types2.Thread{OsThreadId: 7})
+	assert.Equal(t, ThreadKey{ID: 7, Java: true}, java)
+	assert.Equal(t, ThreadKey{ID: 7}, native)
+	assert.NotEqual(t, java, native)
+	assert.Equal(t, -1, CompareThreadKeys(java, native))
+	assert.Equal(t, 1, CompareThreadKeys(ThreadKey{ID: 8}, java))
+	assert.Equal(t, 0, CompareThreadKeys(native, native))
+}
+
+func TestStackCachePerChunk(t *testing.T) {
+	// both recordings use the same stack trace refs for different stacks
+	buf := append(readGzipFile(t, "testdata/cortex-dev-01__kafka-0__cpu_lock0_alloc0__0.jfr.gz"),
+		readGzipFile(t, "testdata/cortex-dev-01__kafka-0__cpu__0.jfr.gz")...)
+	p := NewParser(buf, Options{})
+	cache := NewStackCache[[]types2.StackFrame](p)
+	resolved := 0
+	for {
+		typ, err := p.ParseEvent()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if typ != p.TypeMap.T_EXECUTION_SAMPLE {
+			continue
+		}
+		ref := p.ExecutionSample.StackTrace
+		frames, ok := cache.Get(ref, func(st *types2.StackTrace) []types2.StackFrame {
+			resolved++
+			return st.Frames
+		})
+		require.True(t, ok)
+		require.Equal(t, p.GetStacktrace(ref).Frames, frames)
+	}
+	assert.Positive(t, resolved)
+}
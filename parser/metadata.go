@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/grafana/jfr-parser/parser/types/def"
 	"golang.org/x/text/encoding/charmap"
@@ -11,6 +12,11 @@ func (p *Parser) readMeta(pos int) error {
 	p.TypeMap.IDMap = make(map[def.TypeID]*def.Class, 43+5)
 	p.TypeMap.NameMap = make(map[string]*def.Class, 43+5)
 	p.TypeMap.ISO8859_1Decoder = charmap.ISO8859_1.NewDecoder()
+	p.TypeMap.StringConstant = p.getString
+	// the classes of the previous chunk may still be referenced, start new backing arrays
+	p.metaAnnotations = nil
+	p.metaValues = nil
+	clear(p.units)
 
 	if err := p.seek(pos); err != nil {
 		return err
@@ -56,60 +62,178 @@ func (p *Parser) readMeta(pos int) error {
 		return fmt.Errorf("expected root element, got %s", e.name)
 	}
 	for i := 0; i < e.childCount; i++ {
-		meta, err := p.readElement(strings, false)
+		meta, err := p.readElement(strings, true)
 		if err != nil {
 			return err
 		}
-		//fmt.Println(meta.name)
 		switch meta.name {
 		case "metadata":
 			for j := 0; j < meta.childCount; j++ {
-				classElement, err := p.readElement(strings, true)
-
+				cls, err := p.readClass(strings)
 				if err != nil {
 					return err
 				}
-				cls, err := def.NewClass(classElement.attr, classElement.childCount)
-				if err != nil {
-					return err
-				}
-
-				for k := 0; k < classElement.childCount; k++ {
-					field, err := p.readElement(strings, true)
-					if err != nil {
-						return err
-					}
-					if field.name == "field" {
-						f, err := def.NewField(field.attr)
-						if err != nil {
-							return err
-						}
-						cls.Fields = append(cls.Fields, f)
-					}
-					for l := 0; l < field.childCount; l++ {
-						_, err := p.readElement(strings, false)
-						if err != nil {
-							return err
-						}
-					}
-
-				}
 				p.TypeMap.IDMap[cls.ID] = cls
 				p.TypeMap.NameMap[cls.Name] = cls
-
 			}
 		case "region":
-			break
+			p.TypeMap.Region = def.Region{Locale: meta.attr["locale"]}
+			if offset := meta.attr["gmtOffset"]; offset != "" {
+				p.TypeMap.Region.GMTOffset, err = strconv.ParseInt(offset, 10, 64)
+				if err != nil {
+					return err
+				}
+			}
+			if err := p.skipElements(strings, meta.childCount); err != nil {
+				return err
+			}
 		default:
 			return fmt.Errorf("unexpected element %s", meta.name)
 		}
 	}
+	p.resolveAnnotations()
 	if err := p.checkTypes(); err != nil {
 		return err
 	}
 	return nil
 }
 
+func (p *Parser) readClass(strings []string) (*def.Class, error) {
+	classElement, err := p.readElement(strings, true)
+	if err != nil {
+		return nil, err
+	}
+	cls, err := def.NewClass(classElement.attr, classElement.childCount)
+	if err != nil {
+		return nil, err
+	}
+	// the annotations of annotation types tell whether they are content types
+	readClassAnnotations := p.options.ReadAnnotations || cls.SuperType == "java.lang.annotation.Annotation"
+	for k := 0; k < classElement.childCount; k++ {
+		child, err := p.readElement(strings, true)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case child.name == "field":
+			f, err := def.NewField(child.attr)
+			if err != nil {
+				return nil, err
+			}
+			if f.Annotations, err = p.readAnnotations(strings, child.childCount); err != nil {
+				return nil, err
+			}
+			cls.Fields = append(cls.Fields, f)
+		case child.name == "setting" && p.options.ReadAnnotations:
+			s, err := def.NewSetting(child.attr)
+			if err != nil {
+				return nil, err
+			}
+			if s.Annotations, err = p.readAnnotations(strings, child.childCount); err != nil {
+				return nil, err
+			}
+			cls.Settings = append(cls.Settings, s)
+		case child.name == "annotation" && readClassAnnotations:
+			var a def.Annotation
+			if a, p.metaValues, err = def.AppendAnnotation(p.metaValues, child.attr); err != nil {
+				return nil, err
+			}
+			cls.Annotations = append(cls.Annotations, a)
+			if err := p.skipElements(strings, child.childCount); err != nil {
+				return nil, err
+			}
+		default:
+			if err := p.skipElements(strings, child.childCount); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return cls, nil
+}
+
+// readAnnotations reads the annotations among the next n elements.
+func (p *Parser) readAnnotations(strings []string, n int) (def.Annotations, error) {
+	start := len(p.metaAnnotations)
+	for i := 0; i < n; i++ {
+		e, err := p.readElement(strings, true)
+		if err != nil {
+			return nil, err
+		}
+		if e.name == "annotation" {
+			var a def.Annotation
+			if a, p.metaValues, err = def.AppendAnnotation(p.metaValues, e.attr); err != nil {
+				return nil, err
+			}
+			p.metaAnnotations = append(p.metaAnnotations, a)
+		}
+		if err := p.skipElements(strings, e.childCount); err != nil {
+			return nil, err
+		}
+	}
+	return p.annotations(start), nil
+}
+
+// annotations returns the annotations appended since start, nil if there are none.
+func (p *Parser) annotations(start int) def.Annotations {
+	end := len(p.metaAnnotations)
+	if end == start {
+		return nil
+	}
+	return p.metaAnnotations[start:end:end]
+}
+
+func (p *Parser) skipElements(strings []string, n int) error {
+	for i := 0; i < n; i++ {
+		e, err := p.readElement(strings, false)
+		if err != nil {
+			return err
+		}
+		if err := p.skipElements(strings, e.childCount); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveAnnotations fills in annotation names once all classes of the chunk are known,
+// as annotations may refer to classes declared later in the metadata.
+func (p *Parser) resolveAnnotations() {
+	p.eachAnnotations(func(as def.Annotations) {
+		for i := range as {
+			if c := p.TypeMap.IDMap[as[i].Type]; c != nil {
+				as[i].Name = c.Name
+			}
+		}
+	})
+	p.eachAnnotations(func(as def.Annotations) {
+		for i := range as {
+			c := p.TypeMap.IDMap[as[i].Type]
+			if c == nil {
+				continue
+			}
+			// async-profiler does not annotate the jdk.jfr content types with @ContentType
+			as[i].ContentType = c.Annotations.Get(def.AnnotationContentType) != nil || def.IsContentType(c.Name)
+		}
+	})
+	for _, c := range p.TypeMap.IDMap {
+		for i := range c.Fields {
+			c.Fields[i].Unit = def.NewUnit(c.Fields[i].Annotations)
+		}
+	}
+}
+
+func (p *Parser) eachAnnotations(fn func(as def.Annotations)) {
+	for _, c := range p.TypeMap.IDMap {
+		fn(c.Annotations)
+		for i := range c.Fields {
+			fn(c.Fields[i].Annotations)
+		}
+		for i := range c.Settings {
+			fn(c.Settings[i].Annotations)
+		}
+	}
+}
+
 func (p *Parser) readElement(strings []string, needAttributes bool) (element, error) {
 	iname, err := p.varInt()
 	if err != nil {
@@ -125,7 +249,11 @@ func (p *Parser) readElement(strings []string, needAttributes bool) (element, er
 	}
 	var attributes map[string]string
 	if needAttributes {
-		attributes = make(map[string]string, attributeCount)
+		if p.metaAttr == nil {
+			p.metaAttr = make(map[string]string, 8)
+		}
+		attributes = p.metaAttr
+		clear(attributes)
 	}
 	for i := 0; i < int(attributeCount); i++ {
 		attributeName, err := p.varInt()
@@ -162,7 +290,8 @@ func (p *Parser) readElement(strings []string, needAttributes bool) (element, er
 }
 
 type element struct {
-	name       string
+	name string
+	// attr is only valid until the next element is read.
 	attr       map[string]string
 	childCount int
 }
@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/jfr-parser/parser/types/def"
+)
+
+func TestMetadataAnnotations(t *testing.T) {
+	buf := readGzipFile(t, "testdata/object-allocation-sample.jfr.gz")
+	p := NewParser(buf, Options{ReadAnnotations: true})
+	_, err := p.NextEvent()
+	require.NoError(t, err)
+
+	assert.Equal(t, "en", p.TypeMap.Region.Locale)
+
+	c := p.TypeMap.NameMap["jdk.ThreadSleep"]
+	require.NotNil(t, c)
+	assert.Equal(t, "jdk.jfr.Event", c.SuperType)
+	assert.Equal(t, "Java Thread Sleep", c.Annotations.Label())
+	assert.Equal(t, []string{"Java Application"}, c.Annotations.Category())
+
+	duration := c.Field("duration")
+	require.NotNil(t, duration)
+	assert.Equal(t, "Duration", duration.Annotations.Label())
+	ct := duration.Annotations.ContentType()
+	require.NotNil(t, ct)
+	assert.Equal(t, def.ContentTypeTimespan, ct.Name)
+	assert.Equal(t, "TICKS", ct.Value())
+
+	assert.Nil(t, c.Field("eventThread").Annotations.ContentType())
+	assert.Equal(t, "Thread in which event was committed in", c.Field("eventThread").Annotations.Description())
+
+	settings := map[string]string{}
+	for _, s := range c.Settings {
+		settings[s.Name] = s.DefaultValue
+	}
+	assert.Equal(t, "0 ns", settings["threshold"])
+
+	assert.NotNil(t, p.TypeMap.NameMap[def.ContentTypeTimespan].Annotations.Get(def.AnnotationContentType))
+}
+
+func TestMetadataWithoutAnnotations(t *testing.T) {
+	buf := readGzipFile(t, "testdata/object-allocation-sample.jfr.gz")
+	p := NewParser(buf, Options{})
+	_, err := p.NextEvent()
+	require.NoError(t, err)
+
+	c := p.TypeMap.NameMap["jdk.ThreadSleep"]
+	require.NotNil(t, c)
+	assert.Empty(t, c.Annotations)
+	assert.Empty(t, c.Settings)
+	assert.Equal(t, def.Unit{ContentType: def.ContentTypeTimespan, Value: def.UnitTicks}, c.Field("duration").Unit)
+	assert.NotNil(t, p.TypeMap.NameMap[def.ContentTypeTimespan].Annotations.Get(def.AnnotationContentType))
+}
+
+func TestFieldUnits(t *testing.T) {
+	testcases := []struct {
+		jfr   string
+		event string
+		field string
+		unit  def.Unit
+	}{
+		{"object-allocation-sample", "jdk.ThreadSleep", "duration", def.Unit{ContentType: def.ContentTypeTimespan, Value: def.UnitTicks}},
+		{"object-allocation-sample", "jdk.ThreadSleep", "time", def.Unit{ContentType: def.ContentTypeTimespan, Value: def.UnitMilliseconds}},
+		{"object-allocation-sample", "jdk.ThreadPark", "until", def.Unit{ContentType: def.ContentTypeTimestamp, Value: def.UnitMillisecondsSinceEpoch}},
+		{"object-allocation-sample", "jdk.ObjectAllocationSample", "weight", def.Unit{ContentType: def.ContentTypeDataAmount, Value: def.UnitBytes}},
+		// async-profiler declares jdk.jfr.Timespan without @ContentType
+		{"cortex-dev-01__kafka-0__cpu_lock0_alloc0__0", "jdk.JavaMonitorEnter", "duration", def.Unit{ContentType: def.ContentTypeTimespan, Value: def.UnitTicks}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.jfr+"/"+tc.event+"."+tc.field, func(t *testing.T) {
+			buf := readGzipFile(t, "testdata/"+tc.jfr+".jfr.gz")
+			p := NewParser(buf, Options{})
+			_, err := p.NextEvent()
+			require.NoError(t, err)
+			c := p.TypeMap.NameMap[tc.event]
+			require.NotNil(t, c)
+			f := c.Field(tc.field)
+			require.NotNil(t, f)
+			assert.Equal(t, tc.unit, f.Unit)
+			assert.Equal(t, tc.unit, p.FieldUnit(c.ID, tc.field))
+		})
+	}
+}
+
+func TestFieldUnitPerChunk(t *testing.T) {
+	// async-profiler and the JDK assign different type ids
+	buf := append(readGzipFile(t, "testdata/cortex-dev-01__kafka-0__cpu_lock0_alloc0__0.jfr.gz"),
+		readGzipFile(t, "testdata/object-allocation-sample.jfr.gz")...)
+	p := NewParser(buf, Options{})
+	chunks := 0
+	var chunk ChunkHeader
+	for {
+		typ, err := p.NextEvent()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if h := p.ChunkHeader(); h != chunk {
+			chunk = h
+			chunks++
+		}
+		c := p.TypeMap.IDMap[typ]
+		require.NotNil(t, c)
+		for _, f := range c.Fields {
+			require.Equal(t, f.Unit, p.FieldUnit(typ, f.Name), c.Name+"."+f.Name)
+		}
+	}
+	assert.Equal(t, 2, chunks)
+}
+
+func TestTimespanNanos(t *testing.T) {
+	h := ChunkHeader{TicksPerSecond: 2_000_000_000}
+	assert.Equal(t, int64(500), h.TimespanNanos(def.Unit{ContentType: def.ContentTypeTimespan, Value: def.UnitTicks}, 1000))
+	assert.Equal(t, int64(1000), h.TimespanNanos(def.Unit{ContentType: def.ContentTypeTimespan, Value: def.UnitNanoseconds}, 1000))
+	assert.Equal(t, int64(3_000_000), h.TimespanNanos(def.Unit{ContentType: def.ContentTypeTimespan, Value: def.UnitMilliseconds}, 3))
+	assert.Equal(t, int64(3_000_000), h.TimestampNanos(def.Unit{ContentType: def.ContentTypeTimestamp, Value: def.UnitMillisecondsSinceEpoch}, 3))
+	assert.Equal(t, int64(2), def.Unit{ContentType: def.ContentTypeDataAmount, Value: def.UnitBits}.Bytes(16))
+}
@@ -1,8 +1,10 @@
 package parser
 
 import (
+	"cmp"
 	"fmt"
 	"io"
+	"time"
 	"unsafe"
 
 	types2 "github.com/grafana/jfr-parser/parser/types"
@@ -13,6 +15,11 @@ const chunkHeaderSize = 68
 const bufferSize = 1024 * 1024
 const chunkMagic = 0x464c5200
 
+const (
+	typeMetadata     = 0
+	typeConstantPool = 1
+)
+
 type ChunkHeader struct {
 	Magic              uint32
 	Version            uint32
@@ -30,36 +37,126 @@ func (c *ChunkHeader) String() string {
 	return fmt.Sprintf("ChunkHeader{Magic: %x, Version: %x, Size: %d, OffsetConstantPool: %d, OffsetMeta: %d, StartNanos: %d, DurationNanos: %d, StartTicks: %d, TicksPerSecond: %d, Features: %d}", c.Magic, c.Version, c.Size, c.OffsetConstantPool, c.OffsetMeta, c.StartNanos, c.DurationNanos, c.StartTicks, c.TicksPerSecond, c.Features)
 }
 
+// TicksToNanos converts a tick timestamp from this chunk to nanoseconds since the epoch.
+func (c *ChunkHeader) TicksToNanos(ticks uint64) int64 {
+	return int64(c.StartNanos) + c.TicksToDuration(int64(ticks-c.StartTicks)).Nanoseconds()
+}
+
+// TicksToDuration converts a tick duration from this chunk to time.Duration.
+func (c *ChunkHeader) TicksToDuration(ticks int64) time.Duration {
+	if c.TicksPerSecond == 0 || c.TicksPerSecond == 1e9 {
+		return time.Duration(ticks)
+	}
+	return time.Duration(float64(ticks) * 1e9 / float64(c.TicksPerSecond))
+}
+
+// TimespanNanos converts a jdk.jfr.Timespan value measured in u to nanoseconds.
+func (c *ChunkHeader) TimespanNanos(u def.Unit, v int64) int64 {
+	switch u.Value {
+	case def.UnitTicks:
+		return c.TicksToDuration(v).Nanoseconds()
+	case def.UnitMicroseconds:
+		return v * int64(time.Microsecond)
+	case def.UnitMilliseconds:
+		return v * int64(time.Millisecond)
+	case def.UnitSeconds:
+		return v * int64(time.Second)
+	default:
+		return v
+	}
+}
+
+// TimestampNanos converts a jdk.jfr.Timestamp value measured in u to nanoseconds since epoch.
+func (c *ChunkHeader) TimestampNanos(u def.Unit, v int64) int64 {
+	switch u.Value {
+	case def.UnitTicks:
+		return c.TicksToNanos(uint64(v))
+	default:
+		return v * int64(time.Millisecond)
+	}
+}
+
 type SymbolProcessor func(ref *types2.SymbolList)
 
 type Options struct {
 	ChunkSizeLimit  int
 	SymbolProcessor SymbolProcessor
+	// IndexConstantPools records the position of every constant pool entry,
+	// which Constant needs to resolve references of any type.
+	IndexConstantPools bool
+	// ReadAnnotations keeps all annotations of classes and fields and the settings of event types,
+	// e.g. labels and descriptions. Otherwise only the field annotations Field.Unit is derived
+	// from and the annotations of annotation types are kept.
+	ReadAnnotations bool
+	// OnChunk is called once the header, metadata and constant pools of a chunk are read,
+	// before its first event. It is called for chunks without events as well.
+	OnChunk func(h ChunkHeader)
 }
 
 type Parser struct {
-	FrameTypes   types2.FrameTypeList
-	ThreadStates types2.ThreadStateList
-	Threads      types2.ThreadList
-	Classes      types2.ClassList
-	Methods      types2.MethodList
-	Packages     types2.PackageList
-	Symbols      types2.SymbolList
-	LogLevels    types2.LogLevelList
-	Stacktrace   types2.StackTraceList
-	Strings      types2.StringList
-
-	ExecutionSample             types2.ExecutionSample
-	WallClockSample             types2.WallClockSample
-	Malloc                      types2.Malloc
-	Free                        types2.Free
-	ObjectAllocationInNewTLAB   types2.ObjectAllocationInNewTLAB
-	ObjectAllocationOutsideTLAB types2.ObjectAllocationOutsideTLAB
-	ObjectAllocationSample      types2.ObjectAllocationSample
-	JavaMonitorEnter            types2.JavaMonitorEnter
-	ThreadPark                  types2.ThreadPark
-	LiveObject                  types2.LiveObject
-	ActiveSetting               types2.ActiveSetting
+	FrameTypes            types2.FrameTypeList
+	ThreadStates          types2.ThreadStateList
+	Threads               types2.ThreadList
+	Classes               types2.ClassList
+	Methods               types2.MethodList
+	Packages              types2.PackageList
+	Symbols               types2.SymbolList
+	LogLevels             types2.LogLevelList
+	Stacktrace            types2.StackTraceList
+	Strings               types2.StringList
+	GCNames               types2.GCNameList
+	GCCauses              types2.GCCauseList
+	GCWhens               types2.GCWhenList
+	ThreadGroups          types2.ThreadGroupList
+	CompilerTypes         types2.CompilerTypeList
+	DeoptimizationReasons types2.DeoptimizationReasonList
+	DeoptimizationActions types2.DeoptimizationActionList
+	Bytecodes             types2.BytecodeList
+	VMOperationTypes      types2.VMOperationTypeList
+
+	ExecutionSample               types2.ExecutionSample
+	WallClockSample               types2.WallClockSample
+	Malloc                        types2.Malloc
+	Free                          types2.Free
+	ObjectAllocationInNewTLAB     types2.ObjectAllocationInNewTLAB
+	ObjectAllocationOutsideTLAB   types2.ObjectAllocationOutsideTLAB
+	ObjectAllocationSample        types2.ObjectAllocationSample
+	JavaMonitorEnter              types2.JavaMonitorEnter
+	ThreadPark                    types2.ThreadPark
+	LiveObject                    types2.LiveObject
+	ActiveSetting                 types2.ActiveSetting
+	CPUTimeSample                 types2.CPUTimeSample
+	CPUTimeSamplesLost            types2.CPUTimeSamplesLost
+	NativeMethodSample            types2.NativeMethodSample
+	GarbageCollection             types2.GarbageCollection
+	GCHeapSummary                 types2.GCHeapSummary
+	GCPhasePause                  types2.GCPhasePause
+	YoungGarbageCollection        types2.YoungGarbageCollection
+	OldGarbageCollection          types2.OldGarbageCollection
+	CPULoad                       types2.CPULoad
+	ThreadCPULoad                 types2.ThreadCPULoad
+	JavaExceptionThrow            types2.JavaExceptionThrow
+	JavaErrorThrow                types2.JavaErrorThrow
+	ExceptionStatistics           types2.ExceptionStatistics
+	SocketRead                    types2.SocketRead
+	SocketWrite                   types2.SocketWrite
+	FileRead                      types2.FileRead
+	FileWrite                     types2.FileWrite
+	VirtualThreadPinned           types2.VirtualThreadPinned
+	VirtualThreadSubmitFailed     types2.VirtualThreadSubmitFailed
+	VirtualThreadStart            types2.VirtualThreadStart
+	VirtualThreadEnd              types2.VirtualThreadEnd
+	Compilation                   types2.Compilation
+	CompilationFailure            types2.CompilationFailure
+	Deoptimization                types2.Deoptimization
+	SafepointBegin                types2.SafepointBegin
+	SafepointStateSynchronization types2.SafepointStateSynchronization
+	SafepointEnd                  types2.SafepointEnd
+	ExecuteVMOperation            types2.ExecuteVMOperation
+	JVMInformation                types2.JVMInformation
+	OSInformation                 types2.OSInformation
+	CPUInformation                types2.CPUInformation
+	InitialSystemProperty         types2.InitialSystemProperty
 
 	header   ChunkHeader
 	options  Options
@@ -68,19 +165,47 @@ type Parser struct {
 	metaSize uint32
 	chunkEnd int
 
+	// chunks counts the chunks read so far, StackCache drops its entries when it changes.
+	chunks int
+
+	// units caches FieldUnit, it is reset with the metadata of every chunk.
+	units map[fieldKey]def.Unit
+
+	// metaAttr is the attribute map readElement reuses for every element.
+	metaAttr map[string]string
+	// metaAnnotations and metaValues back the annotations of the current chunk's classes.
+	metaAnnotations []def.Annotation
+	metaValues      []string
+
+	eventType  def.TypeID
+	eventStart int
+	eventPos   int
+	eventEnd   int
+	constants  map[def.TypeID]map[uint64]int
+
 	TypeMap def.TypeMap
 
-	bindFrameType   *types2.BindFrameType
-	bindThreadState *types2.BindThreadState
-	bindThread      *types2.BindThread
-	bindClass       *types2.BindClass
-	bindMethod      *types2.BindMethod
-	bindPackage     *types2.BindPackage
-	bindSymbol      *types2.BindSymbol
-	bindLogLevel    *types2.BindLogLevel
-	bindStackFrame  *types2.BindStackFrame
-	bindStackTrace  *types2.BindStackTrace
-	bindString      *types2.BindString
+	bindFrameType            *types2.BindFrameType
+	bindThreadState          *types2.BindThreadState
+	bindThread               *types2.BindThread
+	bindClass                *types2.BindClass
+	bindMethod               *types2.BindMethod
+	bindPackage              *types2.BindPackage
+	bindSymbol               *types2.BindSymbol
+	bindLogLevel             *types2.BindLogLevel
+	bindStackFrame           *types2.BindStackFrame
+	bindStackTrace           *types2.BindStackTrace
+	bindString               *types2.BindString
+	bindGCName               *types2.BindGCName
+	bindGCCause              *types2.BindGCCause
+	bindGCWhen               *types2.BindGCWhen
+	bindVirtualSpace         *types2.BindVirtualSpace
+	bindThreadGroup          *types2.BindThreadGroup
+	bindCompilerType         *types2.BindCompilerType
+	bindDeoptimizationReason *types2.BindDeoptimizationReason
+	bindDeoptimizationAction *types2.BindDeoptimizationAction
+	bindBytecode             *types2.BindBytecode
+	bindVMOperationType      *types2.BindVMOperationType
 
 	bindExecutionSample *types2.BindExecutionSample
 
@@ -94,6 +219,41 @@ type Parser struct {
 	bindWallClockSample  *types2.BindWallClockSample
 	bindMalloc           *types2.BindMalloc
 	bindFree             *types2.BindFree
+
+	bindCPUTimeSample      *types2.BindCPUTimeSample
+	bindCPUTimeSamplesLost *types2.BindCPUTimeSamplesLost
+	bindNativeMethodSample *types2.BindNativeMethodSample
+
+	bindGarbageCollection *types2.BindGarbageCollection
+	bindGCHeapSummary     *types2.BindGCHeapSummary
+	bindGCPhasePause      *types2.BindGCPhasePause
+	bindYoungGC           *types2.BindYoungGarbageCollection
+	bindOldGC             *types2.BindOldGarbageCollection
+
+	bindCPULoad                       *types2.BindCPULoad
+	bindThreadCPULoad                 *types2.BindThreadCPULoad
+	bindJavaExceptionThrow            *types2.BindJavaExceptionThrow
+	bindJavaErrorThrow                *types2.BindJavaErrorThrow
+	bindExceptionStatistics           *types2.BindExceptionStatistics
+	bindSocketRead                    *types2.BindSocketRead
+	bindSocketWrite                   *types2.BindSocketWrite
+	bindFileRead                      *types2.BindFileRead
+	bindFileWrite                     *types2.BindFileWrite
+	bindVirtualThreadPinned           *types2.BindVirtualThreadPinned
+	bindVirtualThreadSubmitFailed     *types2.BindVirtualThreadSubmitFailed
+	bindVirtualThreadStart            *types2.BindVirtualThreadStart
+	bindVirtualThreadEnd              *types2.BindVirtualThreadEnd
+	bindCompilation                   *types2.BindCompilation
+	bindCompilationFailure            *types2.BindCompilationFailure
+	bindDeoptimization                *types2.BindDeoptimization
+	bindSafepointBegin                *types2.BindSafepointBegin
+	bindSafepointStateSynchronization *types2.BindSafepointStateSynchronization
+	bindSafepointEnd                  *types2.BindSafepointEnd
+	bindExecuteVMOperation            *types2.BindExecuteVMOperation
+	bindJVMInformation                *types2.BindJVMInformation
+	bindOSInformation                 *types2.BindOSInformation
+	bindCPUInformation                *types2.BindCPUInformation
+	bindInitialSystemProperty         *types2.BindInitialSystemProperty
 }
 
 func NewParser(buf []byte, options Options) *Parser {
@@ -129,6 +289,10 @@ func (p *Parser) ParseEvent() (def.TypeID, error) {
 		_ = size
 
 		ttyp := def.TypeID(typ)
+		p.eventType = ttyp
+		p.eventStart = pp
+		p.eventPos = p.pos
+		p.eventEnd = pp + int(size)
 		switch ttyp {
 		case p.TypeMap.T_EXECUTION_SAMPLE:
 			if p.bindExecutionSample == nil {
@@ -239,7 +403,359 @@ func (p *Parser) ParseEvent() (def.TypeID, error) {
 			}
 			p.pos = pp + int(size)
 			return ttyp, nil
-
+		case p.TypeMap.T_CPU_TIME_SAMPLE:
+			if p.bindCPUTimeSample == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.CPUTimeSample.Parse(p.buf[p.pos:], p.bindCPUTimeSample, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_CPU_TIME_SAMPLES_LOST:
+			if p.bindCPUTimeSamplesLost == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.CPUTimeSamplesLost.Parse(p.buf[p.pos:], p.bindCPUTimeSamplesLost, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_NATIVE_METHOD_SAMPLE:
+			if p.bindNativeMethodSample == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.NativeMethodSample.Parse(p.buf[p.pos:], p.bindNativeMethodSample, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+
+		case p.TypeMap.T_GARBAGE_COLLECTION:
+			if p.bindGarbageCollection == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.GarbageCollection.Parse(p.buf[p.pos:], p.bindGarbageCollection, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_GC_HEAP_SUMMARY:
+			if p.bindGCHeapSummary == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.GCHeapSummary.Parse(p.buf[p.pos:], p.bindGCHeapSummary, p.bindVirtualSpace, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_GC_PHASE_PAUSE:
+			if p.bindGCPhasePause == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.GCPhasePause.Parse(p.buf[p.pos:], p.bindGCPhasePause, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_YOUNG_GC:
+			if p.bindYoungGC == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.YoungGarbageCollection.Parse(p.buf[p.pos:], p.bindYoungGC, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_OLD_GC:
+			if p.bindOldGC == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.OldGarbageCollection.Parse(p.buf[p.pos:], p.bindOldGC, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_CPU_LOAD:
+			if p.bindCPULoad == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.CPULoad.Parse(p.buf[p.pos:], p.bindCPULoad, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_THREAD_CPU_LOAD:
+			if p.bindThreadCPULoad == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.ThreadCPULoad.Parse(p.buf[p.pos:], p.bindThreadCPULoad, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_JAVA_EXCEPTION_THROW:
+			if p.bindJavaExceptionThrow == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.JavaExceptionThrow.Parse(p.buf[p.pos:], p.bindJavaExceptionThrow, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_JAVA_ERROR_THROW:
+			if p.bindJavaErrorThrow == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.JavaErrorThrow.Parse(p.buf[p.pos:], p.bindJavaErrorThrow, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_EXCEPTION_STATISTICS:
+			if p.bindExceptionStatistics == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.ExceptionStatistics.Parse(p.buf[p.pos:], p.bindExceptionStatistics, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_SOCKET_READ:
+			if p.bindSocketRead == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.SocketRead.Parse(p.buf[p.pos:], p.bindSocketRead, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_SOCKET_WRITE:
+			if p.bindSocketWrite == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.SocketWrite.Parse(p.buf[p.pos:], p.bindSocketWrite, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_FILE_READ:
+			if p.bindFileRead == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.FileRead.Parse(p.buf[p.pos:], p.bindFileRead, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_FILE_WRITE:
+			if p.bindFileWrite == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.FileWrite.Parse(p.buf[p.pos:], p.bindFileWrite, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_VIRTUAL_THREAD_PINNED:
+			if p.bindVirtualThreadPinned == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.VirtualThreadPinned.Parse(p.buf[p.pos:], p.bindVirtualThreadPinned, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_VIRTUAL_THREAD_SUBMIT_FAILED:
+			if p.bindVirtualThreadSubmitFailed == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.VirtualThreadSubmitFailed.Parse(p.buf[p.pos:], p.bindVirtualThreadSubmitFailed, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_VIRTUAL_THREAD_START:
+			if p.bindVirtualThreadStart == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.VirtualThreadStart.Parse(p.buf[p.pos:], p.bindVirtualThreadStart, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_VIRTUAL_THREAD_END:
+			if p.bindVirtualThreadEnd == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.VirtualThreadEnd.Parse(p.buf[p.pos:], p.bindVirtualThreadEnd, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_COMPILATION:
+			if p.bindCompilation == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.Compilation.Parse(p.buf[p.pos:], p.bindCompilation, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_COMPILATION_FAILURE:
+			if p.bindCompilationFailure == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.CompilationFailure.Parse(p.buf[p.pos:], p.bindCompilationFailure, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_DEOPTIMIZATION:
+			if p.bindDeoptimization == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.Deoptimization.Parse(p.buf[p.pos:], p.bindDeoptimization, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_SAFEPOINT_BEGIN:
+			if p.bindSafepointBegin == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.SafepointBegin.Parse(p.buf[p.pos:], p.bindSafepointBegin, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_SAFEPOINT_STATE_SYNCHRONIZATION:
+			if p.bindSafepointStateSynchronization == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.SafepointStateSynchronization.Parse(p.buf[p.pos:], p.bindSafepointStateSynchronization, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_SAFEPOINT_END:
+			if p.bindSafepointEnd == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.SafepointEnd.Parse(p.buf[p.pos:], p.bindSafepointEnd, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_EXECUTE_VM_OPERATION:
+			if p.bindExecuteVMOperation == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.ExecuteVMOperation.Parse(p.buf[p.pos:], p.bindExecuteVMOperation, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_JVM_INFORMATION:
+			if p.bindJVMInformation == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.JVMInformation.Parse(p.buf[p.pos:], p.bindJVMInformation, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_OS_INFORMATION:
+			if p.bindOSInformation == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.OSInformation.Parse(p.buf[p.pos:], p.bindOSInformation, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_CPU_INFORMATION:
+			if p.bindCPUInformation == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.CPUInformation.Parse(p.buf[p.pos:], p.bindCPUInformation, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_INITIAL_SYSTEM_PROPERTY:
+			if p.bindInitialSystemProperty == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.InitialSystemProperty.Parse(p.buf[p.pos:], p.bindInitialSystemProperty, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
 		case p.TypeMap.T_ACTIVE_SETTING:
 			if p.bindActiveSetting == nil {
 				p.pos = pp + int(size) // skip
@@ -258,10 +774,107 @@ func (p *Parser) ParseEvent() (def.TypeID, error) {
 	}
 }
 
+// NextEvent advances to the next event of any type without decoding it.
+// Use DecodeEvent to read its fields.
+func (p *Parser) NextEvent() (def.TypeID, error) {
+	for {
+		if p.pos == p.chunkEnd {
+			if p.pos == len(p.buf) {
+				return 0, io.EOF
+			}
+			if err := p.readChunk(p.pos); err != nil {
+				return 0, err
+			}
+		}
+		pp := p.pos
+		size, err := p.varLong()
+		if err != nil {
+			return 0, err
+		}
+		if size == 0 {
+			return 0, def.ErrIntOverflow
+		}
+		typ, err := p.varLong()
+		if err != nil {
+			return 0, err
+		}
+		if pp+int(size) > p.chunkEnd || pp+int(size) < p.pos {
+			return 0, io.ErrUnexpectedEOF
+		}
+		eventPos := p.pos
+		p.pos = pp + int(size)
+		if typ == typeMetadata || typ == typeConstantPool {
+			continue
+		}
+		p.eventType = def.TypeID(typ)
+		p.eventStart = pp
+		p.eventPos = eventPos
+		p.eventEnd = p.pos
+		return p.eventType, nil
+	}
+}
+
 func (p *Parser) ChunkHeader() ChunkHeader {
 	return p.header
 }
 
+type fieldKey struct {
+	typ   def.TypeID
+	field string
+}
+
+// FieldUnit returns the unit of a field of type typ in the current chunk, the zero
+// Unit if the type or field is unknown. Units are cached per chunk.
+func (p *Parser) FieldUnit(typ def.TypeID, field string) def.Unit {
+	k := fieldKey{typ: typ, field: field}
+	if u, ok := p.units[k]; ok {
+		return u
+	}
+	var u def.Unit
+	if c := p.TypeMap.IDMap[typ]; c != nil {
+		if f := c.Field(field); f != nil {
+			u = f.Unit
+		}
+	}
+	if p.units == nil {
+		p.units = make(map[fieldKey]def.Unit)
+	}
+	p.units[k] = u
+	return u
+}
+
+// TimespanNanos converts the value of a jdk.jfr.Timespan field of event type typ
+// to nanoseconds using the field's unit in the current chunk.
+func (p *Parser) TimespanNanos(typ def.TypeID, field string, v int64) int64 {
+	return p.header.TimespanNanos(p.FieldUnit(typ, field), v)
+}
+
+// TimestampNanos converts the value of a jdk.jfr.Timestamp field of event type typ
+// to nanoseconds since epoch using the field's unit in the current chunk.
+// Fields without a unit are treated as ticks.
+func (p *Parser) TimestampNanos(typ def.TypeID, field string, v int64) int64 {
+	u := p.FieldUnit(typ, field)
+	if u.Value == "" {
+		u = def.Unit{Value: def.UnitTicks}
+	}
+	return p.header.TimestampNanos(u, v)
+}
+
+// EventSize returns the size in bytes of the current event record, including its header.
+func (p *Parser) EventSize() int {
+	return p.eventEnd - p.eventStart
+}
+
+// ConstantPoolSizes returns the number of constant pool entries per type in the
+// current chunk. It requires Options.IndexConstantPools.
+func (p *Parser) ConstantPoolSizes() map[def.TypeID]int {
+	res := make(map[def.TypeID]int, len(p.constants))
+	for typ, pool := range p.constants {
+		res[typ] = len(pool)
+	}
+	return res
+}
+
 func (p *Parser) GetStacktrace(stID types2.StackTraceRef) *types2.StackTrace {
 	idx, ok := p.Stacktrace.IDMap[stID]
 	if !ok {
@@ -278,6 +891,94 @@ func (p *Parser) GetThreadState(ref types2.ThreadStateRef) *types2.ThreadState {
 	return &p.ThreadStates.ThreadState[idx]
 }
 
+func (p *Parser) GetThreadGroup(ref types2.ThreadGroupRef) *types2.ThreadGroup {
+	idx, ok := p.ThreadGroups.IDMap[ref]
+	if !ok {
+		return nil
+	}
+	return &p.ThreadGroups.ThreadGroup[idx]
+}
+
+func (p *Parser) GetCompilerType(ref types2.CompilerTypeRef) *types2.CompilerType {
+	idx, ok := p.CompilerTypes.IDMap[ref]
+	if !ok {
+		return nil
+	}
+	return &p.CompilerTypes.CompilerType[idx]
+}
+
+func (p *Parser) GetDeoptimizationReason(ref types2.DeoptimizationReasonRef) *types2.DeoptimizationReason {
+	idx, ok := p.DeoptimizationReasons.IDMap[ref]
+	if !ok {
+		return nil
+	}
+	return &p.DeoptimizationReasons.DeoptimizationReason[idx]
+}
+
+func (p *Parser) GetDeoptimizationAction(ref types2.DeoptimizationActionRef) *types2.DeoptimizationAction {
+	idx, ok := p.DeoptimizationActions.IDMap[ref]
+	if !ok {
+		return nil
+	}
+	return &p.DeoptimizationActions.DeoptimizationAction[idx]
+}
+
+func (p *Parser) GetBytecode(ref types2.BytecodeRef) *types2.Bytecode {
+	idx, ok := p.Bytecodes.IDMap[ref]
+	if !ok {
+		return nil
+	}
+	return &p.Bytecodes.Bytecode[idx]
+}
+
+func (p *Parser) GetVMOperationType(ref types2.VMOperationTypeRef) *types2.VMOperationType {
+	idx, ok := p.VMOperationTypes.IDMap[ref]
+	if !ok {
+		return nil
+	}
+	return &p.VMOperationTypes.VMOperationType[idx]
+}
+
+func (p *Parser) GetGCName(ref types2.GCNameRef) *types2.GCName {
+	idx, ok := p.GCNames.IDMap[ref]
+	if !ok {
+		return nil
+	}
+	return &p.GCNames.GCName[idx]
+}
+
+func (p *Parser) GetGCCause(ref types2.GCCauseRef) *types2.GCCause {
+	idx, ok := p.GCCauses.IDMap[ref]
+	if !ok {
+		return nil
+	}
+	return &p.GCCauses.GCCause[idx]
+}
+
+func (p *Parser) GetGCWhen(ref types2.GCWhenRef) *types2.GCWhen {
+	idx, ok := p.GCWhens.IDMap[ref]
+	if !ok {
+		return nil
+	}
+	return &p.GCWhens.GCWhen[idx]
+}
+
+func (p *Parser) GetThread(ref types2.ThreadRef) *types2.Thread {
+	idx, ok := p.Threads.IDMap[ref]
+	if !ok {
+		return nil
+	}
+	return &p.Threads.Thread[idx]
+}
+
+func (p *Parser) GetFrameType(ref types2.FrameTypeRef) *types2.FrameType {
+	idx, ok := p.FrameTypes.IDMap[ref]
+	if !ok {
+		return nil
+	}
+	return &p.FrameTypes.FrameType[idx]
+}
+
 func (p *Parser) GetMethod(mID types2.MethodRef) *types2.Method {
 	idx, ok := p.Methods.IDMap[mID]
 	if !ok || int(idx) >= len(p.Methods.Method) {
@@ -294,6 +995,83 @@ func (p *Parser) GetClass(cID types2.ClassRef) *types2.Class {
 	return &p.Classes.Class[idx]
 }
 
+// FrameName returns the name profiles use for a frame of method m, the class name
+// in internal form followed by the method name, e.g. java/lang/Thread.run.
+// It reports false if the class of m is not in the constant pool.
+func (p *Parser) FrameName(m *types2.Method) (string, bool) {
+	cls := p.GetClass(m.Type)
+	if cls == nil {
+		return "", false
+	}
+	return p.GetSymbolString(cls.Name) + "." + p.GetSymbolString(m.Name), true
+}
+
+// ThreadKey identifies a thread across chunks, thread refs are only valid within a chunk.
+// Threads are keyed by their Java thread id, or their OS thread id if they have none.
+// The two id spaces overlap, Java is part of the key. Names are not unique, virtual
+// threads are usually unnamed.
+type ThreadKey struct {
+	ID   uint64
+	Java bool
+}
+
+// ThreadKeyOf returns the key of thread t.
+func ThreadKeyOf(t *types2.Thread) ThreadKey {
+	if t.JavaThreadId != 0 {
+		return ThreadKey{ID: t.JavaThreadId, Java: true}
+	}
+	return ThreadKey{ID: t.OsThreadId}
+}
+
+// CompareThreadKeys orders thread keys by id, Java threads first.
+func CompareThreadKeys(x, y ThreadKey) int {
+	if c := cmp.Compare(x.ID, y.ID); c != 0 {
+		return c
+	}
+	switch {
+	case x.Java == y.Java:
+		return 0
+	case x.Java:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// StackCache caches a value derived from a stack trace, e.g. its frame names, per
+// stack trace ref. Refs are only valid within a chunk, the cache drops its entries
+// when the parser moves on to the next chunk.
+type StackCache[T any] struct {
+	parser *Parser
+	chunk  int
+	values map[types2.StackTraceRef]T
+}
+
+func NewStackCache[T any](p *Parser) *StackCache[T] {
+	return &StackCache[T]{parser: p, values: make(map[types2.StackTraceRef]T)}
+}
+
+// Get returns the value of the stack trace ref, resolving the stack trace with resolve
+// the first time ref is seen in a chunk. It reports false if the stack trace is not
+// in the constant pool.
+func (c *StackCache[T]) Get(ref types2.StackTraceRef, resolve func(st *types2.StackTrace) T) (T, bool) {
+	if c.chunk != c.parser.chunks {
+		c.chunk = c.parser.chunks
+		clear(c.values)
+	}
+	if v, ok := c.values[ref]; ok {
+		return v, true
+	}
+	st := c.parser.GetStacktrace(ref)
+	if st == nil {
+		var zero T
+		return zero, false
+	}
+	v := resolve(st)
+	c.values[ref] = v
+	return v, true
+}
+
 func (p *Parser) GetSymbol(sID types2.SymbolRef) *types2.Symbol {
 	idx, ok := p.Symbols.IDMap[sID]
 	if !ok {
@@ -310,10 +1088,20 @@ func (p *Parser) GetSymbolString(sID types2.SymbolRef) string {
 	return p.Symbols.Symbol[idx].String
 }
 
+// getString resolves a java.lang.String constant of the current chunk.
+func (p *Parser) getString(id uint64) string {
+	idx, ok := p.Strings.IDMap[types2.StringRef(id)]
+	if !ok {
+		return ""
+	}
+	return p.Strings.String[idx].String
+}
+
 func (p *Parser) readChunk(pos int) error {
 	if err := p.readChunkHeader(pos); err != nil {
 		return fmt.Errorf("error reading chunk header: %w", err)
 	}
+	p.chunks++
 
 	if err := p.readMeta(pos + p.header.OffsetMeta); err != nil {
 		return fmt.Errorf("error reading metadata: %w", err)
@@ -326,6 +1114,9 @@ func (p *Parser) readChunk(pos int) error {
 		pp(&p.Symbols)
 	}
 	p.pos = pos + chunkHeaderSize
+	if p.options.OnChunk != nil {
+		p.options.OnChunk(p.header)
+	}
 	return nil
 }
 
@@ -481,6 +1272,18 @@ func (p *Parser) checkTypes() error {
 	p.TypeMap.T_FLOAT = tfloat.ID
 	p.TypeMap.T_BOOLEAN = tboolean.ID
 	p.TypeMap.T_STRING = tstring.ID
+	p.TypeMap.T_BYTE = -1
+	p.TypeMap.T_CHAR = -1
+	p.TypeMap.T_DOUBLE = -1
+	if tbyte := p.TypeMap.NameMap["byte"]; tbyte != nil {
+		p.TypeMap.T_BYTE = tbyte.ID
+	}
+	if tchar := p.TypeMap.NameMap["char"]; tchar != nil {
+		p.TypeMap.T_CHAR = tchar.ID
+	}
+	if tdouble := p.TypeMap.NameMap["double"]; tdouble != nil {
+		p.TypeMap.T_DOUBLE = tdouble.ID
+	}
 
 	typeCPFrameType := p.TypeMap.NameMap["jdk.types.FrameType"]
 	typeCPThreadState := p.TypeMap.NameMap["jdk.types.ThreadState"]
@@ -492,6 +1295,7 @@ func (p *Parser) checkTypes() error {
 	typeCPLogLevel := p.TypeMap.NameMap["profiler.types.LogLevel"]
 	typeCPStackTrace := p.TypeMap.NameMap["jdk.types.StackTrace"]
 	typeCPClassLoader := p.TypeMap.NameMap["jdk.types.ClassLoader"]
+	typeCPThreadGroup := p.TypeMap.NameMap["jdk.types.ThreadGroup"]
 
 	if typeCPFrameType == nil {
 		return fmt.Errorf("missing \"jdk.types.FrameType\"")
@@ -534,6 +1338,12 @@ func (p *Parser) checkTypes() error {
 	}
 	p.TypeMap.T_STACK_TRACE = typeCPStackTrace.ID
 	p.TypeMap.T_CLASS_LOADER = typeCPClassLoader.ID
+	// thread groups are only present in recordings made by the JDK
+	if typeCPThreadGroup != nil {
+		p.TypeMap.T_THREAD_GROUP = typeCPThreadGroup.ID
+	} else {
+		p.TypeMap.T_THREAD_GROUP = -1
+	}
 
 	typeStackFrame := p.TypeMap.NameMap["jdk.types.StackFrame"]
 
@@ -554,10 +1364,93 @@ func (p *Parser) checkTypes() error {
 	} else {
 		p.bindLogLevel = nil
 	}
+	if typeCPThreadGroup != nil {
+		p.bindThreadGroup = types2.NewBindThreadGroup(typeCPThreadGroup, &p.TypeMap)
+	} else {
+		p.bindThreadGroup = nil
+	}
 	p.bindStackTrace = types2.NewBindStackTrace(typeCPStackTrace, &p.TypeMap)
 	p.bindStackFrame = types2.NewBindStackFrame(typeStackFrame, &p.TypeMap)
 	p.bindString = types2.NewBindString(tstring, &p.TypeMap)
 
+	// GC types are only present in recordings made by the JDK
+	typeCPGCName := p.TypeMap.NameMap["jdk.types.GCName"]
+	typeCPGCCause := p.TypeMap.NameMap["jdk.types.GCCause"]
+	typeCPGCWhen := p.TypeMap.NameMap["jdk.types.GCWhen"]
+	typeVirtualSpace := p.TypeMap.NameMap["jdk.types.VirtualSpace"]
+	if typeCPGCName != nil {
+		p.TypeMap.T_GC_NAME = typeCPGCName.ID
+		p.bindGCName = types2.NewBindGCName(typeCPGCName, &p.TypeMap)
+	} else {
+		p.TypeMap.T_GC_NAME = -1
+		p.bindGCName = nil
+	}
+	if typeCPGCCause != nil {
+		p.TypeMap.T_GC_CAUSE = typeCPGCCause.ID
+		p.bindGCCause = types2.NewBindGCCause(typeCPGCCause, &p.TypeMap)
+	} else {
+		p.TypeMap.T_GC_CAUSE = -1
+		p.bindGCCause = nil
+	}
+	if typeCPGCWhen != nil {
+		p.TypeMap.T_GC_WHEN = typeCPGCWhen.ID
+		p.bindGCWhen = types2.NewBindGCWhen(typeCPGCWhen, &p.TypeMap)
+	} else {
+		p.TypeMap.T_GC_WHEN = -1
+		p.bindGCWhen = nil
+	}
+	if typeVirtualSpace != nil {
+		p.TypeMap.T_VIRTUAL_SPACE = typeVirtualSpace.ID
+		p.bindVirtualSpace = types2.NewBindVirtualSpace(typeVirtualSpace, &p.TypeMap)
+	} else {
+		p.TypeMap.T_VIRTUAL_SPACE = -1
+		p.bindVirtualSpace = nil
+	}
+
+	// VM operation types are only present in recordings made by the JDK
+	typeCPVMOperationType := p.TypeMap.NameMap["jdk.types.VMOperationType"]
+	if typeCPVMOperationType != nil {
+		p.TypeMap.T_VM_OPERATION_TYPE = typeCPVMOperationType.ID
+		p.bindVMOperationType = types2.NewBindVMOperationType(typeCPVMOperationType, &p.TypeMap)
+	} else {
+		p.TypeMap.T_VM_OPERATION_TYPE = -1
+		p.bindVMOperationType = nil
+	}
+
+	// JIT types are only present in recordings made by the JDK
+	typeCPCompilerType := p.TypeMap.NameMap["jdk.types.CompilerType"]
+	typeCPDeoptimizationReason := p.TypeMap.NameMap["jdk.types.DeoptimizationReason"]
+	typeCPDeoptimizationAction := p.TypeMap.NameMap["jdk.types.DeoptimizationAction"]
+	typeCPBytecode := p.TypeMap.NameMap["jdk.types.Bytecode"]
+	if typeCPCompilerType != nil {
+		p.TypeMap.T_COMPILER_TYPE = typeCPCompilerType.ID
+		p.bindCompilerType = types2.NewBindCompilerType(typeCPCompilerType, &p.TypeMap)
+	} else {
+		p.TypeMap.T_COMPILER_TYPE = -1
+		p.bindCompilerType = nil
+	}
+	if typeCPDeoptimizationReason != nil {
+		p.TypeMap.T_DEOPTIMIZATION_REASON = typeCPDeoptimizationReason.ID
+		p.bindDeoptimizationReason = types2.NewBindDeoptimizationReason(typeCPDeoptimizationReason, &p.TypeMap)
+	} else {
+		p.TypeMap.T_DEOPTIMIZATION_REASON = -1
+		p.bindDeoptimizationReason = nil
+	}
+	if typeCPDeoptimizationAction != nil {
+		p.TypeMap.T_DEOPTIMIZATION_ACTION = typeCPDeoptimizationAction.ID
+		p.bindDeoptimizationAction = types2.NewBindDeoptimizationAction(typeCPDeoptimizationAction, &p.TypeMap)
+	} else {
+		p.TypeMap.T_DEOPTIMIZATION_ACTION = -1
+		p.bindDeoptimizationAction = nil
+	}
+	if typeCPBytecode != nil {
+		p.TypeMap.T_BYTECODE = typeCPBytecode.ID
+		p.bindBytecode = types2.NewBindBytecode(typeCPBytecode, &p.TypeMap)
+	} else {
+		p.TypeMap.T_BYTECODE = -1
+		p.bindBytecode = nil
+	}
+
 	typeExecutionSample := p.TypeMap.NameMap["jdk.ExecutionSample"]
 	typeWallClockSample := p.TypeMap.NameMap["profiler.WallClockSample"]
 	typeAllocInNewTLAB := p.TypeMap.NameMap["jdk.ObjectAllocationInNewTLAB"]
@@ -571,6 +1464,41 @@ func (p *Parser) checkTypes() error {
 	typeMalloc := p.TypeMap.NameMap["profiler.Malloc"]
 	typeFree := p.TypeMap.NameMap["profiler.Free"]
 
+	typeCPUTimeSample := p.TypeMap.NameMap["jdk.CPUTimeSample"]
+	typeCPUTimeSamplesLost := p.TypeMap.NameMap["jdk.CPUTimeSamplesLost"]
+	typeNativeMethodSample := p.TypeMap.NameMap["jdk.NativeMethodSample"]
+
+	typeGarbageCollection := p.TypeMap.NameMap["jdk.GarbageCollection"]
+	typeGCHeapSummary := p.TypeMap.NameMap["jdk.GCHeapSummary"]
+	typeGCPhasePause := p.TypeMap.NameMap["jdk.GCPhasePause"]
+	typeYoungGC := p.TypeMap.NameMap["jdk.YoungGarbageCollection"]
+	typeOldGC := p.TypeMap.NameMap["jdk.OldGarbageCollection"]
+
+	typeCPULoad := p.TypeMap.NameMap["jdk.CPULoad"]
+	typeThreadCPULoad := p.TypeMap.NameMap["jdk.ThreadCPULoad"]
+	typeJavaExceptionThrow := p.TypeMap.NameMap["jdk.JavaExceptionThrow"]
+	typeJavaErrorThrow := p.TypeMap.NameMap["jdk.JavaErrorThrow"]
+	typeExceptionStatistics := p.TypeMap.NameMap["jdk.ExceptionStatistics"]
+	typeSocketRead := p.TypeMap.NameMap["jdk.SocketRead"]
+	typeSocketWrite := p.TypeMap.NameMap["jdk.SocketWrite"]
+	typeFileRead := p.TypeMap.NameMap["jdk.FileRead"]
+	typeFileWrite := p.TypeMap.NameMap["jdk.FileWrite"]
+	typeVirtualThreadPinned := p.TypeMap.NameMap["jdk.VirtualThreadPinned"]
+	typeVirtualThreadSubmitFailed := p.TypeMap.NameMap["jdk.VirtualThreadSubmitFailed"]
+	typeVirtualThreadStart := p.TypeMap.NameMap["jdk.VirtualThreadStart"]
+	typeVirtualThreadEnd := p.TypeMap.NameMap["jdk.VirtualThreadEnd"]
+	typeCompilation := p.TypeMap.NameMap["jdk.Compilation"]
+	typeCompilationFailure := p.TypeMap.NameMap["jdk.CompilationFailure"]
+	typeDeoptimization := p.TypeMap.NameMap["jdk.Deoptimization"]
+	typeSafepointBegin := p.TypeMap.NameMap["jdk.SafepointBegin"]
+	typeSafepointStateSynchronization := p.TypeMap.NameMap["jdk.SafepointStateSynchronization"]
+	typeSafepointEnd := p.TypeMap.NameMap["jdk.SafepointEnd"]
+	typeExecuteVMOperation := p.TypeMap.NameMap["jdk.ExecuteVMOperation"]
+	typeJVMInformation := p.TypeMap.NameMap["jdk.JVMInformation"]
+	typeOSInformation := p.TypeMap.NameMap["jdk.OSInformation"]
+	typeCPUInformation := p.TypeMap.NameMap["jdk.CPUInformation"]
+	typeInitialSystemProperty := p.TypeMap.NameMap["jdk.InitialSystemProperty"]
+
 	if typeExecutionSample != nil {
 		p.TypeMap.T_EXECUTION_SAMPLE = typeExecutionSample.ID
 		p.bindExecutionSample = types2.NewBindExecutionSample(typeExecutionSample, &p.TypeMap)
@@ -655,6 +1583,262 @@ func (p *Parser) checkTypes() error {
 		p.bindActiveSetting = nil
 	}
 
+	if typeCPUTimeSample != nil {
+		p.TypeMap.T_CPU_TIME_SAMPLE = typeCPUTimeSample.ID
+		p.bindCPUTimeSample = types2.NewBindCPUTimeSample(typeCPUTimeSample, &p.TypeMap)
+	} else {
+		p.TypeMap.T_CPU_TIME_SAMPLE = -1
+		p.bindCPUTimeSample = nil
+	}
+
+	if typeCPUTimeSamplesLost != nil {
+		p.TypeMap.T_CPU_TIME_SAMPLES_LOST = typeCPUTimeSamplesLost.ID
+		p.bindCPUTimeSamplesLost = types2.NewBindCPUTimeSamplesLost(typeCPUTimeSamplesLost, &p.TypeMap)
+	} else {
+		p.TypeMap.T_CPU_TIME_SAMPLES_LOST = -1
+		p.bindCPUTimeSamplesLost = nil
+	}
+
+	if typeNativeMethodSample != nil {
+		p.TypeMap.T_NATIVE_METHOD_SAMPLE = typeNativeMethodSample.ID
+		p.bindNativeMethodSample = types2.NewBindNativeMethodSample(typeNativeMethodSample, &p.TypeMap)
+	} else {
+		p.TypeMap.T_NATIVE_METHOD_SAMPLE = -1
+		p.bindNativeMethodSample = nil
+	}
+
+	if typeGarbageCollection != nil {
+		p.TypeMap.T_GARBAGE_COLLECTION = typeGarbageCollection.ID
+		p.bindGarbageCollection = types2.NewBindGarbageCollection(typeGarbageCollection, &p.TypeMap)
+	} else {
+		p.TypeMap.T_GARBAGE_COLLECTION = -1
+		p.bindGarbageCollection = nil
+	}
+
+	if typeGCHeapSummary != nil && p.bindVirtualSpace != nil {
+		p.TypeMap.T_GC_HEAP_SUMMARY = typeGCHeapSummary.ID
+		p.bindGCHeapSummary = types2.NewBindGCHeapSummary(typeGCHeapSummary, &p.TypeMap)
+	} else {
+		p.TypeMap.T_GC_HEAP_SUMMARY = -1
+		p.bindGCHeapSummary = nil
+	}
+
+	if typeGCPhasePause != nil {
+		p.TypeMap.T_GC_PHASE_PAUSE = typeGCPhasePause.ID
+		p.bindGCPhasePause = types2.NewBindGCPhasePause(typeGCPhasePause, &p.TypeMap)
+	} else {
+		p.TypeMap.T_GC_PHASE_PAUSE = -1
+		p.bindGCPhasePause = nil
+	}
+
+	if typeYoungGC != nil {
+		p.TypeMap.T_YOUNG_GC = typeYoungGC.ID
+		p.bindYoungGC = types2.NewBindYoungGarbageCollection(typeYoungGC, &p.TypeMap)
+	} else {
+		p.TypeMap.T_YOUNG_GC = -1
+		p.bindYoungGC = nil
+	}
+
+	if typeOldGC != nil {
+		p.TypeMap.T_OLD_GC = typeOldGC.ID
+		p.bindOldGC = types2.NewBindOldGarbageCollection(typeOldGC, &p.TypeMap)
+	} else {
+		p.TypeMap.T_OLD_GC = -1
+		p.bindOldGC = nil
+	}
+
+	if typeCPULoad != nil {
+		p.TypeMap.T_CPU_LOAD = typeCPULoad.ID
+		p.bindCPULoad = types2.NewBindCPULoad(typeCPULoad, &p.TypeMap)
+	} else {
+		p.TypeMap.T_CPU_LOAD = -1
+		p.bindCPULoad = nil
+	}
+
+	if typeThreadCPULoad != nil {
+		p.TypeMap.T_THREAD_CPU_LOAD = typeThreadCPULoad.ID
+		p.bindThreadCPULoad = types2.NewBindThreadCPULoad(typeThreadCPULoad, &p.TypeMap)
+	} else {
+		p.TypeMap.T_THREAD_CPU_LOAD = -1
+		p.bindThreadCPULoad = nil
+	}
+
+	if typeJavaExceptionThrow != nil {
+		p.TypeMap.T_JAVA_EXCEPTION_THROW = typeJavaExceptionThrow.ID
+		p.bindJavaExceptionThrow = types2.NewBindJavaExceptionThrow(typeJavaExceptionThrow, &p.TypeMap)
+	} else {
+		p.TypeMap.T_JAVA_EXCEPTION_THROW = -1
+		p.bindJavaExceptionThrow = nil
+	}
+
+	if typeJavaErrorThrow != nil {
+		p.TypeMap.T_JAVA_ERROR_THROW = typeJavaErrorThrow.ID
+		p.bindJavaErrorThrow = types2.NewBindJavaErrorThrow(typeJavaErrorThrow, &p.TypeMap)
+	} else {
+		p.TypeMap.T_JAVA_ERROR_THROW = -1
+		p.bindJavaErrorThrow = nil
+	}
+
+	if typeExceptionStatistics != nil {
+		p.TypeMap.T_EXCEPTION_STATISTICS = typeExceptionStatistics.ID
+		p.bindExceptionStatistics = types2.NewBindExceptionStatistics(typeExceptionStatistics, &p.TypeMap)
+	} else {
+		p.TypeMap.T_EXCEPTION_STATISTICS = -1
+		p.bindExceptionStatistics = nil
+	}
+
+	if typeSocketRead != nil {
+		p.TypeMap.T_SOCKET_READ = typeSocketRead.ID
+		p.bindSocketRead = types2.NewBindSocketRead(typeSocketRead, &p.TypeMap)
+	} else {
+		p.TypeMap.T_SOCKET_READ = -1
+		p.bindSocketRead = nil
+	}
+
+	if typeSocketWrite != nil {
+		p.TypeMap.T_SOCKET_WRITE = typeSocketWrite.ID
+		p.bindSocketWrite = types2.NewBindSocketWrite(typeSocketWrite, &p.TypeMap)
+	} else {
+		p.TypeMap.T_SOCKET_WRITE = -1
+		p.bindSocketWrite = nil
+	}
+
+	if typeFileRead != nil {
+		p.TypeMap.T_FILE_READ = typeFileRead.ID
+		p.bindFileRead = types2.NewBindFileRead(typeFileRead, &p.TypeMap)
+	} else {
+		p.TypeMap.T_FILE_READ = -1
+		p.bindFileRead = nil
+	}
+
+	if typeFileWrite != nil {
+		p.TypeMap.T_FILE_WRITE = typeFileWrite.ID
+		p.bindFileWrite = types2.NewBindFileWrite(typeFileWrite, &p.TypeMap)
+	} else {
+		p.TypeMap.T_FILE_WRITE = -1
+		p.bindFileWrite = nil
+	}
+
+	if typeVirtualThreadPinned != nil {
+		p.TypeMap.T_VIRTUAL_THREAD_PINNED = typeVirtualThreadPinned.ID
+		p.bindVirtualThreadPinned = types2.NewBindVirtualThreadPinned(typeVirtualThreadPinned, &p.TypeMap)
+	} else {
+		p.TypeMap.T_VIRTUAL_THREAD_PINNED = -1
+		p.bindVirtualThreadPinned = nil
+	}
+
+	if typeVirtualThreadSubmitFailed != nil {
+		p.TypeMap.T_VIRTUAL_THREAD_SUBMIT_FAILED = typeVirtualThreadSubmitFailed.ID
+		p.bindVirtualThreadSubmitFailed = types2.NewBindVirtualThreadSubmitFailed(typeVirtualThreadSubmitFailed, &p.TypeMap)
+	} else {
+		p.TypeMap.T_VIRTUAL_THREAD_SUBMIT_FAILED = -1
+		p.bindVirtualThreadSubmitFailed = nil
+	}
+
+	if typeVirtualThreadStart != nil {
+		p.TypeMap.T_VIRTUAL_THREAD_START = typeVirtualThreadStart.ID
+		p.bindVirtualThreadStart = types2.NewBindVirtualThreadStart(typeVirtualThreadStart, &p.TypeMap)
+	} else {
+		p.TypeMap.T_VIRTUAL_THREAD_START = -1
+		p.bindVirtualThreadStart = nil
+	}
+
+	if typeVirtualThreadEnd != nil {
+		p.TypeMap.T_VIRTUAL_THREAD_END = typeVirtualThreadEnd.ID
+		p.bindVirtualThreadEnd = types2.NewBindVirtualThreadEnd(typeVirtualThreadEnd, &p.TypeMap)
+	} else {
+		p.TypeMap.T_VIRTUAL_THREAD_END = -1
+		p.bindVirtualThreadEnd = nil
+	}
+
+	if typeCompilation != nil {
+		p.TypeMap.T_COMPILATION = typeCompilation.ID
+		p.bindCompilation = types2.NewBindCompilation(typeCompilation, &p.TypeMap)
+	} else {
+		p.TypeMap.T_COMPILATION = -1
+		p.bindCompilation = nil
+	}
+
+	if typeCompilationFailure != nil {
+		p.TypeMap.T_COMPILATION_FAILURE = typeCompilationFailure.ID
+		p.bindCompilationFailure = types2.NewBindCompilationFailure(typeCompilationFailure, &p.TypeMap)
+	} else {
+		p.TypeMap.T_COMPILATION_FAILURE = -1
+		p.bindCompilationFailure = nil
+	}
+
+	if typeDeoptimization != nil {
+		p.TypeMap.T_DEOPTIMIZATION = typeDeoptimization.ID
+		p.bindDeoptimization = types2.NewBindDeoptimization(typeDeoptimization, &p.TypeMap)
+	} else {
+		p.TypeMap.T_DEOPTIMIZATION = -1
+		p.bindDeoptimization = nil
+	}
+
+	if typeSafepointBegin != nil {
+		p.TypeMap.T_SAFEPOINT_BEGIN = typeSafepointBegin.ID
+		p.bindSafepointBegin = types2.NewBindSafepointBegin(typeSafepointBegin, &p.TypeMap)
+	} else {
+		p.TypeMap.T_SAFEPOINT_BEGIN = -1
+		p.bindSafepointBegin = nil
+	}
+
+	if typeSafepointStateSynchronization != nil {
+		p.TypeMap.T_SAFEPOINT_STATE_SYNCHRONIZATION = typeSafepointStateSynchronization.ID
+		p.bindSafepointStateSynchronization = types2.NewBindSafepointStateSynchronization(typeSafepointStateSynchronization, &p.TypeMap)
+	} else {
+		p.TypeMap.T_SAFEPOINT_STATE_SYNCHRONIZATION = -1
+		p.bindSafepointStateSynchronization = nil
+	}
+
+	if typeSafepointEnd != nil {
+		p.TypeMap.T_SAFEPOINT_END = typeSafepointEnd.ID
+		p.bindSafepointEnd = types2.NewBindSafepointEnd(typeSafepointEnd, &p.TypeMap)
+	} else {
+		p.TypeMap.T_SAFEPOINT_END = -1
+		p.bindSafepointEnd = nil
+	}
+
+	if typeExecuteVMOperation != nil {
+		p.TypeMap.T_EXECUTE_VM_OPERATION = typeExecuteVMOperation.ID
+		p.bindExecuteVMOperation = types2.NewBindExecuteVMOperation(typeExecuteVMOperation, &p.TypeMap)
+	} else {
+		p.TypeMap.T_EXECUTE_VM_OPERATION = -1
+		p.bindExecuteVMOperation = nil
+	}
+
+	if typeJVMInformation != nil {
+		p.TypeMap.T_JVM_INFORMATION = typeJVMInformation.ID
+		p.bindJVMInformation = types2.NewBindJVMInformation(typeJVMInformation, &p.TypeMap)
+	} else {
+		p.TypeMap.T_JVM_INFORMATION = -1
+		p.bindJVMInformation = nil
+	}
+
+	if typeOSInformation != nil {
+		p.TypeMap.T_OS_INFORMATION = typeOSInformation.ID
+		p.bindOSInformation = types2.NewBindOSInformation(typeOSInformation, &p.TypeMap)
+	} else {
+		p.TypeMap.T_OS_INFORMATION = -1
+		p.bindOSInformation = nil
+	}
+
+	if typeCPUInformation != nil {
+		p.TypeMap.T_CPU_INFORMATION = typeCPUInformation.ID
+		p.bindCPUInformation = types2.NewBindCPUInformation(typeCPUInformation, &p.TypeMap)
+	} else {
+		p.TypeMap.T_CPU_INFORMATION = -1
+		p.bindCPUInformation = nil
+	}
+
+	if typeInitialSystemProperty != nil {
+		p.TypeMap.T_INITIAL_SYSTEM_PROPERTY = typeInitialSystemProperty.ID
+		p.bindInitialSystemProperty = types2.NewBindInitialSystemProperty(typeInitialSystemProperty, &p.TypeMap)
+	} else {
+		p.TypeMap.T_INITIAL_SYSTEM_PROPERTY = -1
+		p.bindInitialSystemProperty = nil
+	}
+
 	p.FrameTypes.Reset()
 	p.ThreadStates.Reset()
 	p.Threads.Reset()
@@ -665,5 +1849,14 @@ func (p *Parser) checkTypes() error {
 	p.LogLevels.Reset()
 	p.Stacktrace.Reset()
 	p.Strings.Reset()
+	p.GCNames.Reset()
+	p.GCCauses.Reset()
+	p.GCWhens.Reset()
+	p.ThreadGroups.Reset()
+	p.CompilerTypes.Reset()
+	p.DeoptimizationReasons.Reset()
+	p.DeoptimizationActions.Reset()
+	p.Bytecodes.Reset()
+	p.VMOperationTypes.Reset()
 	return nil
 }
@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"io"
+
+	"github.com/grafana/jfr-parser/parser/types/def"
+)
+
+// RecordingInfo describes the JVM, OS and CPU a recording was made on.
+// The JDK writes these events at the start of every chunk, later chunks
+// overwrite earlier values.
+type RecordingInfo struct {
+	JVM JVMInfo
+	// OS is the jdk.OSInformation osVersion, e.g. the contents of /etc/os-release and uname.
+	OS  string
+	CPU CPUInfo
+	// SystemProperties holds the jdk.InitialSystemProperty values, including -D flags.
+	SystemProperties map[string]string
+}
+
+type JVMInfo struct {
+	Name           string
+	Version        string
+	Arguments      string
+	Flags          string
+	JavaArguments  string
+	StartTimeNanos int64
+	PID            uint64
+}
+
+type CPUInfo struct {
+	CPU         string
+	Description string
+	Sockets     uint32
+	Cores       uint32
+	HWThreads   uint32
+}
+
+// ReadRecordingInfo reads the environment events of a recording.
+func ReadRecordingInfo(buf []byte) (*RecordingInfo, error) {
+	p := NewParser(buf, Options{})
+	info := &RecordingInfo{}
+	for {
+		typ, err := p.ParseEvent()
+		if err == io.EOF {
+			return info, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		info.Add(p, typ)
+	}
+}
+
+// Add records the current event of p if it is one of the environment events.
+// It reports whether the event was used, so callers already iterating over
+// events with ParseEvent can collect the info without a second pass.
+func (r *RecordingInfo) Add(p *Parser, typ def.TypeID) bool {
+	switch typ {
+	case p.TypeMap.T_JVM_INFORMATION:
+		e := &p.JVMInformation
+		r.JVM = JVMInfo{
+			Name:           e.JvmName,
+			Version:        e.JvmVersion,
+			Arguments:      e.JvmArguments,
+			Flags:          e.JvmFlags,
+			JavaArguments:  e.JavaArguments,
+			StartTimeNanos: p.TimestampNanos(typ, "jvmStartTime", int64(e.JvmStartTime)),
+			PID:            e.Pid,
+		}
+	case p.TypeMap.T_OS_INFORMATION:
+		r.OS = p.OSInformation.OsVersion
+	case p.TypeMap.T_CPU_INFORMATION:
+		e := &p.CPUInformation
+		r.CPU = CPUInfo{
+			CPU:         e.Cpu,
+			Description: e.Description,
+			Sockets:     e.Sockets,
+			Cores:       e.Cores,
+			HWThreads:   e.HwThreads,
+		}
+	case p.TypeMap.T_INITIAL_SYSTEM_PROPERTY:
+		if r.SystemProperties == nil {
+			r.SystemProperties = make(map[string]string)
+		}
+		r.SystemProperties[p.InitialSystemProperty.Key] = p.InitialSystemProperty.Value
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,23 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRecordingInfo(t *testing.T) {
+	info, err := ReadRecordingInfo(readGzipFile(t, "testdata/object-allocation-sample.jfr.gz"))
+	require.NoError(t, err)
+	assert.Equal(t, "OpenJDK 64-Bit Server VM", info.JVM.Name)
+	assert.Contains(t, info.JVM.Version, "17.0.2+8-86")
+	assert.Equal(t, "-javaagent:dd-java-agent.jar", info.JVM.Arguments)
+	assert.Equal(t, "sh.platform.App", info.JVM.JavaArguments)
+	assert.Equal(t, int64(1725624535155000000), info.JVM.StartTimeNanos)
+	assert.Equal(t, uint64(1), info.JVM.PID)
+	assert.Contains(t, info.OS, "Oracle Linux Server release 8.5")
+	assert.Equal(t, CPUInfo{CPU: info.CPU.CPU, Description: info.CPU.Description, Sockets: 1, Cores: 4, HWThreads: 8}, info.CPU)
+	assert.Contains(t, info.CPU.Description, "i7-10510U")
+	assert.Len(t, info.SystemProperties, 15)
+}
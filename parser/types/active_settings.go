@@ -164,6 +164,27 @@ func (this *ActiveSetting) Parse(data []byte, bind *BindActiveSetting, typeMap *
 						break
 					case 1:
 						break
+					case 2:
+						v64_ = 0
+						for shift = uint(0); shift <= 56; shift += 7 {
+							if pos >= l {
+								return 0, io.ErrUnexpectedEOF
+							}
+							b_ = data[pos]
+							pos++
+							if shift == 56 {
+								v64_ |= uint64(b_&0xFF) << shift
+								break
+							} else {
+								v64_ |= uint64(b_&0x7F) << shift
+								if b_ < 0x80 {
+									break
+								}
+							}
+						}
+						if typeMap.StringConstant != nil {
+							s_ = typeMap.StringConstant(v64_)
+						}
 					case 3:
 						v32_ = uint32(0)
 						for shift = uint(0); ; shift += 7 {
@@ -315,22 +336,17 @@ func (this *ActiveSetting) Parse(data []byte, bind *BindActiveSetting, typeMap *
 					pos++
 					// skipping
 				case typeMap.T_FLOAT:
-					v32_ = uint32(0)
-					for shift = uint(0); ; shift += 7 {
-						if shift >= 32 {
-							return 0, def.ErrIntOverflow
-						}
-						if pos >= l {
-							return 0, io.ErrUnexpectedEOF
-						}
-						b_ = data[pos]
-						pos++
-						v32_ |= uint32(b_&0x7F) << shift
-						if b_ < 0x80 {
-							break
-						}
+					if pos+4 > l {
+						return 0, io.ErrUnexpectedEOF
 					}
+					v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])
+					pos += 4
 					// skipping
+				case typeMap.T_DOUBLE:
+					if pos+8 > l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					pos += 8
 				default:
 					bindFieldType := typeMap.IDMap[bind.Fields[bindFieldIndex].Field.Type]
 					if bindFieldType == nil || len(bindFieldType.Fields) == 0 {
@@ -386,6 +402,27 @@ func (this *ActiveSetting) Parse(data []byte, bind *BindActiveSetting, typeMap *
 									break
 								case 1:
 									break
+								case 2:
+									v64_ = 0
+									for shift = uint(0); shift <= 56; shift += 7 {
+										if pos >= l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										b_ = data[pos]
+										pos++
+										if shift == 56 {
+											v64_ |= uint64(b_&0xFF) << shift
+											break
+										} else {
+											v64_ |= uint64(b_&0x7F) << shift
+											if b_ < 0x80 {
+												break
+											}
+										}
+									}
+									if typeMap.StringConstant != nil {
+										s_ = typeMap.StringConstant(v64_)
+									}
 								case 3:
 									v32_ = uint32(0)
 									for shift = uint(0); ; shift += 7 {
@@ -488,21 +525,16 @@ func (this *ActiveSetting) Parse(data []byte, bind *BindActiveSetting, typeMap *
 									}
 								}
 							} else if bindSkipFieldType == typeMap.T_FLOAT {
-								v32_ = uint32(0)
-								for shift = uint(0); ; shift += 7 {
-									if shift >= 32 {
-										return 0, def.ErrIntOverflow
-									}
-									if pos >= l {
-										return 0, io.ErrUnexpectedEOF
-									}
-									b_ = data[pos]
-									pos++
-									v32_ |= uint32(b_&0x7F) << shift
-									if b_ < 0x80 {
-										break
-									}
+								if pos+4 > l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])
+								pos += 4
+							} else if bindSkipFieldType == typeMap.T_DOUBLE {
+								if pos+8 > l {
+									return 0, io.ErrUnexpectedEOF
 								}
+								pos += 8
 							} else if bindSkipFieldType == typeMap.T_LONG {
 								v64_ = 0
 								for shift = uint(0); shift <= 56; shift += 7 {
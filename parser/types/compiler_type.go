@@ -0,0 +1,582 @@
+// Code generated by gen/main.go. DO NOT EDIT.
+
+package types
+
+import (
+	"fmt"
+	"github.com/grafana/jfr-parser/parser/types/def"
+	"io"
+	"unsafe"
+)
+
+type BindCompilerType struct {
+	Temp   CompilerType
+	Fields []BindFieldCompilerType
+}
+
+type BindFieldCompilerType struct {
+	Field  *def.Field
+	string *string
+}
+
+func NewBindCompilerType(typ *def.Class, typeMap *def.TypeMap) *BindCompilerType {
+	res := new(BindCompilerType)
+	res.Fields = make([]BindFieldCompilerType, 0, len(typ.Fields))
+	for i := 0; i < len(typ.Fields); i++ {
+		switch typ.Fields[i].Name {
+		case "compiler":
+			if typ.Fields[i].Equals(&def.Field{Name: "compiler", Type: typeMap.T_STRING, ConstantPool: false, Array: false}) {
+				res.Fields = append(res.Fields, BindFieldCompilerType{Field: &typ.Fields[i], string: &res.Temp.Compiler})
+			} else {
+				res.Fields = append(res.Fields, BindFieldCompilerType{Field: &typ.Fields[i]}) // skip changed field
+			}
+		default:
+			res.Fields = append(res.Fields, BindFieldCompilerType{Field: &typ.Fields[i]}) // skip unknown new field
+		}
+	}
+	return res
+}
+
+type CompilerTypeRef uint64
+type CompilerTypeList struct {
+	IDMap        map[CompilerTypeRef]uint32
+	CompilerType []CompilerType
+}
+
+type CompilerType struct {
+	Compiler string
+}
+
+func (this *CompilerTypeList) Reset() {
+	this.IDMap = make(map[CompilerTypeRef]uint32)
+	this.CompilerType = nil
+}
+func (this *CompilerTypeList) Parse(data []byte, bind *BindCompilerType, typeMap *def.TypeMap) (pos int, err error) {
+	var (
+		v64_  uint64
+		v32_  uint32
+		v16_  uint16
+		s_    string
+		b_    byte
+		shift = uint(0)
+		l     = len(data)
+	)
+	_ = v64_
+	_ = v32_
+	_ = v16_
+	_ = s_
+	v32_ = uint32(0)
+	for shift = uint(0); ; shift += 7 {
+		if shift >= 32 {
+			return 0, def.ErrIntOverflow
+		}
+		if pos >= l {
+			return 0, io.ErrUnexpectedEOF
+		}
+		b_ = data[pos]
+		pos++
+		v32_ |= uint32(b_&0x7F) << shift
+		if b_ < 0x80 {
+			break
+		}
+	}
+	n := int(v32_)
+	if this.CompilerType == nil {
+		this.CompilerType = make([]CompilerType, 0, max(n, 128))
+	}
+	for i := 0; i < n; i++ {
+		v64_ = 0
+		for shift = uint(0); shift <= 56; shift += 7 {
+			if pos >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b_ = data[pos]
+			pos++
+			if shift == 56 {
+				v64_ |= uint64(b_&0xFF) << shift
+				break
+			} else {
+				v64_ |= uint64(b_&0x7F) << shift
+				if b_ < 0x80 {
+					break
+				}
+			}
+		}
+		id := CompilerTypeRef(v64_)
+		for bindFieldIndex := 0; bindFieldIndex < len(bind.Fields); bindFieldIndex++ {
+			bindArraySize := 1
+			if bind.Fields[bindFieldIndex].Field.Array {
+				v32_ = uint32(0)
+				for shift = uint(0); ; shift += 7 {
+					if shift >= 32 {
+						return 0, def.ErrIntOverflow
+					}
+					if pos >= l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					b_ = data[pos]
+					pos++
+					v32_ |= uint32(b_&0x7F) << shift
+					if b_ < 0x80 {
+						break
+					}
+				}
+				bindArraySize = int(v32_)
+			}
+			for bindArrayIndex := 0; bindArrayIndex < bindArraySize; bindArrayIndex++ {
+				if bind.Fields[bindFieldIndex].Field.ConstantPool {
+					v64_ = 0
+					for shift = uint(0); shift <= 56; shift += 7 {
+						if pos >= l {
+							return 0, io.ErrUnexpectedEOF
+						}
+						b_ = data[pos]
+						pos++
+						if shift == 56 {
+							v64_ |= uint64(b_&0xFF) << shift
+							break
+						} else {
+							v64_ |= uint64(b_&0x7F) << shift
+							if b_ < 0x80 {
+								break
+							}
+						}
+					}
+				} else {
+					bindFieldTypeID := bind.Fields[bindFieldIndex].Field.Type
+					switch bindFieldTypeID {
+					case typeMap.T_STRING:
+						s_ = ""
+						if pos >= l {
+							return 0, io.ErrUnexpectedEOF
+						}
+						b_ = data[pos]
+						pos++
+						switch b_ {
+						case 0:
+							break
+						case 1:
+							break
+						case 2:
+							v64_ = 0
+							for shift = uint(0); shift <= 56; shift += 7 {
+								if pos >= l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								b_ = data[pos]
+								pos++
+								if shift == 56 {
+									v64_ |= uint64(b_&0xFF) << shift
+									break
+								} else {
+									v64_ |= uint64(b_&0x7F) << shift
+									if b_ < 0x80 {
+										break
+									}
+								}
+							}
+							if typeMap.StringConstant != nil {
+								s_ = typeMap.StringConstant(v64_)
+							}
+						case 3:
+							v32_ = uint32(0)
+							for shift = uint(0); ; shift += 7 {
+								if shift >= 32 {
+									return 0, def.ErrIntOverflow
+								}
+								if pos >= l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								b_ = data[pos]
+								pos++
+								v32_ |= uint32(b_&0x7F) << shift
+								if b_ < 0x80 {
+									break
+								}
+							}
+							if pos+int(v32_) > l {
+								return 0, io.ErrUnexpectedEOF
+							}
+							bs := data[pos : pos+int(v32_)]
+							s_ = *(*string)(unsafe.Pointer(&bs))
+							pos += int(v32_)
+						case 5:
+							v32_ = uint32(0)
+							for shift = uint(0); ; shift += 7 {
+								if shift >= 32 {
+									return 0, def.ErrIntOverflow
+								}
+								if pos >= l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								b_ = data[pos]
+								pos++
+								v32_ |= uint32(b_&0x7F) << shift
+								if b_ < 0x80 {
+									break
+								}
+							}
+							if pos+int(v32_) > l {
+								return 0, io.ErrUnexpectedEOF
+							}
+							bs := data[pos : pos+int(v32_)]
+							bs, _ = typeMap.ISO8859_1Decoder.Bytes(bs)
+							s_ = *(*string)(unsafe.Pointer(&bs))
+							pos += int(v32_)
+						case 4:
+							v32_ = uint32(0)
+							for shift = uint(0); ; shift += 7 {
+								if shift >= 32 {
+									return 0, def.ErrIntOverflow
+								}
+								if pos >= l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								b_ = data[pos]
+								pos++
+								v32_ |= uint32(b_&0x7F) << shift
+								if b_ < 0x80 {
+									break
+								}
+							}
+							bl := int(v32_)
+							buf := make([]rune, bl)
+							for i := 0; i < bl; i++ {
+								v32_ = uint32(0)
+								for shift = uint(0); ; shift += 7 {
+									if shift >= 32 {
+										return 0, def.ErrIntOverflow
+									}
+									if pos >= l {
+										return 0, io.ErrUnexpectedEOF
+									}
+									b_ = data[pos]
+									pos++
+									v32_ |= uint32(b_&0x7F) << shift
+									if b_ < 0x80 {
+										break
+									}
+								}
+								buf[i] = rune(v32_)
+							}
+							s_ = string(buf)
+						default:
+							return 0, fmt.Errorf("unknown string type %d at %d", b_, pos)
+						}
+						if bind.Fields[bindFieldIndex].string != nil {
+							*bind.Fields[bindFieldIndex].string = s_
+						}
+					case typeMap.T_INT:
+						v32_ = uint32(0)
+						for shift = uint(0); ; shift += 7 {
+							if shift >= 32 {
+								return 0, def.ErrIntOverflow
+							}
+							if pos >= l {
+								return 0, io.ErrUnexpectedEOF
+							}
+							b_ = data[pos]
+							pos++
+							v32_ |= uint32(b_&0x7F) << shift
+							if b_ < 0x80 {
+								break
+							}
+						}
+						// skipping
+					case typeMap.T_LONG:
+						v64_ = 0
+						for shift = uint(0); shift <= 56; shift += 7 {
+							if pos >= l {
+								return 0, io.ErrUnexpectedEOF
+							}
+							b_ = data[pos]
+							pos++
+							if shift == 56 {
+								v64_ |= uint64(b_&0xFF) << shift
+								break
+							} else {
+								v64_ |= uint64(b_&0x7F) << shift
+								if b_ < 0x80 {
+									break
+								}
+							}
+						}
+						// skipping
+					case typeMap.T_SHORT:
+						v16_ = uint16(0)
+						for shift = uint(0); ; shift += 7 {
+							if shift >= 16 {
+								return 0, def.ErrIntOverflow
+							}
+							if pos >= l {
+								return 0, io.ErrUnexpectedEOF
+							}
+							b_ = data[pos]
+							pos++
+							v16_ |= uint16(b_&0x7F) << shift
+							if b_ < 0x80 {
+								break
+							}
+						}
+						// skipping
+					case typeMap.T_BOOLEAN:
+						if pos >= l {
+							return 0, io.ErrUnexpectedEOF
+						}
+						b_ = data[pos]
+						pos++
+						// skipping
+					case typeMap.T_FLOAT:
+						if pos+4 > l {
+							return 0, io.ErrUnexpectedEOF
+						}
+						v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])
+						pos += 4
+						// skipping
+					case typeMap.T_DOUBLE:
+						if pos+8 > l {
+							return 0, io.ErrUnexpectedEOF
+						}
+						pos += 8
+					default:
+						bindFieldType := typeMap.IDMap[bind.Fields[bindFieldIndex].Field.Type]
+						if bindFieldType == nil || len(bindFieldType.Fields) == 0 {
+							return 0, fmt.Errorf("unknown type %d %+v", bind.Fields[bindFieldIndex].Field.Type, bindFieldType)
+						}
+						bindSkipObjects := 1
+						if bind.Fields[bindFieldIndex].Field.Array {
+							v32_ = uint32(0)
+							for shift = uint(0); ; shift += 7 {
+								if shift >= 32 {
+									return 0, def.ErrIntOverflow
+								}
+								if pos >= l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								b_ = data[pos]
+								pos++
+								v32_ |= uint32(b_&0x7F) << shift
+								if b_ < 0x80 {
+									break
+								}
+							}
+							bindSkipObjects = int(v32_)
+						}
+						for bindSkipObjectIndex := 0; bindSkipObjectIndex < bindSkipObjects; bindSkipObjectIndex++ {
+							for bindskipFieldIndex := 0; bindskipFieldIndex < len(bindFieldType.Fields); bindskipFieldIndex++ {
+								bindSkipFieldType := bindFieldType.Fields[bindskipFieldIndex].Type
+								if bindFieldType.Fields[bindskipFieldIndex].ConstantPool {
+									v32_ = uint32(0)
+									for shift = uint(0); ; shift += 7 {
+										if shift >= 32 {
+											return 0, def.ErrIntOverflow
+										}
+										if pos >= l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										b_ = data[pos]
+										pos++
+										v32_ |= uint32(b_&0x7F) << shift
+										if b_ < 0x80 {
+											break
+										}
+									}
+								} else if bindSkipFieldType == typeMap.T_STRING {
+									s_ = ""
+									if pos >= l {
+										return 0, io.ErrUnexpectedEOF
+									}
+									b_ = data[pos]
+									pos++
+									switch b_ {
+									case 0:
+										break
+									case 1:
+										break
+									case 2:
+										v64_ = 0
+										for shift = uint(0); shift <= 56; shift += 7 {
+											if pos >= l {
+												return 0, io.ErrUnexpectedEOF
+											}
+											b_ = data[pos]
+											pos++
+											if shift == 56 {
+												v64_ |= uint64(b_&0xFF) << shift
+												break
+											} else {
+												v64_ |= uint64(b_&0x7F) << shift
+												if b_ < 0x80 {
+													break
+												}
+											}
+										}
+										if typeMap.StringConstant != nil {
+											s_ = typeMap.StringConstant(v64_)
+										}
+									case 3:
+										v32_ = uint32(0)
+										for shift = uint(0); ; shift += 7 {
+											if shift >= 32 {
+												return 0, def.ErrIntOverflow
+											}
+											if pos >= l {
+												return 0, io.ErrUnexpectedEOF
+											}
+											b_ = data[pos]
+											pos++
+											v32_ |= uint32(b_&0x7F) << shift
+											if b_ < 0x80 {
+												break
+											}
+										}
+										if pos+int(v32_) > l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										bs := data[pos : pos+int(v32_)]
+										s_ = *(*string)(unsafe.Pointer(&bs))
+										pos += int(v32_)
+									case 5:
+										v32_ = uint32(0)
+										for shift = uint(0); ; shift += 7 {
+											if shift >= 32 {
+												return 0, def.ErrIntOverflow
+											}
+											if pos >= l {
+												return 0, io.ErrUnexpectedEOF
+											}
+											b_ = data[pos]
+											pos++
+											v32_ |= uint32(b_&0x7F) << shift
+											if b_ < 0x80 {
+												break
+											}
+										}
+										if pos+int(v32_) > l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										bs := data[pos : pos+int(v32_)]
+										bs, _ = typeMap.ISO8859_1Decoder.Bytes(bs)
+										s_ = *(*string)(unsafe.Pointer(&bs))
+										pos += int(v32_)
+									case 4:
+										v32_ = uint32(0)
+										for shift = uint(0); ; shift += 7 {
+											if shift >= 32 {
+												return 0, def.ErrIntOverflow
+											}
+											if pos >= l {
+												return 0, io.ErrUnexpectedEOF
+											}
+											b_ = data[pos]
+											pos++
+											v32_ |= uint32(b_&0x7F) << shift
+											if b_ < 0x80 {
+												break
+											}
+										}
+										bl := int(v32_)
+										buf := make([]rune, bl)
+										for i := 0; i < bl; i++ {
+											v32_ = uint32(0)
+											for shift = uint(0); ; shift += 7 {
+												if shift >= 32 {
+													return 0, def.ErrIntOverflow
+												}
+												if pos >= l {
+													return 0, io.ErrUnexpectedEOF
+												}
+												b_ = data[pos]
+												pos++
+												v32_ |= uint32(b_&0x7F) << shift
+												if b_ < 0x80 {
+													break
+												}
+											}
+											buf[i] = rune(v32_)
+										}
+										s_ = string(buf)
+									default:
+										return 0, fmt.Errorf("unknown string type %d at %d", b_, pos)
+									}
+								} else if bindSkipFieldType == typeMap.T_INT {
+									v32_ = uint32(0)
+									for shift = uint(0); ; shift += 7 {
+										if shift >= 32 {
+											return 0, def.ErrIntOverflow
+										}
+										if pos >= l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										b_ = data[pos]
+										pos++
+										v32_ |= uint32(b_&0x7F) << shift
+										if b_ < 0x80 {
+											break
+										}
+									}
+								} else if bindSkipFieldType == typeMap.T_FLOAT {
+									if pos+4 > l {
+										return 0, io.ErrUnexpectedEOF
+									}
+									v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])
+									pos += 4
+								} else if bindSkipFieldType == typeMap.T_DOUBLE {
+									if pos+8 > l {
+										return 0, io.ErrUnexpectedEOF
+									}
+									pos += 8
+								} else if bindSkipFieldType == typeMap.T_LONG {
+									v64_ = 0
+									for shift = uint(0); shift <= 56; shift += 7 {
+										if pos >= l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										b_ = data[pos]
+										pos++
+										if shift == 56 {
+											v64_ |= uint64(b_&0xFF) << shift
+											break
+										} else {
+											v64_ |= uint64(b_&0x7F) << shift
+											if b_ < 0x80 {
+												break
+											}
+										}
+									}
+								} else if bindSkipFieldType == typeMap.T_SHORT {
+									v16_ = uint16(0)
+									for shift = uint(0); ; shift += 7 {
+										if shift >= 16 {
+											return 0, def.ErrIntOverflow
+										}
+										if pos >= l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										b_ = data[pos]
+										pos++
+										v16_ |= uint16(b_&0x7F) << shift
+										if b_ < 0x80 {
+											break
+										}
+									}
+								} else if bindSkipFieldType == typeMap.T_BOOLEAN {
+									if pos >= l {
+										return 0, io.ErrUnexpectedEOF
+									}
+									b_ = data[pos]
+									pos++
+								} else {
+									return 0, fmt.Errorf("nested objects not implemented. ")
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+		this.CompilerType = append(this.CompilerType, bind.Temp)
+		this.IDMap[id] = uint32(len(this.CompilerType) - 1)
+	}
+	return pos, nil
+}
@@ -3,15 +3,46 @@ package def
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 var ErrIntOverflow = fmt.Errorf("int overflow")
 var ErrNameEmpty = fmt.Errorf("class/field name is empty")
 
+const (
+	AnnotationLabel        = "jdk.jfr.Label"
+	AnnotationDescription  = "jdk.jfr.Description"
+	AnnotationCategory     = "jdk.jfr.Category"
+	AnnotationExperimental = "jdk.jfr.Experimental"
+	AnnotationContentType  = "jdk.jfr.ContentType"
+
+	ContentTypeTimespan      = "jdk.jfr.Timespan"
+	ContentTypeTimestamp     = "jdk.jfr.Timestamp"
+	ContentTypeDataAmount    = "jdk.jfr.DataAmount"
+	ContentTypeFrequency     = "jdk.jfr.Frequency"
+	ContentTypePercentage    = "jdk.jfr.Percentage"
+	ContentTypeMemoryAddress = "jdk.jfr.MemoryAddress"
+	ContentTypeUnsigned      = "jdk.jfr.Unsigned"
+
+	UnitTicks                  = "TICKS"
+	UnitNanoseconds            = "NANOSECONDS"
+	UnitMicroseconds           = "MICROSECONDS"
+	UnitMilliseconds           = "MILLISECONDS"
+	UnitSeconds                = "SECONDS"
+	UnitMillisecondsSinceEpoch = "MILLISECONDS_SINCE_EPOCH"
+	UnitBytes                  = "BYTES"
+	UnitBits                   = "BITS"
+	UnitHertz                  = "HERTZ"
+)
+
 type Class struct {
-	Name   string
-	ID     TypeID
-	Fields []Field
+	Name        string
+	ID          TypeID
+	SuperType   string
+	SimpleType  bool
+	Fields      []Field
+	Settings    []Setting
+	Annotations Annotations
 }
 
 func NewClass(attrs map[string]string, childCount int) (*Class, error) {
@@ -24,9 +55,11 @@ func NewClass(attrs map[string]string, childCount int) (*Class, error) {
 		return nil, ErrNameEmpty
 	}
 	return &Class{
-		Name:   name,
-		ID:     TypeID(id),
-		Fields: make([]Field, 0, childCount),
+		Name:       name,
+		ID:         TypeID(id),
+		SuperType:  attrs["superType"],
+		SimpleType: attrs["simpleType"] == "true",
+		Fields:     make([]Field, 0, childCount),
 	}, nil
 }
 
@@ -59,6 +92,8 @@ type Field struct {
 	Type         TypeID
 	ConstantPool bool
 	Array        bool
+	Annotations  Annotations
+	Unit         Unit
 }
 
 func (f *Field) Equals(other *Field) bool {
@@ -99,3 +134,186 @@ func NewField(attrs map[string]string) (Field, error) {
 		Array:        array,
 	}, nil
 }
+
+// IsContentType reports whether name is one of the content type annotations declared by jdk.jfr.
+func IsContentType(name string) bool {
+	switch name {
+	case ContentTypeTimespan, ContentTypeTimestamp, ContentTypeDataAmount, ContentTypeFrequency,
+		ContentTypePercentage, ContentTypeMemoryAddress, ContentTypeUnsigned:
+		return true
+	}
+	return false
+}
+
+// Unit is the measurement of a field value derived from its content type annotation,
+// e.g. {ContentType: jdk.jfr.Timespan, Value: TICKS}. The zero value means a plain number.
+type Unit struct {
+	ContentType string
+	Value       string
+	Unsigned    bool
+}
+
+// NewUnit picks the content type out of a field's annotations, filling in the
+// jdk.jfr defaults for annotations declared without a value.
+func NewUnit(as Annotations) Unit {
+	u := Unit{}
+	for i := range as {
+		if !as[i].ContentType {
+			continue
+		}
+		if as[i].Name == ContentTypeUnsigned {
+			u.Unsigned = true
+			continue
+		}
+		if u.ContentType == "" {
+			u.ContentType = as[i].Name
+			u.Value = as[i].Value()
+		}
+	}
+	if u.Value == "" {
+		switch u.ContentType {
+		case ContentTypeTimespan:
+			u.Value = UnitNanoseconds
+		case ContentTypeTimestamp:
+			u.Value = UnitMillisecondsSinceEpoch
+		case ContentTypeDataAmount:
+			u.Value = UnitBytes
+		}
+	}
+	return u
+}
+
+// Bytes converts a jdk.jfr.DataAmount value to bytes.
+func (u Unit) Bytes(v int64) int64 {
+	if u.Value == UnitBits {
+		return v / 8
+	}
+	return v
+}
+
+type Setting struct {
+	Name         string
+	Type         TypeID
+	DefaultValue string
+	Annotations  Annotations
+}
+
+func NewSetting(attrs map[string]string) (Setting, error) {
+	typ, err := strconv.Atoi(attrs["class"])
+	if err != nil {
+		return Setting{}, err
+	}
+	name := attrs["name"]
+	if name == "" {
+		return Setting{}, ErrNameEmpty
+	}
+	return Setting{
+		Name:         name,
+		Type:         TypeID(typ),
+		DefaultValue: attrs["defaultValue"],
+	}, nil
+}
+
+type Annotation struct {
+	Type TypeID
+	// Name is the class name of the annotation type, resolved once all classes of the chunk are read.
+	Name string
+	// ContentType is true if the annotation type is itself annotated with jdk.jfr.ContentType,
+	// e.g. jdk.jfr.Timespan or jdk.jfr.DataAmount.
+	ContentType bool
+	// Values holds "value" or, for array values, "value-0", "value-1", ... in order.
+	Values []string
+}
+
+func NewAnnotation(attrs map[string]string) (Annotation, error) {
+	a, _, err := AppendAnnotation(nil, attrs)
+	return a, err
+}
+
+// AppendAnnotation is NewAnnotation appending the annotation values to values, which lets
+// the annotations of a metadata event share their allocations. It returns the extended values.
+func AppendAnnotation(values []string, attrs map[string]string) (Annotation, []string, error) {
+	typ, err := strconv.Atoi(attrs["class"])
+	if err != nil {
+		return Annotation{}, values, err
+	}
+	a := Annotation{Type: TypeID(typ)}
+	start := len(values)
+	if v, ok := attrs["value"]; ok {
+		values = append(values, v)
+	} else if len(attrs) > 1 {
+		for i := 0; ; i++ {
+			v, ok := attrs["value-"+strconv.Itoa(i)]
+			if !ok {
+				break
+			}
+			values = append(values, v)
+		}
+	}
+	if len(values) > start {
+		a.Values = values[start:len(values):len(values)]
+	}
+	return a, values, nil
+}
+
+func (a *Annotation) Value() string {
+	if len(a.Values) == 0 {
+		return ""
+	}
+	return a.Values[0]
+}
+
+type Annotations []Annotation
+
+func (as Annotations) Get(name string) *Annotation {
+	for i := range as {
+		if as[i].Name == name {
+			return &as[i]
+		}
+	}
+	return nil
+}
+
+func (as Annotations) Label() string {
+	return as.value(AnnotationLabel)
+}
+
+func (as Annotations) Description() string {
+	return as.value(AnnotationDescription)
+}
+
+func (as Annotations) Category() []string {
+	if a := as.Get(AnnotationCategory); a != nil {
+		return a.Values
+	}
+	return nil
+}
+
+func (as Annotations) Experimental() bool {
+	return as.Get(AnnotationExperimental) != nil
+}
+
+// ContentType returns the content type annotation such as jdk.jfr.Timespan, or nil.
+func (as Annotations) ContentType() *Annotation {
+	for i := range as {
+		if as[i].ContentType {
+			return &as[i]
+		}
+	}
+	return nil
+}
+
+func (as Annotations) String() string {
+	res := make([]string, 0, len(as))
+	for i := range as {
+		res = append(res, fmt.Sprintf("@%s(%s)", as[i].Name, strings.Join(as[i].Values, ", ")))
+	}
+	return strings.Join(res, " ")
+}
+
+func (as Annotations) value(name string) string {
+	if a := as.Get(name); a != nil {
+		return a.Value()
+	}
+	return ""
+}
@@ -7,6 +7,7 @@ type TypeID int64
 type TypeMap struct {
 	IDMap   map[TypeID]*Class
 	NameMap map[string]*Class
+	Region  Region
 
 	T_STRING  TypeID
 	T_INT     TypeID
@@ -14,6 +15,9 @@ type TypeMap struct {
 	T_SHORT   TypeID
 	T_FLOAT   TypeID
 	T_BOOLEAN TypeID
+	T_BYTE    TypeID
+	T_CHAR    TypeID
+	T_DOUBLE  TypeID
 
 	T_CLASS        TypeID
 	T_THREAD       TypeID
@@ -25,8 +29,19 @@ type TypeMap struct {
 	T_SYMBOL       TypeID
 	T_LOG_LEVEL    TypeID
 
-	T_STACK_FRAME  TypeID
-	T_CLASS_LOADER TypeID
+	T_STACK_FRAME           TypeID
+	T_CLASS_LOADER          TypeID
+	T_THREAD_GROUP          TypeID
+	T_COMPILER_TYPE         TypeID
+	T_DEOPTIMIZATION_REASON TypeID
+	T_DEOPTIMIZATION_ACTION TypeID
+	T_BYTECODE              TypeID
+	T_VM_OPERATION_TYPE     TypeID
+
+	T_VIRTUAL_SPACE TypeID
+	T_GC_NAME       TypeID
+	T_GC_CAUSE      TypeID
+	T_GC_WHEN       TypeID
 
 	T_EXECUTION_SAMPLE   TypeID
 	T_WALL_CLOCK_SAMPLE  TypeID
@@ -40,5 +55,48 @@ type TypeMap struct {
 	T_MALLOC             TypeID
 	T_FREE               TypeID
 
+	T_CPU_TIME_SAMPLE       TypeID
+	T_CPU_TIME_SAMPLES_LOST TypeID
+	T_NATIVE_METHOD_SAMPLE  TypeID
+
+	T_GARBAGE_COLLECTION TypeID
+	T_GC_HEAP_SUMMARY    TypeID
+	T_GC_PHASE_PAUSE     TypeID
+	T_YOUNG_GC           TypeID
+	T_OLD_GC             TypeID
+
+	T_CPU_LOAD                        TypeID
+	T_THREAD_CPU_LOAD                 TypeID
+	T_JAVA_EXCEPTION_THROW            TypeID
+	T_JAVA_ERROR_THROW                TypeID
+	T_EXCEPTION_STATISTICS            TypeID
+	T_SOCKET_READ                     TypeID
+	T_SOCKET_WRITE                    TypeID
+	T_FILE_READ                       TypeID
+	T_FILE_WRITE                      TypeID
+	T_VIRTUAL_THREAD_PINNED           TypeID
+	T_VIRTUAL_THREAD_SUBMIT_FAILED    TypeID
+	T_VIRTUAL_THREAD_START            TypeID
+	T_VIRTUAL_THREAD_END              TypeID
+	T_COMPILATION                     TypeID
+	T_COMPILATION_FAILURE             TypeID
+	T_DEOPTIMIZATION                  TypeID
+	T_SAFEPOINT_BEGIN                 TypeID
+	T_SAFEPOINT_STATE_SYNCHRONIZATION TypeID
+	T_SAFEPOINT_END                   TypeID
+	T_EXECUTE_VM_OPERATION            TypeID
+	T_JVM_INFORMATION                 TypeID
+	T_OS_INFORMATION                  TypeID
+	T_CPU_INFORMATION                 TypeID
+	T_INITIAL_SYSTEM_PROPERTY         TypeID
+
 	ISO8859_1Decoder *encoding.Decoder
+	// StringConstant resolves strings stored as java.lang.String constant pool references.
+	StringConstant func(id uint64) string
+}
+
+type Region struct {
+	Locale string
+	// GMTOffset is the recording's time zone offset in milliseconds.
+	GMTOffset int64
 }
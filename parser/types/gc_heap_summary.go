@@ -0,0 +1,1047 @@
+// Code generated by gen/main.go. DO NOT EDIT.
+
+package types
+
+import (
+	"fmt"
+	"github.com/grafana/jfr-parser/parser/types/def"
+	"io"
+	"unsafe"
+)
+
+type BindGCHeapSummary struct {
+	Temp   GCHeapSummary
+	Fields []BindFieldGCHeapSummary
+}
+
+type BindFieldGCHeapSummary struct {
+	Field        *def.Field
+	uint64       *uint64
+	uint32       *uint32
+	GCWhenRef    *GCWhenRef
+	VirtualSpace *VirtualSpace
+}
+
+func NewBindGCHeapSummary(typ *def.Class, typeMap *def.TypeMap) *BindGCHeapSummary {
+	res := new(BindGCHeapSummary)
+	res.Fields = make([]BindFieldGCHeapSummary, 0, len(typ.Fields))
+	for i := 0; i < len(typ.Fields); i++ {
+		switch typ.Fields[i].Name {
+		case "startTime":
+			if typ.Fields[i].Equals(&def.Field{Name: "startTime", Type: typeMap.T_LONG, ConstantPool: false, Array: false}) {
+				res.Fields = append(res.Fields, BindFieldGCHeapSummary{Field: &typ.Fields[i], uint64: &res.Temp.StartTime})
+			} else {
+				res.Fields = append(res.Fields, BindFieldGCHeapSummary{Field: &typ.Fields[i]}) // skip changed field
+			}
+		case "gcId":
+			if typ.Fields[i].Equals(&def.Field{Name: "gcId", Type: typeMap.T_INT, ConstantPool: false, Array: false}) {
+				res.Fields = append(res.Fields, BindFieldGCHeapSummary{Field: &typ.Fields[i], uint32: &res.Temp.GcId})
+			} else {
+				res.Fields = append(res.Fields, BindFieldGCHeapSummary{Field: &typ.Fields[i]}) // skip changed field
+			}
+		case "when":
+			if typ.Fields[i].Equals(&def.Field{Name: "when", Type: typeMap.T_GC_WHEN, ConstantPool: true, Array: false}) {
+				res.Fields = append(res.Fields, BindFieldGCHeapSummary{Field: &typ.Fields[i], GCWhenRef: &res.Temp.When})
+			} else {
+				res.Fields = append(res.Fields, BindFieldGCHeapSummary{Field: &typ.Fields[i]}) // skip changed field
+			}
+		case "heapSpace":
+			if typ.Fields[i].Equals(&def.Field{Name: "heapSpace", Type: typeMap.T_VIRTUAL_SPACE, ConstantPool: false, Array: false}) {
+				res.Fields = append(res.Fields, BindFieldGCHeapSummary{Field: &typ.Fields[i], VirtualSpace: &res.Temp.HeapSpace})
+			} else {
+				res.Fields = append(res.Fields, BindFieldGCHeapSummary{Field: &typ.Fields[i]}) // skip changed field
+			}
+		case "heapUsed":
+			if typ.Fields[i].Equals(&def.Field{Name: "heapUsed", Type: typeMap.T_LONG, ConstantPool: false, Array: false}) {
+				res.Fields = append(res.Fields, BindFieldGCHeapSummary{Field: &typ.Fields[i], uint64: &res.Temp.HeapUsed})
+			} else {
+				res.Fields = append(res.Fields, BindFieldGCHeapSummary{Field: &typ.Fields[i]}) // skip changed field
+			}
+		default:
+			res.Fields = append(res.Fields, BindFieldGCHeapSummary{Field: &typ.Fields[i]}) // skip unknown new field
+		}
+	}
+	return res
+}
+
+type GCHeapSummary struct {
+	StartTime uint64
+	GcId      uint32
+	When      GCWhenRef
+	HeapSpace VirtualSpace
+	HeapUsed  uint64
+}
+
+func (this *GCHeapSummary) Parse(data []byte, bind *BindGCHeapSummary, bindVirtualSpace *BindVirtualSpace, typeMap *def.TypeMap) (pos int, err error) {
+	var (
+		v64_  uint64
+		v32_  uint32
+		v16_  uint16
+		s_    string
+		b_    byte
+		shift = uint(0)
+		l     = len(data)
+	)
+	_ = v64_
+	_ = v32_
+	_ = v16_
+	_ = s_
+	for bindFieldIndex := 0; bindFieldIndex < len(bind.Fields); bindFieldIndex++ {
+		bindArraySize := 1
+		if bind.Fields[bindFieldIndex].Field.Array {
+			v32_ = uint32(0)
+			for shift = uint(0); ; shift += 7 {
+				if shift >= 32 {
+					return 0, def.ErrIntOverflow
+				}
+				if pos >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b_ = data[pos]
+				pos++
+				v32_ |= uint32(b_&0x7F) << shift
+				if b_ < 0x80 {
+					break
+				}
+			}
+			bindArraySize = int(v32_)
+		}
+		for bindArrayIndex := 0; bindArrayIndex < bindArraySize; bindArrayIndex++ {
+			if bind.Fields[bindFieldIndex].Field.ConstantPool {
+				v64_ = 0
+				for shift = uint(0); shift <= 56; shift += 7 {
+					if pos >= l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					b_ = data[pos]
+					pos++
+					if shift == 56 {
+						v64_ |= uint64(b_&0xFF) << shift
+						break
+					} else {
+						v64_ |= uint64(b_&0x7F) << shift
+						if b_ < 0x80 {
+							break
+						}
+					}
+				}
+				switch bind.Fields[bindFieldIndex].Field.Type {
+				case typeMap.T_GC_WHEN:
+					if bind.Fields[bindFieldIndex].GCWhenRef != nil {
+						*bind.Fields[bindFieldIndex].GCWhenRef = GCWhenRef(v64_)
+					}
+				}
+			} else {
+				bindFieldTypeID := bind.Fields[bindFieldIndex].Field.Type
+				switch bindFieldTypeID {
+				case typeMap.T_STRING:
+					s_ = ""
+					if pos >= l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					b_ = data[pos]
+					pos++
+					switch b_ {
+					case 0:
+						break
+					case 1:
+						break
+					case 2:
+						v64_ = 0
+						for shift = uint(0); shift <= 56; shift += 7 {
+							if pos >= l {
+								return 0, io.ErrUnexpectedEOF
+							}
+							b_ = data[pos]
+							pos++
+							if shift == 56 {
+								v64_ |= uint64(b_&0xFF) << shift
+								break
+							} else {
+								v64_ |= uint64(b_&0x7F) << shift
+								if b_ < 0x80 {
+									break
+								}
+							}
+						}
+						if typeMap.StringConstant != nil {
+							s_ = typeMap.StringConstant(v64_)
+						}
+					case 3:
+						v32_ = uint32(0)
+						for shift = uint(0); ; shift += 7 {
+							if shift >= 32 {
+								return 0, def.ErrIntOverflow
+							}
+							if pos >= l {
+								return 0, io.ErrUnexpectedEOF
+							}
+							b_ = data[pos]
+							pos++
+							v32_ |= uint32(b_&0x7F) << shift
+							if b_ < 0x80 {
+								break
+							}
+						}
+						if pos+int(v32_) > l {
+							return 0, io.ErrUnexpectedEOF
+						}
+						bs := data[pos : pos+int(v32_)]
+						s_ = *(*string)(unsafe.Pointer(&bs))
+						pos += int(v32_)
+					case 5:
+						v32_ = uint32(0)
+						for shift = uint(0); ; shift += 7 {
+							if shift >= 32 {
+								return 0, def.ErrIntOverflow
+							}
+							if pos >= l {
+								return 0, io.ErrUnexpectedEOF
+							}
+							b_ = data[pos]
+							pos++
+							v32_ |= uint32(b_&0x7F) << shift
+							if b_ < 0x80 {
+								break
+							}
+						}
+						if pos+int(v32_) > l {
+							return 0, io.ErrUnexpectedEOF
+						}
+						bs := data[pos : pos+int(v32_)]
+						bs, _ = typeMap.ISO8859_1Decoder.Bytes(bs)
+						s_ = *(*string)(unsafe.Pointer(&bs))
+						pos += int(v32_)
+					case 4:
+						v32_ = uint32(0)
+						for shift = uint(0); ; shift += 7 {
+							if shift >= 32 {
+								return 0, def.ErrIntOverflow
+							}
+							if pos >= l {
+								return 0, io.ErrUnexpectedEOF
+							}
+							b_ = data[pos]
+							pos++
+							v32_ |= uint32(b_&0x7F) << shift
+							if b_ < 0x80 {
+								break
+							}
+						}
+						bl := int(v32_)
+						buf := make([]rune, bl)
+						for i := 0; i < bl; i++ {
+							v32_ = uint32(0)
+							for shift = uint(0); ; shift += 7 {
+								if shift >= 32 {
+									return 0, def.ErrIntOverflow
+								}
+								if pos >= l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								b_ = data[pos]
+								pos++
+								v32_ |= uint32(b_&0x7F) << shift
+								if b_ < 0x80 {
+									break
+								}
+							}
+							buf[i] = rune(v32_)
+						}
+						s_ = string(buf)
+					default:
+						return 0, fmt.Errorf("unknown string type %d at %d", b_, pos)
+					}
+					// skipping
+				case typeMap.T_INT:
+					v32_ = uint32(0)
+					for shift = uint(0); ; shift += 7 {
+						if shift >= 32 {
+							return 0, def.ErrIntOverflow
+						}
+						if pos >= l {
+							return 0, io.ErrUnexpectedEOF
+						}
+						b_ = data[pos]
+						pos++
+						v32_ |= uint32(b_&0x7F) << shift
+						if b_ < 0x80 {
+							break
+						}
+					}
+					if bind.Fields[bindFieldIndex].uint32 != nil {
+						*bind.Fields[bindFieldIndex].uint32 = v32_
+					}
+				case typeMap.T_LONG:
+					v64_ = 0
+					for shift = uint(0); shift <= 56; shift += 7 {
+						if pos >= l {
+							return 0, io.ErrUnexpectedEOF
+						}
+						b_ = data[pos]
+						pos++
+						if shift == 56 {
+							v64_ |= uint64(b_&0xFF) << shift
+							break
+						} else {
+							v64_ |= uint64(b_&0x7F) << shift
+							if b_ < 0x80 {
+								break
+							}
+						}
+					}
+					if bind.Fields[bindFieldIndex].uint64 != nil {
+						*bind.Fields[bindFieldIndex].uint64 = v64_
+					}
+				case typeMap.T_SHORT:
+					v16_ = uint16(0)
+					for shift = uint(0); ; shift += 7 {
+						if shift >= 16 {
+							return 0, def.ErrIntOverflow
+						}
+						if pos >= l {
+							return 0, io.ErrUnexpectedEOF
+						}
+						b_ = data[pos]
+						pos++
+						v16_ |= uint16(b_&0x7F) << shift
+						if b_ < 0x80 {
+							break
+						}
+					}
+					// skipping
+				case typeMap.T_BOOLEAN:
+					if pos >= l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					b_ = data[pos]
+					pos++
+					// skipping
+				case typeMap.T_FLOAT:
+					if pos+4 > l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])
+					pos += 4
+					// skipping
+				case typeMap.T_DOUBLE:
+					if pos+8 > l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					pos += 8
+				case typeMap.T_VIRTUAL_SPACE:
+					for bindVirtualSpaceFieldIndex := 0; bindVirtualSpaceFieldIndex < len(bindVirtualSpace.Fields); bindVirtualSpaceFieldIndex++ {
+						bindVirtualSpaceArraySize := 1
+						if bindVirtualSpace.Fields[bindVirtualSpaceFieldIndex].Field.Array {
+							v32_ = uint32(0)
+							for shift = uint(0); ; shift += 7 {
+								if shift >= 32 {
+									return 0, def.ErrIntOverflow
+								}
+								if pos >= l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								b_ = data[pos]
+								pos++
+								v32_ |= uint32(b_&0x7F) << shift
+								if b_ < 0x80 {
+									break
+								}
+							}
+							bindVirtualSpaceArraySize = int(v32_)
+						}
+						for bindVirtualSpaceArrayIndex := 0; bindVirtualSpaceArrayIndex < bindVirtualSpaceArraySize; bindVirtualSpaceArrayIndex++ {
+							if bindVirtualSpace.Fields[bindVirtualSpaceFieldIndex].Field.ConstantPool {
+								v64_ = 0
+								for shift = uint(0); shift <= 56; shift += 7 {
+									if pos >= l {
+										return 0, io.ErrUnexpectedEOF
+									}
+									b_ = data[pos]
+									pos++
+									if shift == 56 {
+										v64_ |= uint64(b_&0xFF) << shift
+										break
+									} else {
+										v64_ |= uint64(b_&0x7F) << shift
+										if b_ < 0x80 {
+											break
+										}
+									}
+								}
+							} else {
+								bindVirtualSpaceFieldTypeID := bindVirtualSpace.Fields[bindVirtualSpaceFieldIndex].Field.Type
+								switch bindVirtualSpaceFieldTypeID {
+								case typeMap.T_STRING:
+									s_ = ""
+									if pos >= l {
+										return 0, io.ErrUnexpectedEOF
+									}
+									b_ = data[pos]
+									pos++
+									switch b_ {
+									case 0:
+										break
+									case 1:
+										break
+									case 2:
+										v64_ = 0
+										for shift = uint(0); shift <= 56; shift += 7 {
+											if pos >= l {
+												return 0, io.ErrUnexpectedEOF
+											}
+											b_ = data[pos]
+											pos++
+											if shift == 56 {
+												v64_ |= uint64(b_&0xFF) << shift
+												break
+											} else {
+												v64_ |= uint64(b_&0x7F) << shift
+												if b_ < 0x80 {
+													break
+												}
+											}
+										}
+										if typeMap.StringConstant != nil {
+											s_ = typeMap.StringConstant(v64_)
+										}
+									case 3:
+										v32_ = uint32(0)
+										for shift = uint(0); ; shift += 7 {
+											if shift >= 32 {
+												return 0, def.ErrIntOverflow
+											}
+											if pos >= l {
+												return 0, io.ErrUnexpectedEOF
+											}
+											b_ = data[pos]
+											pos++
+											v32_ |= uint32(b_&0x7F) << shift
+											if b_ < 0x80 {
+												break
+											}
+										}
+										if pos+int(v32_) > l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										bs := data[pos : pos+int(v32_)]
+										s_ = *(*string)(unsafe.Pointer(&bs))
+										pos += int(v32_)
+									case 5:
+										v32_ = uint32(0)
+										for shift = uint(0); ; shift += 7 {
+											if shift >= 32 {
+												return 0, def.ErrIntOverflow
+											}
+											if pos >= l {
+												return 0, io.ErrUnexpectedEOF
+											}
+											b_ = data[pos]
+											pos++
+											v32_ |= uint32(b_&0x7F) << shift
+											if b_ < 0x80 {
+												break
+											}
+										}
+										if pos+int(v32_) > l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										bs := data[pos : pos+int(v32_)]
+										bs, _ = typeMap.ISO8859_1Decoder.Bytes(bs)
+										s_ = *(*string)(unsafe.Pointer(&bs))
+										pos += int(v32_)
+									case 4:
+										v32_ = uint32(0)
+										for shift = uint(0); ; shift += 7 {
+											if shift >= 32 {
+												return 0, def.ErrIntOverflow
+											}
+											if pos >= l {
+												return 0, io.ErrUnexpectedEOF
+											}
+											b_ = data[pos]
+											pos++
+											v32_ |= uint32(b_&0x7F) << shift
+											if b_ < 0x80 {
+												break
+											}
+										}
+										bl := int(v32_)
+										buf := make([]rune, bl)
+										for i := 0; i < bl; i++ {
+											v32_ = uint32(0)
+											for shift = uint(0); ; shift += 7 {
+												if shift >= 32 {
+													return 0, def.ErrIntOverflow
+												}
+												if pos >= l {
+													return 0, io.ErrUnexpectedEOF
+												}
+												b_ = data[pos]
+												pos++
+												v32_ |= uint32(b_&0x7F) << shift
+												if b_ < 0x80 {
+													break
+												}
+											}
+											buf[i] = rune(v32_)
+										}
+										s_ = string(buf)
+									default:
+										return 0, fmt.Errorf("unknown string type %d at %d", b_, pos)
+									}
+									// skipping
+								case typeMap.T_INT:
+									v32_ = uint32(0)
+									for shift = uint(0); ; shift += 7 {
+										if shift >= 32 {
+											return 0, def.ErrIntOverflow
+										}
+										if pos >= l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										b_ = data[pos]
+										pos++
+										v32_ |= uint32(b_&0x7F) << shift
+										if b_ < 0x80 {
+											break
+										}
+									}
+									// skipping
+								case typeMap.T_LONG:
+									v64_ = 0
+									for shift = uint(0); shift <= 56; shift += 7 {
+										if pos >= l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										b_ = data[pos]
+										pos++
+										if shift == 56 {
+											v64_ |= uint64(b_&0xFF) << shift
+											break
+										} else {
+											v64_ |= uint64(b_&0x7F) << shift
+											if b_ < 0x80 {
+												break
+											}
+										}
+									}
+									if bindVirtualSpace.Fields[bindVirtualSpaceFieldIndex].uint64 != nil {
+										*bindVirtualSpace.Fields[bindVirtualSpaceFieldIndex].uint64 = v64_
+									}
+								case typeMap.T_SHORT:
+									v16_ = uint16(0)
+									for shift = uint(0); ; shift += 7 {
+										if shift >= 16 {
+											return 0, def.ErrIntOverflow
+										}
+										if pos >= l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										b_ = data[pos]
+										pos++
+										v16_ |= uint16(b_&0x7F) << shift
+										if b_ < 0x80 {
+											break
+										}
+									}
+									// skipping
+								case typeMap.T_BOOLEAN:
+									if pos >= l {
+										return 0, io.ErrUnexpectedEOF
+									}
+									b_ = data[pos]
+									pos++
+									// skipping
+								case typeMap.T_FLOAT:
+									if pos+4 > l {
+										return 0, io.ErrUnexpectedEOF
+									}
+									v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])
+									pos += 4
+									// skipping
+								case typeMap.T_DOUBLE:
+									if pos+8 > l {
+										return 0, io.ErrUnexpectedEOF
+									}
+									pos += 8
+								default:
+									bindVirtualSpaceFieldType := typeMap.IDMap[bindVirtualSpace.Fields[bindVirtualSpaceFieldIndex].Field.Type]
+									if bindVirtualSpaceFieldType == nil || len(bindVirtualSpaceFieldType.Fields) == 0 {
+										return 0, fmt.Errorf("unknown type %d %+v", bindVirtualSpace.Fields[bindVirtualSpaceFieldIndex].Field.Type, bindVirtualSpaceFieldType)
+									}
+									bindVirtualSpaceSkipObjects := 1
+									if bindVirtualSpace.Fields[bindVirtualSpaceFieldIndex].Field.Array {
+										v32_ = uint32(0)
+										for shift = uint(0); ; shift += 7 {
+											if shift >= 32 {
+												return 0, def.ErrIntOverflow
+											}
+											if pos >= l {
+												return 0, io.ErrUnexpectedEOF
+											}
+											b_ = data[pos]
+											pos++
+											v32_ |= uint32(b_&0x7F) << shift
+											if b_ < 0x80 {
+												break
+											}
+										}
+										bindVirtualSpaceSkipObjects = int(v32_)
+									}
+									for bindVirtualSpaceSkipObjectIndex := 0; bindVirtualSpaceSkipObjectIndex < bindVirtualSpaceSkipObjects; bindVirtualSpaceSkipObjectIndex++ {
+										for bindVirtualSpaceskipFieldIndex := 0; bindVirtualSpaceskipFieldIndex < len(bindVirtualSpaceFieldType.Fields); bindVirtualSpaceskipFieldIndex++ {
+											bindVirtualSpaceSkipFieldType := bindVirtualSpaceFieldType.Fields[bindVirtualSpaceskipFieldIndex].Type
+											if bindVirtualSpaceFieldType.Fields[bindVirtualSpaceskipFieldIndex].ConstantPool {
+												v32_ = uint32(0)
+												for shift = uint(0); ; shift += 7 {
+													if shift >= 32 {
+														return 0, def.ErrIntOverflow
+													}
+													if pos >= l {
+														return 0, io.ErrUnexpectedEOF
+													}
+													b_ = data[pos]
+													pos++
+													v32_ |= uint32(b_&0x7F) << shift
+													if b_ < 0x80 {
+														break
+													}
+												}
+											} else if bindVirtualSpaceSkipFieldType == typeMap.T_STRING {
+												s_ = ""
+												if pos >= l {
+													return 0, io.ErrUnexpectedEOF
+												}
+												b_ = data[pos]
+												pos++
+												switch b_ {
+												case 0:
+													break
+												case 1:
+													break
+												case 2:
+													v64_ = 0
+													for shift = uint(0); shift <= 56; shift += 7 {
+														if pos >= l {
+															return 0, io.ErrUnexpectedEOF
+														}
+														b_ = data[pos]
+														pos++
+														if shift == 56 {
+															v64_ |= uint64(b_&0xFF) << shift
+															break
+														} else {
+															v64_ |= uint64(b_&0x7F) << shift
+															if b_ < 0x80 {
+																break
+															}
+														}
+													}
+													if typeMap.StringConstant != nil {
+														s_ = typeMap.StringConstant(v64_)
+													}
+												case 3:
+													v32_ = uint32(0)
+													for shift = uint(0); ; shift += 7 {
+														if shift >= 32 {
+															return 0, def.ErrIntOverflow
+														}
+														if pos >= l {
+															return 0, io.ErrUnexpectedEOF
+														}
+														b_ = data[pos]
+														pos++
+														v32_ |= uint32(b_&0x7F) << shift
+														if b_ < 0x80 {
+															break
+														}
+													}
+													if pos+int(v32_) > l {
+														return 0, io.ErrUnexpectedEOF
+													}
+													bs := data[pos : pos+int(v32_)]
+													s_ = *(*string)(unsafe.Pointer(&bs))
+													pos += int(v32_)
+												case 5:
+													v32_ = uint32(0)
+													for shift = uint(0); ; shift += 7 {
+														if shift >= 32 {
+															return 0, def.ErrIntOverflow
+														}
+														if pos >= l {
+															return 0, io.ErrUnexpectedEOF
+														}
+														b_ = data[pos]
+														pos++
+														v32_ |= uint32(b_&0x7F) << shift
+														if b_ < 0x80 {
+															break
+														}
+													}
+													if pos+int(v32_) > l {
+														return 0, io.ErrUnexpectedEOF
+													}
+													bs := data[pos : pos+int(v32_)]
+													bs, _ = typeMap.ISO8859_1Decoder.Bytes(bs)
+													s_ = *(*string)(unsafe.Pointer(&bs))
+													pos += int(v32_)
+												case 4:
+													v32_ = uint32(0)
+													for shift = uint(0); ; shift += 7 {
+														if shift >= 32 {
+															return 0, def.ErrIntOverflow
+														}
+														if pos >= l {
+															return 0, io.ErrUnexpectedEOF
+														}
+														b_ = data[pos]
+														pos++
+														v32_ |= uint32(b_&0x7F) << shift
+														if b_ < 0x80 {
+															break
+														}
+													}
+													bl := int(v32_)
+													buf := make([]rune, bl)
+													for i := 0; i < bl; i++ {
+														v32_ = uint32(0)
+														for shift = uint(0); ; shift += 7 {
+															if shift >= 32 {
+																return 0, def.ErrIntOverflow
+															}
+															if pos >= l {
+																return 0, io.ErrUnexpectedEOF
+															}
+															b_ = data[pos]
+															pos++
+															v32_ |= uint32(b_&0x7F) << shift
+															if b_ < 0x80 {
+																break
+															}
+														}
+														buf[i] = rune(v32_)
+													}
+													s_ = string(buf)
+												default:
+													return 0, fmt.Errorf("unknown string type %d at %d", b_, pos)
+												}
+											} else if bindVirtualSpaceSkipFieldType == typeMap.T_INT {
+												v32_ = uint32(0)
+												for shift = uint(0); ; shift += 7 {
+													if shift >= 32 {
+														return 0, def.ErrIntOverflow
+													}
+													if pos >= l {
+														return 0, io.ErrUnexpectedEOF
+													}
+													b_ = data[pos]
+													pos++
+													v32_ |= uint32(b_&0x7F) << shift
+													if b_ < 0x80 {
+														break
+													}
+												}
+											} else if bindVirtualSpaceSkipFieldType == typeMap.T_FLOAT {
+												if pos+4 > l {
+													return 0, io.ErrUnexpectedEOF
+												}
+												v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])
+												pos += 4
+											} else if bindVirtualSpaceSkipFieldType == typeMap.T_DOUBLE {
+												if pos+8 > l {
+													return 0, io.ErrUnexpectedEOF
+												}
+												pos += 8
+											} else if bindVirtualSpaceSkipFieldType == typeMap.T_LONG {
+												v64_ = 0
+												for shift = uint(0); shift <= 56; shift += 7 {
+													if pos >= l {
+														return 0, io.ErrUnexpectedEOF
+													}
+													b_ = data[pos]
+													pos++
+													if shift == 56 {
+														v64_ |= uint64(b_&0xFF) << shift
+														break
+													} else {
+														v64_ |= uint64(b_&0x7F) << shift
+														if b_ < 0x80 {
+															break
+														}
+													}
+												}
+											} else if bindVirtualSpaceSkipFieldType == typeMap.T_SHORT {
+												v16_ = uint16(0)
+												for shift = uint(0); ; shift += 7 {
+													if shift >= 16 {
+														return 0, def.ErrIntOverflow
+													}
+													if pos >= l {
+														return 0, io.ErrUnexpectedEOF
+													}
+													b_ = data[pos]
+													pos++
+													v16_ |= uint16(b_&0x7F) << shift
+													if b_ < 0x80 {
+														break
+													}
+												}
+											} else if bindVirtualSpaceSkipFieldType == typeMap.T_BOOLEAN {
+												if pos >= l {
+													return 0, io.ErrUnexpectedEOF
+												}
+												b_ = data[pos]
+												pos++
+											} else {
+												return 0, fmt.Errorf("nested objects not implemented. ")
+											}
+										}
+									}
+								}
+							}
+						}
+					}
+					if bind.Fields[bindFieldIndex].VirtualSpace != nil {
+						*bind.Fields[bindFieldIndex].VirtualSpace = bindVirtualSpace.Temp
+					}
+				default:
+					bindFieldType := typeMap.IDMap[bind.Fields[bindFieldIndex].Field.Type]
+					if bindFieldType == nil || len(bindFieldType.Fields) == 0 {
+						return 0, fmt.Errorf("unknown type %d %+v", bind.Fields[bindFieldIndex].Field.Type, bindFieldType)
+					}
+					bindSkipObjects := 1
+					if bind.Fields[bindFieldIndex].Field.Array {
+						v32_ = uint32(0)
+						for shift = uint(0); ; shift += 7 {
+							if shift >= 32 {
+								return 0, def.ErrIntOverflow
+							}
+							if pos >= l {
+								return 0, io.ErrUnexpectedEOF
+							}
+							b_ = data[pos]
+							pos++
+							v32_ |= uint32(b_&0x7F) << shift
+							if b_ < 0x80 {
+								break
+							}
+						}
+						bindSkipObjects = int(v32_)
+					}
+					for bindSkipObjectIndex := 0; bindSkipObjectIndex < bindSkipObjects; bindSkipObjectIndex++ {
+						for bindskipFieldIndex := 0; bindskipFieldIndex < len(bindFieldType.Fields); bindskipFieldIndex++ {
+							bindSkipFieldType := bindFieldType.Fields[bindskipFieldIndex].Type
+							if bindFieldType.Fields[bindskipFieldIndex].ConstantPool {
+								v32_ = uint32(0)
+								for shift = uint(0); ; shift += 7 {
+									if shift >= 32 {
+										return 0, def.ErrIntOverflow
+									}
+									if pos >= l {
+										return 0, io.ErrUnexpectedEOF
+									}
+									b_ = data[pos]
+									pos++
+									v32_ |= uint32(b_&0x7F) << shift
+									if b_ < 0x80 {
+										break
+									}
+								}
+							} else if bindSkipFieldType == typeMap.T_STRING {
+								s_ = ""
+								if pos >= l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								b_ = data[pos]
+								pos++
+								switch b_ {
+								case 0:
+									break
+								case 1:
+									break
+								case 2:
+									v64_ = 0
+									for shift = uint(0); shift <= 56; shift += 7 {
+										if pos >= l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										b_ = data[pos]
+										pos++
+										if shift == 56 {
+											v64_ |= uint64(b_&0xFF) << shift
+											break
+										} else {
+											v64_ |= uint64(b_&0x7F) << shift
+											if b_ < 0x80 {
+												break
+											}
+										}
+									}
+									if typeMap.StringConstant != nil {
+										s_ = typeMap.StringConstant(v64_)
+									}
+								case 3:
+									v32_ = uint32(0)
+									for shift = uint(0); ; shift += 7 {
+										if shift >= 32 {
+											return 0, def.ErrIntOverflow
+										}
+										if pos >= l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										b_ = data[pos]
+										pos++
+										v32_ |= uint32(b_&0x7F) << shift
+										if b_ < 0x80 {
+											break
+										}
+									}
+									if pos+int(v32_) > l {
+										return 0, io.ErrUnexpectedEOF
+									}
+									bs := data[pos : pos+int(v32_)]
+									s_ = *(*string)(unsafe.Pointer(&bs))
+									pos += int(v32_)
+								case 5:
+									v32_ = uint32(0)
+									for shift = uint(0); ; shift += 7 {
+										if shift >= 32 {
+											return 0, def.ErrIntOverflow
+										}
+										if pos >= l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										b_ = data[pos]
+										pos++
+										v32_ |= uint32(b_&0x7F) << shift
+										if b_ < 0x80 {
+											break
+										}
+									}
+									if pos+int(v32_) > l {
+										return 0, io.ErrUnexpectedEOF
+									}
+									bs := data[pos : pos+int(v32_)]
+									bs, _ = typeMap.ISO8859_1Decoder.Bytes(bs)
+									s_ = *(*string)(unsafe.Pointer(&bs))
+									pos += int(v32_)
+								case 4:
+									v32_ = uint32(0)
+									for shift = uint(0); ; shift += 7 {
+										if shift >= 32 {
+											return 0, def.ErrIntOverflow
+										}
+										if pos >= l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										b_ = data[pos]
+										pos++
+										v32_ |= uint32(b_&0x7F) << shift
+										if b_ < 0x80 {
+											break
+										}
+									}
+									bl := int(v32_)
+									buf := make([]rune, bl)
+									for i := 0; i < bl; i++ {
+										v32_ = uint32(0)
+										for shift = uint(0); ; shift += 7 {
+											if shift >= 32 {
+												return 0, def.ErrIntOverflow
+											}
+											if pos >= l {
+												return 0, io.ErrUnexpectedEOF
+											}
+											b_ = data[pos]
+											pos++
+											v32_ |= uint32(b_&0x7F) << shift
+											if b_ < 0x80 {
+												break
+											}
+										}
+										buf[i] = rune(v32_)
+									}
+									s_ = string(buf)
+								default:
+									return 0, fmt.Errorf("unknown string type %d at %d", b_, pos)
+								}
+							} else if bindSkipFieldType == typeMap.T_INT {
+								v32_ = uint32(0)
+								for shift = uint(0); ; shift += 7 {
+									if shift >= 32 {
+										return 0, def.ErrIntOverflow
+									}
+									if pos >= l {
+										return 0, io.ErrUnexpectedEOF
+									}
+									b_ = data[pos]
+									pos++
+									v32_ |= uint32(b_&0x7F) << shift
+									if b_ < 0x80 {
+										break
+									}
+								}
+							} else if bindSkipFieldType == typeMap.T_FLOAT {
+								if pos+4 > l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])
+								pos += 4
+							} else if bindSkipFieldType == typeMap.T_DOUBLE {
+								if pos+8 > l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								pos += 8
+							} else if bindSkipFieldType == typeMap.T_LONG {
+								v64_ = 0
+								for shift = uint(0); shift <= 56; shift += 7 {
+									if pos >= l {
+										return 0, io.ErrUnexpectedEOF
+									}
+									b_ = data[pos]
+									pos++
+									if shift == 56 {
+										v64_ |= uint64(b_&0xFF) << shift
+										break
+									} else {
+										v64_ |= uint64(b_&0x7F) << shift
+										if b_ < 0x80 {
+											break
+										}
+									}
+								}
+							} else if bindSkipFieldType == typeMap.T_SHORT {
+								v16_ = uint16(0)
+								for shift = uint(0); ; shift += 7 {
+									if shift >= 16 {
+										return 0, def.ErrIntOverflow
+									}
+									if pos >= l {
+										return 0, io.ErrUnexpectedEOF
+									}
+									b_ = data[pos]
+									pos++
+									v16_ |= uint16(b_&0x7F) << shift
+									if b_ < 0x80 {
+										break
+									}
+								}
+							} else if bindSkipFieldType == typeMap.T_BOOLEAN {
+								if pos >= l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								b_ = data[pos]
+								pos++
+							} else {
+								return 0, fmt.Errorf("nested objects not implemented. ")
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	*this = bind.Temp
+	return pos, nil
+}
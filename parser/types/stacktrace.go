@@ -168,6 +168,27 @@ func (this *StackTraceList) Parse(data []byte, bind *BindStackTrace, bindStackFr
 							break
 						case 1:
 							break
+						case 2:
+							v64_ = 0
+							for shift = uint(0); shift <= 56; shift += 7 {
+								if pos >= l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								b_ = data[pos]
+								pos++
+								if shift == 56 {
+									v64_ |= uint64(b_&0xFF) << shift
+									break
+								} else {
+									v64_ |= uint64(b_&0x7F) << shift
+									if b_ < 0x80 {
+										break
+									}
+								}
+							}
+							if typeMap.StringConstant != nil {
+								s_ = typeMap.StringConstant(v64_)
+							}
 						case 3:
 							v32_ = uint32(0)
 							for shift = uint(0); ; shift += 7 {
@@ -317,22 +338,17 @@ func (this *StackTraceList) Parse(data []byte, bind *BindStackTrace, bindStackFr
 							*bind.Fields[bindFieldIndex].bool = b_ != 0
 						}
 					case typeMap.T_FLOAT:
-						v32_ = uint32(0)
-						for shift = uint(0); ; shift += 7 {
-							if shift >= 32 {
-								return 0, def.ErrIntOverflow
-							}
-							if pos >= l {
-								return 0, io.ErrUnexpectedEOF
-							}
-							b_ = data[pos]
-							pos++
-							v32_ |= uint32(b_&0x7F) << shift
-							if b_ < 0x80 {
-								break
-							}
+						if pos+4 > l {
+							return 0, io.ErrUnexpectedEOF
 						}
+						v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])
+						pos += 4
 						// skipping
+					case typeMap.T_DOUBLE:
+						if pos+8 > l {
+							return 0, io.ErrUnexpectedEOF
+						}
+						pos += 8
 					case typeMap.T_STACK_FRAME:
 						for bindStackFrameFieldIndex := 0; bindStackFrameFieldIndex < len(bindStackFrame.Fields); bindStackFrameFieldIndex++ {
 							bindStackFrameArraySize := 1
@@ -398,6 +414,27 @@ func (this *StackTraceList) Parse(data []byte, bind *BindStackTrace, bindStackFr
 											break
 										case 1:
 											break
+										case 2:
+											v64_ = 0
+											for shift = uint(0); shift <= 56; shift += 7 {
+												if pos >= l {
+													return 0, io.ErrUnexpectedEOF
+												}
+												b_ = data[pos]
+												pos++
+												if shift == 56 {
+													v64_ |= uint64(b_&0xFF) << shift
+													break
+												} else {
+													v64_ |= uint64(b_&0x7F) << shift
+													if b_ < 0x80 {
+														break
+													}
+												}
+											}
+											if typeMap.StringConstant != nil {
+												s_ = typeMap.StringConstant(v64_)
+											}
 										case 3:
 											v32_ = uint32(0)
 											for shift = uint(0); ; shift += 7 {
@@ -547,22 +584,17 @@ func (this *StackTraceList) Parse(data []byte, bind *BindStackTrace, bindStackFr
 										pos++
 										// skipping
 									case typeMap.T_FLOAT:
-										v32_ = uint32(0)
-										for shift = uint(0); ; shift += 7 {
-											if shift >= 32 {
-												return 0, def.ErrIntOverflow
-											}
-											if pos >= l {
-												return 0, io.ErrUnexpectedEOF
-											}
-											b_ = data[pos]
-											pos++
-											v32_ |= uint32(b_&0x7F) << shift
-											if b_ < 0x80 {
-												break
-											}
+										if pos+4 > l {
+											return 0, io.ErrUnexpectedEOF
 										}
+										v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])
+										pos += 4
 										// skipping
+									case typeMap.T_DOUBLE:
+										if pos+8 > l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										pos += 8
 									default:
 										bindStackFrameFieldType := typeMap.IDMap[bindStackFrame.Fields[bindStackFrameFieldIndex].Field.Type]
 										if bindStackFrameFieldType == nil || len(bindStackFrameFieldType.Fields) == 0 {
@@ -618,6 +650,27 @@ func (this *StackTraceList) Parse(data []byte, bind *BindStackTrace, bindStackFr
 														break
 													case 1:
 														break
+													case 2:
+														v64_ = 0
+														for shift = uint(0); shift <= 56; shift += 7 {
+															if pos >= l {
+																return 0, io.ErrUnexpectedEOF
+															}
+															b_ = data[pos]
+															pos++
+															if shift == 56 {
+																v64_ |= uint64(b_&0xFF) << shift
+																break
+															} else {
+																v64_ |= uint64(b_&0x7F) << shift
+																if b_ < 0x80 {
+																	break
+																}
+															}
+														}
+														if typeMap.StringConstant != nil {
+															s_ = typeMap.StringConstant(v64_)
+														}
 													case 3:
 														v32_ = uint32(0)
 														for shift = uint(0); ; shift += 7 {
@@ -720,21 +773,16 @@ func (this *StackTraceList) Parse(data []byte, bind *BindStackTrace, bindStackFr
 														}
 													}
 												} else if bindStackFrameSkipFieldType == typeMap.T_FLOAT {
-													v32_ = uint32(0)
-													for shift = uint(0); ; shift += 7 {
-														if shift >= 32 {
-															return 0, def.ErrIntOverflow
-														}
-														if pos >= l {
-															return 0, io.ErrUnexpectedEOF
-														}
-														b_ = data[pos]
-														pos++
-														v32_ |= uint32(b_&0x7F) << shift
-														if b_ < 0x80 {
-															break
-														}
+													if pos+4 > l {
+														return 0, io.ErrUnexpectedEOF
+													}
+													v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])
+													pos += 4
+												} else if bindStackFrameSkipFieldType == typeMap.T_DOUBLE {
+													if pos+8 > l {
+														return 0, io.ErrUnexpectedEOF
 													}
+													pos += 8
 												} else if bindStackFrameSkipFieldType == typeMap.T_LONG {
 													v64_ = 0
 													for shift = uint(0); shift <= 56; shift += 7 {
@@ -842,6 +890,27 @@ func (this *StackTraceList) Parse(data []byte, bind *BindStackTrace, bindStackFr
 										break
 									case 1:
 										break
+									case 2:
+										v64_ = 0
+										for shift = uint(0); shift <= 56; shift += 7 {
+											if pos >= l {
+												return 0, io.ErrUnexpectedEOF
+											}
+											b_ = data[pos]
+											pos++
+											if shift == 56 {
+												v64_ |= uint64(b_&0xFF) << shift
+												break
+											} else {
+												v64_ |= uint64(b_&0x7F) << shift
+												if b_ < 0x80 {
+													break
+												}
+											}
+										}
+										if typeMap.StringConstant != nil {
+											s_ = typeMap.StringConstant(v64_)
+										}
 									case 3:
 										v32_ = uint32(0)
 										for shift = uint(0); ; shift += 7 {
@@ -944,21 +1013,16 @@ func (this *StackTraceList) Parse(data []byte, bind *BindStackTrace, bindStackFr
 										}
 									}
 								} else if bindSkipFieldType == typeMap.T_FLOAT {
-									v32_ = uint32(0)
-									for shift = uint(0); ; shift += 7 {
-										if shift >= 32 {
-											return 0, def.ErrIntOverflow
-										}
-										if pos >= l {
-											return 0, io.ErrUnexpectedEOF
-										}
-										b_ = data[pos]
-										pos++
-										v32_ |= uint32(b_&0x7F) << shift
-										if b_ < 0x80 {
-											break
-										}
+									if pos+4 > l {
+										return 0, io.ErrUnexpectedEOF
+									}
+									v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])
+									pos += 4
+								} else if bindSkipFieldType == typeMap.T_DOUBLE {
+									if pos+8 > l {
+										return 0, io.ErrUnexpectedEOF
 									}
+									pos += 8
 								} else if bindSkipFieldType == typeMap.T_LONG {
 									v64_ = 0
 									for shift = uint(0); shift <= 56; shift += 7 {
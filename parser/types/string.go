@@ -107,6 +107,27 @@ func (this *StringList) Parse(data []byte, bind *BindString, typeMap *def.TypeMa
 			break
 		case 1:
 			break
+		case 2:
+			v64_ = 0
+			for shift = uint(0); shift <= 56; shift += 7 {
+				if pos >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b_ = data[pos]
+				pos++
+				if shift == 56 {
+					v64_ |= uint64(b_&0xFF) << shift
+					break
+				} else {
+					v64_ |= uint64(b_&0x7F) << shift
+					if b_ < 0x80 {
+						break
+					}
+				}
+			}
+			if typeMap.StringConstant != nil {
+				s_ = typeMap.StringConstant(v64_)
+			}
 		case 3:
 			v32_ = uint32(0)
 			for shift = uint(0); ; shift += 7 {
@@ -3,6 +3,8 @@ package pprof
 import (
 	"time"
 
+	"github.com/grafana/jfr-parser/parser"
+
 	profilev1 "github.com/grafana/pyroscope/api/gen/proto/go/google/v1"
 	typesv1 "github.com/grafana/pyroscope/api/gen/proto/go/types/v1"
 )
@@ -20,6 +22,8 @@ type Profiles struct {
 	JFREvent string
 
 	ParseMetrics ParseMetrics
+	// RecordingInfo is only set with WithRecordingInfo.
+	RecordingInfo *parser.RecordingInfo
 }
 
 type Profile struct {
@@ -28,7 +32,17 @@ type Profile struct {
 }
 
 type ParseMetrics struct {
+	// StacktraceNotFound is the number of events referring to a stack trace missing from the
+	// constant pool. Events recorded without a stack trace, stack trace reference 0, are
+	// skipped without being counted, since event types such as exceptions and I/O may have none.
 	StacktraceNotFound int
 	ClassNotFound      int
 	MethodNotFound     int
+
+	// CPUTimeSamplesLost is the number of samples jdk.CPUTimeSamplesLost reports as dropped by the JVM.
+	CPUTimeSamplesLost int
+	// CPUTimeSamplesFailed is the number of jdk.CPUTimeSample events without a stack trace.
+	CPUTimeSamplesFailed int
+	// VirtualThreadSubmitFailed is the number of virtual threads the scheduler failed to start or unpark.
+	VirtualThreadSubmitFailed int
 }
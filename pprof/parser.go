@@ -3,13 +3,28 @@ package pprof
 import (
 	"fmt"
 	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/grafana/jfr-parser/parser"
+	"github.com/grafana/jfr-parser/parser/types/def"
 )
 
 type pprofOptions struct {
 	truncatedFrame       bool
 	disablePanicRecovery bool
+	nativeProfile        bool
+	recordingInfo        bool
+
+	exceptionDetail        ExceptionDetail
+	exceptionMessageLength int
+
+	ioLabelLimit    int
+	threadKindLabel bool
+	threadNameLabel bool
+	frameTypes      bool
 }
 type Option func(*pprofOptions)
 
@@ -19,41 +34,100 @@ func WithTruncatedFrame(v bool) Option {
 	}
 }
 
-func WithDisablePanicRecovery(v bool) Option {
+// WithNativeProfile additionally puts jdk.NativeMethodSample events into a dedicated "native" profile.
+// They are always part of the wall profile, each sample counting as the jdk.NativeMethodSample
+// period setting of the recording, 20 ms by default.
+func WithNativeProfile(v bool) Option {
 	return func(o *pprofOptions) {
-		o.disablePanicRecovery = v
+		o.nativeProfile = v
 	}
 }
 
-func ParseJFR(body []byte, pi *ParseInput, jfrLabels *LabelsSnapshot, opts ...Option) (res *Profiles, err error) {
-	o := &pprofOptions{
-		truncatedFrame:       false,
-		disablePanicRecovery: false,
+// WithRecordingInfo collects the JVM, OS, CPU and system property events into Profiles.RecordingInfo.
+func WithRecordingInfo(v bool) Option {
+	return func(o *pprofOptions) {
+		o.recordingInfo = v
 	}
-	for i := range opts {
-		opts[i](o)
+}
+
+type ExceptionDetail int
+
+const (
+	// ExceptionDetailNone aggregates exceptions by throw site only.
+	ExceptionDetailNone ExceptionDetail = iota
+	// ExceptionDetailFrame adds the thrown class as the leaf frame of the exceptions profile.
+	ExceptionDetailFrame
+	// ExceptionDetailLabel adds the thrown class as the exception_class label.
+	ExceptionDetailLabel
+)
+
+// WithExceptionDetail controls how the thrown class is added to the exceptions profile.
+// A positive messageLength also adds the exception message, truncated to that many
+// characters, to the leaf frame or as the exception_message label.
+// Messages often contain ids or other unbounded values, use them with care.
+func WithExceptionDetail(d ExceptionDetail, messageLength int) Option {
+	return func(o *pprofOptions) {
+		o.exceptionDetail = d
+		o.exceptionMessageLength = messageLength
 	}
+}
 
-	if !o.disablePanicRecovery {
-		defer func() {
-			if r := recover(); r != nil {
-				err = fmt.Errorf("jfr parser panic: %v", r)
-			}
-		}()
+// WithIOLabels adds the remote host:port to socket_io samples as the io_host label
+// and the path to file_io samples as the io_path label. At most limit distinct values
+// are kept per profile, further values are replaced with "other". A limit <= 0 disables the labels.
+func WithIOLabels(limit int) Option {
+	return func(o *pprofOptions) {
+		o.ioLabelLimit = limit
+	}
+}
+
+// WithThreadKindLabel adds the thread_kind label to CPU and wall samples of jdk.ExecutionSample,
+// "virtual" for virtual threads mounted on a carrier thread and "platform" for all other threads.
+func WithThreadKindLabel(v bool) Option {
+	return func(o *pprofOptions) {
+		o.threadKindLabel = v
+	}
+}
+
+// WithThreadNameLabel adds the thread name as the thread_name label to every sample,
+// the Java name if set, the OS name otherwise.
+func WithThreadNameLabel(v bool) Option {
+	return func(o *pprofOptions) {
+		o.threadNameLabel = v
+	}
+}
+
+// WithFrameTypes appends the async-profiler frame type suffix to function names,
+// _[j] for JIT compiled, _[i] for inlined, _[0] for interpreted, _[1] for C1 compiled
+// and _[k] for kernel frames. A method is then a function per frame type.
+func WithFrameTypes(v bool) Option {
+	return func(o *pprofOptions) {
+		o.frameTypes = v
+	}
+}
+
+func WithDisablePanicRecovery(v bool) Option {
+	return func(o *pprofOptions) {
+		o.disablePanicRecovery = v
 	}
+}
 
-	p := parser.NewParser(body, parser.Options{
-		SymbolProcessor: parser.ProcessSymbols,
-	})
-	return parse(p, pi, jfrLabels, o)
+func ParseJFR(body []byte, pi *ParseInput, jfrLabels *LabelsSnapshot, opts ...Option) (res *Profiles, err error) {
+	sink := NewProfilesSink()
+	if err := WalkJFR(body, pi, jfrLabels, sink, opts...); err != nil {
+		return nil, err
+	}
+	return sink.Profiles(), nil
 }
 
-func parse(parser *parser.Parser, piOriginal *ParseInput, jfrLabels *LabelsSnapshot, opt *pprofOptions) (result *Profiles, err error) {
+func parse(builders *jfrPprofBuilders, opt *pprofOptions) error {
 	var event string
+	parser := builders.parser
 
-	builders := newJfrPprofBuilders(parser, jfrLabels, piOriginal, opt)
+	var values = [3]int64{1, 0, 0}
 
-	var values = [2]int64{1, 0}
+	info := newRecordingInfo(opt)
+	exceptionsEnabled := false
 
 	for {
 		typ, err := parser.ParseEvent()
@@ -61,24 +135,28 @@ func parse(parser *parser.Parser, piOriginal *ParseInput, jfrLabels *LabelsSnaps
 			if err == io.EOF {
 				break
 			}
-			return nil, fmt.Errorf("jfr parser ParseEvent error: %w", err)
+			return fmt.Errorf("jfr parser ParseEvent error: %w", err)
 		}
+		values[0] = 1
 		switch typ {
 		case parser.TypeMap.T_EXECUTION_SAMPLE:
+			start := builders.startNanos(typ, parser.ExecutionSample.StartTime)
 			ts := parser.GetThreadState(parser.ExecutionSample.State)
 			correlation := StacktraceCorrelation{
 				ContextId: parser.ExecutionSample.ContextId,
 				SpanId:    parser.ExecutionSample.SpanId,
 				SpanName:  parser.ExecutionSample.SpanName,
 			}
+			values[0] = builders.period
 			if ts != nil && ts.Name != "STATE_SLEEPING" {
-				builders.addStacktrace(sampleTypeCPU, correlation, parser.ExecutionSample.StackTrace, values[:1])
+				builders.addExecutionSample(start, sampleTypeCPU, correlation, parser.ExecutionSample.StackTrace, parser.ExecutionSample.SampledThread, values[:1])
 			}
 			if event == "wall" {
-				builders.addStacktrace(sampleTypeWall, correlation, parser.ExecutionSample.StackTrace, values[:1])
+				builders.addExecutionSample(start, sampleTypeWall, correlation, parser.ExecutionSample.StackTrace, parser.ExecutionSample.SampledThread, values[:1])
 			}
 		case parser.TypeMap.T_WALL_CLOCK_SAMPLE:
-			values[0] = int64(parser.WallClockSample.Samples)
+			start := builders.startNanos(typ, parser.WallClockSample.StartTime)
+			values[0] = int64(parser.WallClockSample.Samples) * builders.period
 			correlation := StacktraceCorrelation{
 				ContextId: parser.WallClockSample.ContextId,
 				SpanId:    parser.WallClockSample.SpanId,
@@ -86,52 +164,170 @@ func parse(parser *parser.Parser, piOriginal *ParseInput, jfrLabels *LabelsSnaps
 			}
 			ts := parser.GetThreadState(parser.WallClockSample.State)
 			if ts != nil && ts.Name == "STATE_RUNNABLE" && event == "wall" {
-				builders.addStacktrace(sampleTypeCPU, correlation, parser.WallClockSample.StackTrace, values[:1])
+				builders.addStacktrace(start, sampleTypeCPU, correlation, parser.WallClockSample.StackTrace, parser.WallClockSample.SampledThread, values[:1])
 			}
-			builders.addStacktrace(sampleTypeWall, correlation, parser.WallClockSample.StackTrace, values[:1])
+			builders.addStacktrace(start, sampleTypeWall, correlation, parser.WallClockSample.StackTrace, parser.WallClockSample.SampledThread, values[:1])
 		case parser.TypeMap.T_ALLOC_IN_NEW_TLAB:
+			start := builders.startNanos(typ, parser.ObjectAllocationInNewTLAB.StartTime)
 			values[1] = int64(parser.ObjectAllocationInNewTLAB.TlabSize)
 			correlation := StacktraceCorrelation{
 				ContextId: parser.ObjectAllocationInNewTLAB.ContextId,
 				SpanId:    parser.ObjectAllocationInNewTLAB.SpanId,
 				SpanName:  parser.ObjectAllocationInNewTLAB.SpanName,
 			}
-			builders.addStacktrace(sampleTypeInTLAB, correlation, parser.ObjectAllocationInNewTLAB.StackTrace, values[:2])
+			builders.addStacktrace(start, sampleTypeInTLAB, correlation, parser.ObjectAllocationInNewTLAB.StackTrace, parser.ObjectAllocationInNewTLAB.EventThread, values[:2])
 		case parser.TypeMap.T_ALLOC_OUTSIDE_TLAB:
+			start := builders.startNanos(typ, parser.ObjectAllocationOutsideTLAB.StartTime)
 			values[1] = int64(parser.ObjectAllocationOutsideTLAB.AllocationSize)
 			correlation := StacktraceCorrelation{
 				ContextId: parser.ObjectAllocationOutsideTLAB.ContextId,
 				SpanId:    parser.ObjectAllocationOutsideTLAB.SpanId,
 				SpanName:  parser.ObjectAllocationOutsideTLAB.SpanName,
 			}
-			builders.addStacktrace(sampleTypeOutTLAB, correlation, parser.ObjectAllocationOutsideTLAB.StackTrace, values[:2])
+			builders.addStacktrace(start, sampleTypeOutTLAB, correlation, parser.ObjectAllocationOutsideTLAB.StackTrace, parser.ObjectAllocationOutsideTLAB.EventThread, values[:2])
 		case parser.TypeMap.T_ALLOC_SAMPLE:
+			start := builders.startNanos(typ, parser.ObjectAllocationSample.StartTime)
 			values[1] = int64(parser.ObjectAllocationSample.Weight)
-			builders.addStacktrace(sampleTypeAllocSample, StacktraceCorrelation{}, parser.ObjectAllocationSample.StackTrace, values[:2])
+			builders.addStacktrace(start, sampleTypeAllocSample, StacktraceCorrelation{}, parser.ObjectAllocationSample.StackTrace, parser.ObjectAllocationSample.EventThread, values[:2])
 		case parser.TypeMap.T_MONITOR_ENTER:
-			values[1] = int64(parser.JavaMonitorEnter.Duration)
+			start := builders.startNanos(typ, parser.JavaMonitorEnter.StartTime)
+			values[1] = builders.eventDurationNanos(typ, parser.JavaMonitorEnter.Duration)
 			correlation := StacktraceCorrelation{
 				ContextId: parser.JavaMonitorEnter.ContextId,
 				SpanId:    parser.JavaMonitorEnter.SpanId,
 				SpanName:  parser.JavaMonitorEnter.SpanName,
 			}
-			builders.addStacktrace(sampleTypeLock, correlation, parser.JavaMonitorEnter.StackTrace, values[:2])
+			builders.addStacktrace(start, sampleTypeLock, correlation, parser.JavaMonitorEnter.StackTrace, parser.JavaMonitorEnter.EventThread, values[:2])
 		case parser.TypeMap.T_THREAD_PARK:
-			values[1] = int64(parser.ThreadPark.Duration)
-			builders.addStacktrace(sampleTypeThreadPark, StacktraceCorrelation{}, parser.ThreadPark.StackTrace, values[:2])
+			start := builders.startNanos(typ, parser.ThreadPark.StartTime)
+			values[1] = builders.eventDurationNanos(typ, parser.ThreadPark.Duration)
+			builders.addStacktrace(start, sampleTypeThreadPark, StacktraceCorrelation{}, parser.ThreadPark.StackTrace, parser.ThreadPark.EventThread, values[:2])
 		case parser.TypeMap.T_LIVE_OBJECT:
-			builders.addStacktrace(sampleTypeLiveObject, StacktraceCorrelation{}, parser.LiveObject.StackTrace, values[:1])
+			start := builders.startNanos(typ, parser.LiveObject.StartTime)
+			builders.addStacktrace(start, sampleTypeLiveObject, StacktraceCorrelation{}, parser.LiveObject.StackTrace, parser.LiveObject.EventThread, values[:1])
 		case parser.TypeMap.T_MALLOC:
+			start := builders.startNanos(typ, parser.Malloc.StartTime)
 			values[1] = int64(parser.Malloc.Size)
-			builders.addStacktrace(sampleTypeMalloc, StacktraceCorrelation{}, parser.Malloc.StackTrace, values[:2])
+			builders.addStacktrace(start, sampleTypeMalloc, StacktraceCorrelation{}, parser.Malloc.StackTrace, parser.Malloc.EventThread, values[:2])
+		case parser.TypeMap.T_NATIVE_METHOD_SAMPLE:
+			start := builders.startNanos(typ, parser.NativeMethodSample.StartTime)
+			values[0] = builders.nativePeriod
+			builders.addStacktrace(start, sampleTypeWall, StacktraceCorrelation{}, parser.NativeMethodSample.StackTrace, parser.NativeMethodSample.SampledThread, values[:1])
+			if opt.nativeProfile {
+				builders.addStacktrace(start, sampleTypeNative, StacktraceCorrelation{}, parser.NativeMethodSample.StackTrace, parser.NativeMethodSample.SampledThread, values[:1])
+			}
+		case parser.TypeMap.T_CPU_TIME_SAMPLE:
+			if parser.CPUTimeSample.Failed {
+				builders.metrics.CPUTimeSamplesFailed++
+				break
+			}
+			start := builders.startNanos(typ, parser.CPUTimeSample.StartTime)
+			values[0] = builders.samplingPeriodNanos(typ, parser.CPUTimeSample.SamplingPeriod)
+			if values[0] <= 0 {
+				values[0] = builders.cpuTimePeriod
+			}
+			if values[0] <= 0 {
+				values[0] = builders.period
+			}
+			builders.addStacktrace(start, sampleTypeCPUTime, StacktraceCorrelation{}, parser.CPUTimeSample.StackTrace, parser.CPUTimeSample.EventThread, values[:1])
+		case parser.TypeMap.T_JAVA_EXCEPTION_THROW:
+			start := builders.startNanos(typ, parser.JavaExceptionThrow.StartTime)
+			builders.addException(start, parser.JavaExceptionThrow.StackTrace, parser.JavaExceptionThrow.EventThread, parser.JavaExceptionThrow.ThrownClass, parser.JavaExceptionThrow.Message)
+		case parser.TypeMap.T_JAVA_ERROR_THROW:
+			// errors are recorded as jdk.JavaExceptionThrow as well when it is enabled
+			if !exceptionsEnabled {
+				start := builders.startNanos(typ, parser.JavaErrorThrow.StartTime)
+				builders.addException(start, parser.JavaErrorThrow.StackTrace, parser.JavaErrorThrow.EventThread, parser.JavaErrorThrow.ThrownClass, parser.JavaErrorThrow.Message)
+			}
+		case parser.TypeMap.T_SOCKET_READ:
+			e := &parser.SocketRead
+			start := builders.startNanos(typ, e.StartTime)
+			values[1] = ioBytes(e.BytesRead)
+			values[2] = builders.eventDurationNanos(typ, e.Duration)
+			builders.addIO(start, sampleTypeSocketIO, e.StackTrace, e.EventThread, hostPort(e.Host, e.Address, e.Port), values[:3])
+		case parser.TypeMap.T_SOCKET_WRITE:
+			e := &parser.SocketWrite
+			start := builders.startNanos(typ, e.StartTime)
+			values[1] = ioBytes(e.BytesWritten)
+			values[2] = builders.eventDurationNanos(typ, e.Duration)
+			builders.addIO(start, sampleTypeSocketIO, e.StackTrace, e.EventThread, hostPort(e.Host, e.Address, e.Port), values[:3])
+		case parser.TypeMap.T_FILE_READ:
+			e := &parser.FileRead
+			start := builders.startNanos(typ, e.StartTime)
+			values[1] = ioBytes(e.BytesRead)
+			values[2] = builders.eventDurationNanos(typ, e.Duration)
+			builders.addIO(start, sampleTypeFileIO, e.StackTrace, e.EventThread, e.Path, values[:3])
+		case parser.TypeMap.T_FILE_WRITE:
+			e := &parser.FileWrite
+			start := builders.startNanos(typ, e.StartTime)
+			values[1] = ioBytes(e.BytesWritten)
+			values[2] = builders.eventDurationNanos(typ, e.Duration)
+			builders.addIO(start, sampleTypeFileIO, e.StackTrace, e.EventThread, e.Path, values[:3])
+		case parser.TypeMap.T_VIRTUAL_THREAD_PINNED:
+			start := builders.startNanos(typ, parser.VirtualThreadPinned.StartTime)
+			values[1] = builders.eventDurationNanos(typ, parser.VirtualThreadPinned.Duration)
+			builders.addStacktrace(start, sampleTypePinned, StacktraceCorrelation{}, parser.VirtualThreadPinned.StackTrace, parser.VirtualThreadPinned.EventThread, values[:2])
+		case parser.TypeMap.T_VIRTUAL_THREAD_SUBMIT_FAILED:
+			builders.metrics.VirtualThreadSubmitFailed++
+		case parser.TypeMap.T_CPU_TIME_SAMPLES_LOST:
+			builders.metrics.CPUTimeSamplesLost += int(parser.CPUTimeSamplesLost.LostSamples)
 		case parser.TypeMap.T_ACTIVE_SETTING:
 			if parser.ActiveSetting.Name == "event" {
 				event = parser.ActiveSetting.Value
 			}
+			if parser.ActiveSetting.Name == "enabled" && def.TypeID(parser.ActiveSetting.Id) == parser.TypeMap.T_JAVA_EXCEPTION_THROW {
+				exceptionsEnabled = parser.ActiveSetting.Value == "true"
+			}
+			if parser.ActiveSetting.Name == "throttle" && def.TypeID(parser.ActiveSetting.Id) == parser.TypeMap.T_CPU_TIME_SAMPLE {
+				builders.cpuTimePeriod = settingPeriod(parser.ActiveSetting.Value)
+			}
+			if parser.ActiveSetting.Name == "period" && def.TypeID(parser.ActiveSetting.Id) == parser.TypeMap.T_NATIVE_METHOD_SAMPLE {
+				if period := settingPeriod(parser.ActiveSetting.Value); period > 0 {
+					builders.nativePeriod = period
+				}
+			}
+		default:
+			if info != nil {
+				info.Add(parser, typ)
+			}
 		}
 	}
 
-	result = builders.build(event)
+	return builders.finish(event, info)
+}
+
+func newRecordingInfo(opt *pprofOptions) *parser.RecordingInfo {
+	if !opt.recordingInfo {
+		return nil
+	}
+	return &parser.RecordingInfo{}
+}
+
+// hostPort returns the remote endpoint of a socket event, preferring the host name over the address.
+func hostPort(host, address string, port uint32) string {
+	if host == "" {
+		host = address
+	}
+	if host == "" {
+		return ""
+	}
+	return net.JoinHostPort(host, strconv.Itoa(int(port)))
+}
+
+// ioBytes returns the bytes of an I/O event, some JDKs record -1 at the end of a stream.
+func ioBytes(v uint64) int64 {
+	return max(int64(v), 0)
+}
 
-	return result, nil
+// settingPeriod parses a period or jdk.CPUTimeSample throttle setting such as "10 ms" into nanoseconds.
+// Rate settings such as "100/s" depend on the number of CPUs and return 0, as do "everyChunk" and "off".
+func settingPeriod(setting string) int64 {
+	if strings.Contains(setting, "/") {
+		return 0
+	}
+	d, err := time.ParseDuration(strings.ReplaceAll(setting, " ", ""))
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return d.Nanoseconds()
 }
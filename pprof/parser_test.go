@@ -128,26 +128,26 @@ var testFiles = []testdata{
 	{
 		jfr:           "dd-trace-java",
 		labels:        "",
-		expectedCount: 4,
+		expectedCount: 5,
 		options:       nil,
 	},
 	{
 		jfr:           "cpool-uint64-constant-index",
 		labels:        "",
-		expectedCount: 5,
+		expectedCount: 7,
 		options:       nil,
 	},
 	{
 		jfr:           "event-with-type-zero",
 		labels:        "",
-		expectedCount: 5,
+		expectedCount: 7,
 		options:       nil,
 	},
 	{
 		jfr:           "event-with-type-zero",
 		testName:      "event-with-type-zero with truncated frame",
 		labels:        "",
-		expectedCount: 5,
+		expectedCount: 7,
 		options:       []Option{WithTruncatedFrame(true)},
 	},
 	{
@@ -159,7 +159,7 @@ var testFiles = []testdata{
 	{
 		jfr:           "uint64-ref-id",
 		labels:        "",
-		expectedCount: 5,
+		expectedCount: 6,
 		options:       nil,
 	},
 
@@ -194,6 +194,18 @@ var testFiles = []testdata{
 		expectedCount: 2,
 		options:       nil,
 	},
+	{
+		jfr:           "cpu-time-sample",
+		labels:        "",
+		expectedCount: 1,
+		options:       nil,
+	},
+	{
+		jfr:           "virtual-threads",
+		labels:        "",
+		expectedCount: 2,
+		options:       nil,
+	},
 }
 
 type gprofile struct {
@@ -437,3 +449,234 @@ func stackCollapseProto(p *profilev1.Profile, lineNumbers bool) string {
 func TestProfileId(t *testing.T) {
 	assert.Equal(t, "00000000000000ef", profileIdString(0xef))
 }
+
+func TestSettingPeriod(t *testing.T) {
+	assert.Equal(t, int64(10_000_000), settingPeriod("10 ms"))
+	assert.Equal(t, int64(20_000_000), settingPeriod("20ms"))
+	assert.Equal(t, int64(1_000_000_000), settingPeriod("1 s"))
+	assert.Equal(t, int64(0), settingPeriod("100/s"))
+	assert.Equal(t, int64(0), settingPeriod("off"))
+	assert.Equal(t, int64(0), settingPeriod("everyChunk"))
+}
+
+func TestCPUTimeSample(t *testing.T) {
+	jfr := readGzipFile(t, testdataDir+"cpu-time-sample.jfr.gz")
+	profiles, err := ParseJFR(jfr, parseInput, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, profiles.ParseMetrics.CPUTimeSamplesFailed)
+	assert.Equal(t, 7, profiles.ParseMetrics.CPUTimeSamplesLost)
+	assert.Equal(t, 0, profiles.ParseMetrics.StacktraceNotFound)
+	// the jdk.ExecutionSample profile is dropped in favour of the CPU-time profile
+	require.Len(t, profiles.Profiles, 1)
+	p := profiles.Profiles[0].Profile
+	assert.Equal(t, "process_cpu", profiles.Profiles[0].Metric)
+	require.Len(t, p.SampleType, 1)
+	assert.Equal(t, "nanoseconds", p.StringTable[p.SampleType[0].Unit])
+
+	totals := map[string]int64{}
+	for _, s := range p.Sample {
+		leaf := p.StringTable[p.Function[p.Location[s.LocationId[0]-1].Line[0].FunctionId-1].Name]
+		totals[leaf] += s.Value[0]
+	}
+	// 5 and 3 samples with a 10ms samplingPeriod, 4 without which use the 20ms throttle
+	assert.Equal(t, map[string]int64{
+		"com/example/App.hash": 50_000_000,
+		"com/example/App.sort": 110_000_000,
+	}, totals)
+}
+
+func TestNativeProfile(t *testing.T) {
+	jfr := readGzipFile(t, testdataDir+"event-with-type-zero.jfr.gz")
+	profiles, err := ParseJFR(jfr, parseInput, nil, WithNativeProfile(true))
+	require.NoError(t, err)
+
+	totals := map[string]int64{}
+	for _, p := range profiles.Profiles {
+		for _, s := range p.Profile.Sample {
+			totals[p.Metric] += s.Value[0]
+			if p.Metric == "native" {
+				// the recording samples native methods every 20 ms, independent of the sample rate
+				assert.Zero(t, s.Value[0]%20_000_000)
+			}
+		}
+	}
+	assert.NotZero(t, totals["native"])
+	assert.GreaterOrEqual(t, totals["wall"], totals["native"])
+
+	profiles, err = ParseJFR(jfr, parseInput, nil)
+	require.NoError(t, err)
+	for _, p := range profiles.Profiles {
+		assert.NotEqual(t, "native", p.Metric)
+	}
+}
+
+func TestRecordingInfo(t *testing.T) {
+	jfr := readGzipFile(t, testdataDir+"async-profiler.jfr.gz")
+	profiles, err := ParseJFR(jfr, parseInput, nil, WithRecordingInfo(true))
+	require.NoError(t, err)
+	require.NotNil(t, profiles.RecordingInfo)
+	assert.Equal(t, uint64(6574), profiles.RecordingInfo.JVM.PID)
+	assert.Contains(t, profiles.RecordingInfo.JVM.Arguments, "-Dservice.type=grpc")
+
+	profiles, err = ParseJFR(jfr, parseInput, nil)
+	require.NoError(t, err)
+	assert.Nil(t, profiles.RecordingInfo)
+}
+
+func TestExceptionDetail(t *testing.T) {
+	jfr := readGzipFile(t, testdataDir+"event-with-type-zero.jfr.gz")
+	exceptions := func(opts ...Option) *profilev1.Profile {
+		profiles, err := ParseJFR(jfr, parseInput, nil, opts...)
+		require.NoError(t, err)
+		for _, p := range profiles.Profiles {
+			if p.Metric == "exceptions" {
+				return p.Profile
+			}
+		}
+		require.Fail(t, "no exceptions profile")
+		return nil
+	}
+	total := func(p *profilev1.Profile) int64 {
+		res := int64(0)
+		for _, s := range p.Sample {
+			res += s.Value[0]
+		}
+		return res
+	}
+
+	plain := exceptions()
+	require.NotEmpty(t, plain.Sample)
+
+	frames := exceptions(WithExceptionDetail(ExceptionDetailFrame, 20))
+	assert.Equal(t, total(plain), total(frames))
+	for _, s := range frames.Sample {
+		leaf := frames.StringTable[frames.Function[frames.Location[s.LocationId[0]-1].Line[0].FunctionId-1].Name]
+		assert.NotContains(t, leaf, "/")
+		assert.Regexp(t, `^[\w.$]+(: .{1,20}(\.\.\.)?)?$`, leaf)
+	}
+
+	labels := exceptions(WithExceptionDetail(ExceptionDetailLabel, 0))
+	assert.Equal(t, total(plain), total(labels))
+	for _, s := range labels.Sample {
+		var class string
+		for _, l := range s.Label {
+			assert.NotEqual(t, LabelExceptionMessage, labels.StringTable[l.Key])
+			if labels.StringTable[l.Key] == LabelExceptionClass {
+				class = labels.StringTable[l.Str]
+			}
+		}
+		assert.NotEmpty(t, class)
+	}
+}
+
+func TestIOLabels(t *testing.T) {
+	for _, tc := range []struct {
+		jfr    string
+		metric string
+		label  string
+	}{
+		{"dd-trace-java", "socket_io", LabelIOHost},
+		{"uint64-ref-id", "file_io", LabelIOPath},
+	} {
+		t.Run(tc.jfr, func(t *testing.T) {
+			jfr := readGzipFile(t, testdataDir+tc.jfr+".jfr.gz")
+			profiles, err := ParseJFR(jfr, parseInput, nil, WithIOLabels(10))
+			require.NoError(t, err)
+			var p *profilev1.Profile
+			for _, prof := range profiles.Profiles {
+				if prof.Metric == tc.metric {
+					p = prof.Profile
+				}
+			}
+			require.NotNil(t, p)
+			require.Len(t, p.SampleType, 3)
+			assert.Equal(t, "io_delay", p.StringTable[p.SampleType[2].Type])
+			require.Len(t, p.Sample, 1)
+			s := p.Sample[0]
+			assert.Equal(t, int64(1), s.Value[0])
+			assert.Positive(t, s.Value[2])
+			values := map[string]string{}
+			for _, l := range s.Label {
+				values[p.StringTable[l.Key]] = p.StringTable[l.Str]
+			}
+			assert.NotEmpty(t, values[tc.label])
+		})
+	}
+}
+
+func TestIOLabelLimit(t *testing.T) {
+	b := &jfrPprofBuilders{opt: &pprofOptions{ioLabelLimit: 2}}
+	assert.Equal(t, "a:1", b.ioLabelValue(sampleTypeSocketIO, "a:1"))
+	assert.Equal(t, "b:1", b.ioLabelValue(sampleTypeSocketIO, "b:1"))
+	assert.Equal(t, "other", b.ioLabelValue(sampleTypeSocketIO, "c:1"))
+	assert.Equal(t, "a:1", b.ioLabelValue(sampleTypeSocketIO, "a:1"))
+	assert.Equal(t, "/c", b.ioLabelValue(sampleTypeFileIO, "/c"))
+	assert.Equal(t, "", b.ioLabelValue(sampleTypeFileIO, ""))
+
+	assert.Equal(t, "example.com:443", hostPort("example.com", "1.2.3.4", 443))
+	assert.Equal(t, "[::1]:80", hostPort("", "::1", 80))
+	assert.Equal(t, "", hostPort("", "", 80))
+}
+
+func TestThreadKindLabel(t *testing.T) {
+	totals := func(jfr []byte, opts ...Option) map[string]int64 {
+		profiles, err := ParseJFR(jfr, parseInput, nil, opts...)
+		require.NoError(t, err)
+		res := map[string]int64{}
+		for _, p := range profiles.Profiles {
+			if p.Metric != "process_cpu" {
+				continue
+			}
+			for _, s := range p.Profile.Sample {
+				kind := ""
+				for _, l := range s.Label {
+					if p.Profile.StringTable[l.Key] == LabelThreadKind {
+						kind = p.Profile.StringTable[l.Str]
+					}
+				}
+				res[kind] += s.Value[0]
+			}
+		}
+		return res
+	}
+	jfr := readGzipFile(t, testdataDir+"event-with-type-zero.jfr.gz")
+	plain := totals(jfr)
+	require.NotZero(t, plain[""])
+	assert.Equal(t, map[string]int64{"platform": plain[""]}, totals(jfr, WithThreadKindLabel(true)))
+
+	// 4 samples of the main thread, 5 runnable and 1 sleeping sample of two virtual threads
+	jfr = readGzipFile(t, testdataDir+"virtual-threads.jfr.gz")
+	assert.Equal(t, map[string]int64{"": 90_000_000}, totals(jfr))
+	assert.Equal(t, map[string]int64{
+		"platform": 40_000_000,
+		"virtual":  50_000_000,
+	}, totals(jfr, WithThreadKindLabel(true)))
+}
+
+func TestVirtualThreadPinned(t *testing.T) {
+	jfr := readGzipFile(t, testdataDir+"virtual-threads.jfr.gz")
+	profiles, err := ParseJFR(jfr, parseInput, nil)
+	require.NoError(t, err)
+	var pinned *profilev1.Profile
+	for _, p := range profiles.Profiles {
+		if p.Metric == "virtual_thread_pinned" {
+			pinned = p.Profile
+		}
+	}
+	require.NotNil(t, pinned)
+	require.Len(t, pinned.SampleType, 2)
+	assert.Equal(t, "pinned", pinned.StringTable[pinned.SampleType[0].Type])
+	assert.Equal(t, "nanoseconds", pinned.StringTable[pinned.SampleType[1].Unit])
+
+	var count, duration int64
+	for _, s := range pinned.Sample {
+		leaf := pinned.StringTable[pinned.Function[pinned.Location[s.LocationId[0]-1].Line[0].FunctionId-1].Name]
+		assert.Equal(t, "java/lang/VirtualThread.parkOnCarrierThread", leaf)
+		count += s.Value[0]
+		duration += s.Value[1]
+	}
+	// pinned for 25ms and 15ms
+	assert.Equal(t, int64(2), count)
+	assert.Equal(t, int64(40_000_000), duration)
+}
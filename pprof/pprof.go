@@ -1,8 +1,14 @@
 package pprof
 
 import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
 	"github.com/grafana/jfr-parser/parser"
 	"github.com/grafana/jfr-parser/parser/types"
+	"github.com/grafana/jfr-parser/parser/types/def"
 )
 
 const (
@@ -15,9 +21,37 @@ const (
 	sampleTypeLiveObject  = 6
 	sampleTypeAllocSample = 7
 	sampleTypeMalloc      = 8
+	// sampleTypeCPUTime is jdk.CPUTimeSample, which goes to the process_cpu profile
+	// with values already weighted in nanoseconds.
+	sampleTypeCPUTime   = 9
+	sampleTypeNative    = 10
+	sampleTypeException = 11
+	sampleTypeSocketIO  = 12
+	sampleTypeFileIO    = 13
+	sampleTypePinned    = 14
+)
+
+const (
+	LabelExceptionClass   = "exception_class"
+	LabelExceptionMessage = "exception_message"
+	// LabelIOHost is the remote host:port of socket I/O samples, see WithIOLabels.
+	LabelIOHost = "io_host"
+	// LabelIOPath is the file path of file I/O samples, see WithIOLabels.
+	LabelIOPath = "io_path"
+	// ioLabelOther replaces I/O label values above the WithIOLabels cap.
+	ioLabelOther = "other"
+	// LabelThreadKind is "virtual" for samples of virtual threads, which run on a carrier
+	// thread, and "platform" otherwise, see WithThreadKindLabel.
+	LabelThreadKind = "thread_kind"
+	// LabelThreadName is the name of the thread of a sample, see WithThreadNameLabel.
+	LabelThreadName = "thread_name"
+
+	// defaultNativePeriod is the JDK default period of jdk.NativeMethodSample, used until the
+	// recording's period setting is read.
+	defaultNativePeriod = int64(20 * time.Millisecond)
 )
 
-func newJfrPprofBuilders(p *parser.Parser, jfrLabels *LabelsSnapshot, piOriginal *ParseInput, opt *pprofOptions) *jfrPprofBuilders {
+func newJfrPprofBuilders(p *parser.Parser, jfrLabels *LabelsSnapshot, piOriginal *ParseInput, sink Sink, opt *pprofOptions) *jfrPprofBuilders {
 	st := piOriginal.StartTime.UnixNano()
 	et := piOriginal.EndTime.UnixNano()
 	var period int64
@@ -29,152 +63,564 @@ func newJfrPprofBuilders(p *parser.Parser, jfrLabels *LabelsSnapshot, piOriginal
 
 	res := &jfrPprofBuilders{
 		parser:        p,
-		builders:      make(map[int64]*ProfileBuilder),
+		sink:          sink,
+		profiles:      make(map[int64]*sinkProfile),
 		jfrLabels:     jfrLabels,
 		timeNanos:     st,
 		durationNanos: et - st,
 		period:        period,
+		nativePeriod:  defaultNativePeriod,
 		opt:           opt,
+		units:         make(map[def.TypeID]eventUnits),
+	}
+	if ts, ok := sink.(TimestampSink); ok {
+		res.timestamps = ts.Timestamps()
 	}
 	return res
 }
 
 type jfrPprofBuilders struct {
 	parser        *parser.Parser
-	builders      map[int64]*ProfileBuilder
+	sink          Sink
+	profiles      map[int64]*sinkProfile
 	jfrLabels     *LabelsSnapshot
 	timeNanos     int64
 	durationNanos int64
 	period        int64
 	opt           *pprofOptions
 
+	// cpuTimePeriod is the jdk.CPUTimeSample throttle period, used when a sample
+	// does not carry its samplingPeriod.
+	cpuTimePeriod int64
+	// nativePeriod is the jdk.NativeMethodSample period setting.
+	nativePeriod int64
+
+	// ioLabelValues holds the distinct I/O label values per sample type, bounded by opt.ioLabelLimit.
+	ioLabelValues map[int64]map[string]struct{}
+
+	// header and units are the header and the event field units of the current chunk,
+	// units are resolved once per event type and chunk.
+	header parser.ChunkHeader
+	units  map[def.TypeID]eventUnits
+
+	// timestamps is set if the sink uses Sample.TimestampNanos, see TimestampSink.
+	timestamps bool
+
+	// stackIDs is the last Sample.StackID handed out.
+	stackIDs uint64
+	// stackBlock and locationBlock back the stacks and their locations. Sinks may retain
+	// Sample.Locations, a block is never reused.
+	stackBlock    []sinkStack
+	locationBlock []uint64
+	// sample and values are reused for every event, sinks don't retain Sample.Values
+	sample  Sample
+	values  [3]int64
 	metrics ParseMetrics
 }
 
-func (b *jfrPprofBuilders) addStacktrace(sampleType int64, correlation StacktraceCorrelation, ref types.StackTraceRef, values []int64) {
-	p := b.profileBuilderForSampleType(sampleType)
-	st := b.parser.GetStacktrace(ref)
-	if st == nil {
-		b.metrics.StacktraceNotFound++
-		return
+// eventUnits are the units of the fields of an event type the walk converts to nanoseconds.
+type eventUnits struct {
+	start          def.Unit
+	duration       def.Unit
+	samplingPeriod def.Unit
+}
+
+// sinkProfile holds the ids a sink returned for a profile.
+type sinkProfile struct {
+	id        int
+	functions map[functionKey]uint64
+	locations map[locationKey]uint64
+	stacks    map[sampleID]*sinkStack
+
+	truncatedLoc  uint64
+	syntheticLocs map[string]uint64
+}
+
+type functionKey struct {
+	method types.MethodRef
+	// frameType is only set with WithFrameTypes, the frames of a method are then named per type.
+	frameType types.FrameTypeRef
+}
+
+type locationKey struct {
+	functionKey
+	line uint32
+}
+
+// sinkStack is the part of a sample that is the same for all events of a sampleID.
+type sinkStack struct {
+	id        uint64
+	locations []uint64
+	labels    []Label
+}
+
+func (b *jfrPprofBuilders) addStacktrace(ts int64, sampleType int64, correlation StacktraceCorrelation, ref types.StackTraceRef, thread types.ThreadRef, values []int64) {
+	b.addStacktraceWithLabel(ts, sampleType, correlation, ref, thread, values, "", "")
+}
+
+// addException adds a jdk.JavaExceptionThrow or jdk.JavaErrorThrow sample. Depending on
+// the options the thrown class and message become the leaf frame or sample labels.
+func (b *jfrPprofBuilders) addException(ts int64, ref types.StackTraceRef, thread types.ThreadRef, class types.ClassRef, message string) {
+	p := b.profileForSampleType(sampleTypeException)
+	var className string
+	if b.opt.exceptionDetail != ExceptionDetailNone {
+		className = b.className(class)
+		message = truncateMessage(message, b.opt.exceptionMessageLength)
+	} else {
+		message = ""
+	}
+
+	id := sampleID{locationsID: uint64(ref), thread: b.threadID(thread), detail: className + "\x00" + message}
+	stack := p.stacks[id]
+	if stack == nil {
+		st := b.getStacktrace(ref)
+		if st == nil {
+			return
+		}
+		locations := b.locations(p, st)
+		if b.opt.exceptionDetail == ExceptionDetailFrame && className != "" {
+			frame := className
+			if message != "" {
+				frame += ": " + message
+			}
+			// pprof locations are leaf first
+			locations = append([]uint64{b.syntheticLocation(p, frame)}, locations...)
+		}
+		var labels []Label
+		if b.opt.exceptionDetail == ExceptionDetailLabel && className != "" {
+			labels = append(labels, Label{Key: LabelExceptionClass, Value: className})
+			if message != "" {
+				labels = append(labels, Label{Key: LabelExceptionMessage, Value: message})
+			}
+		}
+		labels = b.appendThreadName(labels, thread)
+		stack = b.addStack(p, id, locations, labels)
 	}
+	// every exception counts once, addSample copies the values into b.values anyway
+	b.values[0] = 1
+	b.addSample(ts, p, stack, b.values[:1])
+}
 
-	addValues := func(dst []int64) {
-		mul := 1
-		if sampleType == sampleTypeCPU || sampleType == sampleTypeWall {
-			mul = int(b.period)
+// addStacktraceWithLabel is addStacktrace for samples that carry an additional label.
+// Samples of the same stack with different label values are kept apart. An empty value adds no label.
+func (b *jfrPprofBuilders) addStacktraceWithLabel(ts int64, sampleType int64, correlation StacktraceCorrelation, ref types.StackTraceRef, thread types.ThreadRef, values []int64, key, value string) {
+	p := b.profileForSampleType(sampleType)
+	id := sampleID{locationsID: uint64(ref), correlation: correlation, thread: b.threadID(thread), detail: value}
+	stack := p.stacks[id]
+	if stack == nil {
+		st := b.getStacktrace(ref)
+		if st == nil {
+			return
 		}
-		for i, value := range values {
-			dst[i] += value * int64(mul)
+		labels := b.labels(correlation)
+		if value != "" {
+			labels = append(labels, Label{Key: key, Value: value})
 		}
+		labels = b.appendThreadName(labels, thread)
+		stack = b.addStack(p, id, b.locations(p, st), labels)
+	}
+	b.addSample(ts, p, stack, values)
+}
+
+// addIO adds a socket or file I/O sample with the values count, bytes and delay.
+// target is the host:port or path, added as a label if WithIOLabels is enabled.
+func (b *jfrPprofBuilders) addIO(ts int64, sampleType int64, ref types.StackTraceRef, thread types.ThreadRef, target string, values []int64) {
+	if b.opt.ioLabelLimit <= 0 {
+		b.addStacktrace(ts, sampleType, StacktraceCorrelation{}, ref, thread, values)
+		return
 	}
+	key := LabelIOHost
+	if sampleType == sampleTypeFileIO {
+		key = LabelIOPath
+	}
+	b.addStacktraceWithLabel(ts, sampleType, StacktraceCorrelation{}, ref, thread, values, key, b.ioLabelValue(sampleType, target))
+}
 
-	sample := p.FindExternalSampleWithCorrelation(uint64(ref), correlation)
-	if sample != nil {
-		addValues(sample.Value)
+// addExecutionSample adds a CPU or wall sample, labeled with the thread kind if WithThreadKindLabel is enabled.
+func (b *jfrPprofBuilders) addExecutionSample(ts int64, sampleType int64, correlation StacktraceCorrelation, ref types.StackTraceRef, thread types.ThreadRef, values []int64) {
+	if !b.opt.threadKindLabel {
+		b.addStacktrace(ts, sampleType, correlation, ref, thread, values)
 		return
 	}
+	kind := "platform"
+	if t := b.parser.GetThread(thread); t != nil && t.Virtual {
+		kind = "virtual"
+	}
+	b.addStacktraceWithLabel(ts, sampleType, correlation, ref, thread, values, LabelThreadKind, kind)
+}
+
+// threadID returns the part of a sampleID that keeps the samples of threads apart, 0 without WithThreadNameLabel.
+func (b *jfrPprofBuilders) threadID(thread types.ThreadRef) uint64 {
+	if !b.opt.threadNameLabel {
+		return 0
+	}
+	return uint64(thread)
+}
 
+// appendThreadName adds the thread_name label if WithThreadNameLabel is enabled.
+func (b *jfrPprofBuilders) appendThreadName(labels []Label, thread types.ThreadRef) []Label {
+	if !b.opt.threadNameLabel {
+		return labels
+	}
+	return append(labels, Label{Key: LabelThreadName, Value: b.threadName(thread)})
+}
+
+func (b *jfrPprofBuilders) threadName(ref types.ThreadRef) string {
+	t := b.parser.GetThread(ref)
+	switch {
+	case t == nil:
+		return "unknown thread"
+	case t.JavaName != "":
+		return t.JavaName
+	case t.OsName != "":
+		return t.OsName
+	default:
+		return fmt.Sprintf("tid=%d", t.OsThreadId)
+	}
+}
+
+// ioLabelValue returns v, or "other" once the profile already has opt.ioLabelLimit distinct values.
+func (b *jfrPprofBuilders) ioLabelValue(sampleType int64, v string) string {
+	if v == "" {
+		return ""
+	}
+	if b.ioLabelValues == nil {
+		b.ioLabelValues = make(map[int64]map[string]struct{})
+	}
+	seen := b.ioLabelValues[sampleType]
+	if seen == nil {
+		seen = make(map[string]struct{})
+		b.ioLabelValues[sampleType] = seen
+	}
+	if _, ok := seen[v]; ok {
+		return v
+	}
+	if len(seen) >= b.opt.ioLabelLimit {
+		return ioLabelOther
+	}
+	seen[v] = struct{}{}
+	return v
+}
+
+// onChunk is the parser.Options.OnChunk hook of the walk.
+func (b *jfrPprofBuilders) onChunk(h parser.ChunkHeader) {
+	b.header = h
+	clear(b.units)
+}
+
+// eventUnits returns the units of event type typ in the current chunk.
+func (b *jfrPprofBuilders) eventUnits(typ def.TypeID) eventUnits {
+	if u, ok := b.units[typ]; ok {
+		return u
+	}
+	u := eventUnits{
+		start:          b.parser.FieldUnit(typ, "startTime"),
+		duration:       b.parser.FieldUnit(typ, "duration"),
+		samplingPeriod: b.parser.FieldUnit(typ, "samplingPeriod"),
+	}
+	// like parser.TimestampNanos, start times without a unit are ticks
+	if u.start.Value == "" {
+		u.start = def.Unit{Value: def.UnitTicks}
+	}
+	b.units[typ] = u
+	return u
+}
+
+// startNanos converts the startTime of an event of type typ to nanoseconds since epoch,
+// it returns 0 if the sink does not use timestamps.
+func (b *jfrPprofBuilders) startNanos(typ def.TypeID, v uint64) int64 {
+	if !b.timestamps {
+		return 0
+	}
+	return b.header.TimestampNanos(b.eventUnits(typ).start, int64(v))
+}
+
+// eventDurationNanos converts the duration of an event of type typ to nanoseconds.
+func (b *jfrPprofBuilders) eventDurationNanos(typ def.TypeID, v uint64) int64 {
+	return b.header.TimespanNanos(b.eventUnits(typ).duration, int64(v))
+}
+
+// samplingPeriodNanos converts the samplingPeriod of an event of type typ to nanoseconds.
+func (b *jfrPprofBuilders) samplingPeriodNanos(typ def.TypeID, v uint64) int64 {
+	return b.header.TimespanNanos(b.eventUnits(typ).samplingPeriod, int64(v))
+}
+
+func (b *jfrPprofBuilders) getStacktrace(ref types.StackTraceRef) *types.StackTrace {
+	st := b.parser.GetStacktrace(ref)
+	// 0 is the null reference of events recorded without a stack trace
+	if st == nil && ref != 0 {
+		b.metrics.StacktraceNotFound++
+	}
+	return st
+}
+
+func (b *jfrPprofBuilders) addStack(p *sinkProfile, id sampleID, locations []uint64, labels []Label) *sinkStack {
+	b.stackIDs++
+	if len(b.stackBlock) == cap(b.stackBlock) {
+		b.stackBlock = make([]sinkStack, 0, 64)
+	}
+	b.stackBlock = append(b.stackBlock, sinkStack{id: b.stackIDs, locations: locations, labels: labels})
+	stack := &b.stackBlock[len(b.stackBlock)-1]
+	p.stacks[id] = stack
+	return stack
+}
+
+// newLocations returns an empty slice with capacity n from the current location block.
+func (b *jfrPprofBuilders) newLocations(n int) []uint64 {
+	if len(b.locationBlock)+n > cap(b.locationBlock) {
+		b.locationBlock = make([]uint64, 0, max(1024, n))
+	}
+	i := len(b.locationBlock)
+	b.locationBlock = b.locationBlock[:i+n]
+	return b.locationBlock[i : i : i+n]
+}
+
+func (b *jfrPprofBuilders) addSample(ts int64, p *sinkProfile, stack *sinkStack, values []int64) {
+	n := copy(b.values[:], values)
+	b.sample = Sample{
+		Locations:      stack.locations,
+		Values:         b.values[:n],
+		Labels:         stack.labels,
+		TimestampNanos: ts,
+		StackID:        stack.id,
+	}
+	b.sink.AddSample(p.id, &b.sample)
+}
+
+// labels returns the context and span labels of a sample, labels are only added with a LabelsSnapshot.
+func (b *jfrPprofBuilders) labels(correlation StacktraceCorrelation) []Label {
+	if b.jfrLabels == nil {
+		return nil
+	}
+	const LabelProfileId = "profile_id"
+	const LabelSpanName = "span_name"
+	labelsCtx := b.contextLabels(correlation.ContextId)
+	capacity := 0
+	if labelsCtx != nil {
+		capacity += len(labelsCtx.Labels)
+	}
+	if correlation.SpanId != 0 {
+		capacity++
+	}
+	if correlation.SpanName != 0 {
+		capacity++
+	}
+	if capacity == 0 {
+		return nil
+	}
+	labels := make([]Label, 0, capacity)
+	if labelsCtx != nil {
+		for k, v := range labelsCtx.Labels {
+			labels = append(labels, Label{
+				Key:   b.jfrLabels.Strings[k],
+				Value: b.jfrLabels.Strings[v],
+			})
+		}
+	}
+	if correlation.SpanId != 0 {
+		labels = append(labels, Label{Key: LabelProfileId, Value: profileIdString(correlation.SpanId)})
+	}
+	if correlation.SpanName != 0 {
+		spanName := b.jfrLabels.Strings[int64(correlation.SpanName)]
+		if spanName != "" {
+			labels = append(labels, Label{Key: LabelSpanName, Value: spanName})
+		}
+	}
+	return labels
+}
+
+func (b *jfrPprofBuilders) locations(p *sinkProfile, st *types.StackTrace) []uint64 {
 	nLocs := len(st.Frames)
 	if b.opt.truncatedFrame && st.Truncated {
 		nLocs += 1
 	}
-	locations := make([]uint64, 0, nLocs)
+	locations := b.newLocations(nLocs)
 	for i := 0; i < len(st.Frames); i++ {
 		f := st.Frames[i]
-		extLocID := ExternalLocationID{
-			ExternalFunctionID: ExternalFunctionID(f.Method),
-			Line:               f.LineNumber,
+		key := locationKey{functionKey: functionKey{method: f.Method}, line: f.LineNumber}
+		if b.opt.frameTypes {
+			key.frameType = f.Type
 		}
-		loc, found := p.FindLocationByExternalID(extLocID)
+		loc, found := p.locations[key]
 		if found {
-			locations = append(locations, uint64(loc))
+			locations = append(locations, loc)
 			continue
 		}
 		m := b.parser.GetMethod(f.Method)
 		if m != nil {
 
-			pprofFuncID, found := p.FindFunctionByExternalID(extLocID.ExternalFunctionID)
+			funcID, found := p.functions[key.functionKey]
 			if found {
 				// add new location with old function
 			} else {
-				cls := b.parser.GetClass(m.Type)
-				if cls == nil {
+				frame, ok := b.parser.FrameName(m)
+				if !ok {
 					b.metrics.ClassNotFound++
 					continue
 				}
-				clsName := b.parser.GetSymbolString(cls.Name)
-				methodName := b.parser.GetSymbolString(m.Name)
-				frame := clsName + "." + methodName
-				pprofFuncID = p.AddExternalFunction(frame, extLocID.ExternalFunctionID)
+				if b.opt.frameTypes {
+					frame += b.frameTypeSuffix(f.Type)
+				}
+				funcID = b.sink.AddFunction(p.id, frame)
+				p.functions[key.functionKey] = funcID
 			}
-			loc = p.AddExternalLocation(extLocID, pprofFuncID)
-			locations = append(locations, uint64(loc))
+			loc = b.sink.AddLocation(p.id, funcID, int64(key.line))
+			p.locations[key] = loc
+			locations = append(locations, loc)
 		} else {
 			b.metrics.MethodNotFound++
 		}
 	}
 	if b.opt.truncatedFrame && st.Truncated {
-		locations = append(locations, p.getTruncatedLocation())
+		locations = append(locations, b.truncatedLocation(p))
+	}
+	return locations
+}
+
+// frameTypeSuffix returns the async-profiler style suffix of a frame type, see WithFrameTypes.
+func (b *jfrPprofBuilders) frameTypeSuffix(ref types.FrameTypeRef) string {
+	ft := b.parser.GetFrameType(ref)
+	if ft == nil {
+		return ""
+	}
+	switch ft.Description {
+	case "JIT compiled":
+		return "_[j]"
+	case "Inlined":
+		return "_[i]"
+	case "Interpreted":
+		return "_[0]"
+	case "C1 compiled":
+		return "_[1]"
+	case "Kernel":
+		return "_[k]"
+	default:
+		return ""
+	}
+}
+
+func (b *jfrPprofBuilders) truncatedLocation(p *sinkProfile) uint64 {
+	if p.truncatedLoc == 0 {
+		const truncatedFrameName = "[truncated]"
+		p.truncatedLoc = b.sink.AddLocation(p.id, b.sink.AddFunction(p.id, truncatedFrameName), 0)
+	}
+	return p.truncatedLoc
+}
+
+// syntheticLocation returns a location for a frame that is not part of the
+// recorded stack trace, such as the thrown exception class.
+func (b *jfrPprofBuilders) syntheticLocation(p *sinkProfile, frame string) uint64 {
+	if loc, ok := p.syntheticLocs[frame]; ok {
+		return loc
+	}
+	if p.syntheticLocs == nil {
+		p.syntheticLocs = make(map[string]uint64)
 	}
-	vs := make([]int64, len(values))
-	addValues(vs)
-	p.AddExternalSampleWithLabels(locations, vs, b.contextLabels(correlation.ContextId), b.jfrLabels, uint64(ref), correlation)
+	loc := b.sink.AddLocation(p.id, b.sink.AddFunction(p.id, frame), 0)
+	p.syntheticLocs[frame] = loc
+	return loc
 }
 
-func (b *jfrPprofBuilders) profileBuilderForSampleType(sampleType int64) *ProfileBuilder {
-	if builder, ok := b.builders[sampleType]; ok {
-		return builder
+// className returns the thrown class in Java notation, e.g. java.lang.IllegalStateException.
+func (b *jfrPprofBuilders) className(ref types.ClassRef) string {
+	cls := b.parser.GetClass(ref)
+	if cls == nil {
+		return ""
 	}
-	builder := NewProfileBuilderWithLabels(b.timeNanos)
-	builder.DurationNanos = b.durationNanos
-	var metric string
+	return strings.ReplaceAll(b.parser.GetSymbolString(cls.Name), "/", ".")
+}
+
+func truncateMessage(message string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(message) <= n {
+		return message
+	}
+	return string([]rune(message)[:n]) + "..."
+}
+
+func (b *jfrPprofBuilders) profileForSampleType(sampleType int64) *sinkProfile {
+	if p, ok := b.profiles[sampleType]; ok {
+		return p
+	}
+	t := &ProfileType{TimeNanos: b.timeNanos, DurationNanos: b.durationNanos}
 	switch sampleType {
-	case sampleTypeCPU:
-		builder.AddSampleType("cpu", "nanoseconds")
-		builder.PeriodType("cpu", "nanoseconds")
-		metric = "process_cpu"
+	case sampleTypeCPU, sampleTypeCPUTime:
+		t.SampleTypes = append(t.SampleTypes, ValueType{"cpu", "nanoseconds"})
+		t.PeriodType = ValueType{"cpu", "nanoseconds"}
+		t.Metric = "process_cpu"
 	case sampleTypeWall:
-		builder.AddSampleType("wall", "nanoseconds")
-		builder.PeriodType("wall", "nanoseconds")
-		metric = "wall"
+		t.SampleTypes = append(t.SampleTypes, ValueType{"wall", "nanoseconds"})
+		t.PeriodType = ValueType{"wall", "nanoseconds"}
+		t.Metric = "wall"
+	case sampleTypeNative:
+		t.SampleTypes = append(t.SampleTypes, ValueType{"native", "nanoseconds"})
+		t.PeriodType = ValueType{"native", "nanoseconds"}
+		t.Metric = "native"
 	case sampleTypeInTLAB:
-		builder.AddSampleType("alloc_in_new_tlab_objects", "count")
-		builder.AddSampleType("alloc_in_new_tlab_bytes", "bytes")
-		builder.PeriodType("space", "bytes")
-		metric = "memory"
+		t.SampleTypes = append(t.SampleTypes, ValueType{"alloc_in_new_tlab_objects", "count"})
+		t.SampleTypes = append(t.SampleTypes, ValueType{"alloc_in_new_tlab_bytes", "bytes"})
+		t.PeriodType = ValueType{"space", "bytes"}
+		t.Metric = "memory"
 	case sampleTypeOutTLAB:
-		builder.AddSampleType("alloc_outside_tlab_objects", "count")
-		builder.AddSampleType("alloc_outside_tlab_bytes", "bytes")
-		builder.PeriodType("space", "bytes")
-		metric = "memory"
+		t.SampleTypes = append(t.SampleTypes, ValueType{"alloc_outside_tlab_objects", "count"})
+		t.SampleTypes = append(t.SampleTypes, ValueType{"alloc_outside_tlab_bytes", "bytes"})
+		t.PeriodType = ValueType{"space", "bytes"}
+		t.Metric = "memory"
 	case sampleTypeLock:
-		builder.AddSampleType("contentions", "count")
-		builder.AddSampleType("delay", "nanoseconds")
-		builder.PeriodType("mutex", "count")
-		metric = "mutex"
+		t.SampleTypes = append(t.SampleTypes, ValueType{"contentions", "count"})
+		t.SampleTypes = append(t.SampleTypes, ValueType{"delay", "nanoseconds"})
+		t.PeriodType = ValueType{"mutex", "count"}
+		t.Metric = "mutex"
 	case sampleTypeThreadPark:
-		builder.AddSampleType("contentions", "count")
-		builder.AddSampleType("delay", "nanoseconds")
-		builder.PeriodType("block", "count")
-		metric = "block"
+		t.SampleTypes = append(t.SampleTypes, ValueType{"contentions", "count"})
+		t.SampleTypes = append(t.SampleTypes, ValueType{"delay", "nanoseconds"})
+		t.PeriodType = ValueType{"block", "count"}
+		t.Metric = "block"
 	case sampleTypeLiveObject:
-		builder.AddSampleType("live", "count")
-		builder.PeriodType("objects", "count")
-		metric = "memory"
+		t.SampleTypes = append(t.SampleTypes, ValueType{"live", "count"})
+		t.PeriodType = ValueType{"objects", "count"}
+		t.Metric = "memory"
 	case sampleTypeAllocSample:
-		builder.AddSampleType("alloc_sample_objects", "count")
-		builder.AddSampleType("alloc_sample_bytes", "bytes")
-		builder.PeriodType("space", "bytes")
-		metric = "memory"
+		t.SampleTypes = append(t.SampleTypes, ValueType{"alloc_sample_objects", "count"})
+		t.SampleTypes = append(t.SampleTypes, ValueType{"alloc_sample_bytes", "bytes"})
+		t.PeriodType = ValueType{"space", "bytes"}
+		t.Metric = "memory"
 	case sampleTypeMalloc:
-		builder.AddSampleType("malloc_objects", "count")
-		builder.AddSampleType("malloc_bytes", "bytes")
-		metric = "memory"
+		t.SampleTypes = append(t.SampleTypes, ValueType{"malloc_objects", "count"})
+		t.SampleTypes = append(t.SampleTypes, ValueType{"malloc_bytes", "bytes"})
+		t.Metric = "memory"
+	case sampleTypeException:
+		t.SampleTypes = append(t.SampleTypes, ValueType{"exceptions", "count"})
+		t.PeriodType = ValueType{"exceptions", "count"}
+		t.Metric = "exceptions"
+	case sampleTypeSocketIO:
+		t.SampleTypes = append(t.SampleTypes, ValueType{"io_count", "count"})
+		t.SampleTypes = append(t.SampleTypes, ValueType{"io_bytes", "bytes"})
+		t.SampleTypes = append(t.SampleTypes, ValueType{"io_delay", "nanoseconds"})
+		t.PeriodType = ValueType{"socket", "count"}
+		t.Metric = "socket_io"
+	case sampleTypeFileIO:
+		t.SampleTypes = append(t.SampleTypes, ValueType{"io_count", "count"})
+		t.SampleTypes = append(t.SampleTypes, ValueType{"io_bytes", "bytes"})
+		t.SampleTypes = append(t.SampleTypes, ValueType{"io_delay", "nanoseconds"})
+		t.PeriodType = ValueType{"file", "count"}
+		t.Metric = "file_io"
+	case sampleTypePinned:
+		t.SampleTypes = append(t.SampleTypes, ValueType{"pinned", "count"})
+		t.SampleTypes = append(t.SampleTypes, ValueType{"pinned_duration", "nanoseconds"})
+		t.PeriodType = ValueType{"pinned", "count"}
+		t.Metric = "virtual_thread_pinned"
+	}
+	p := &sinkProfile{
+		id:        b.sink.AddProfile(t),
+		functions: make(map[functionKey]uint64),
+		locations: make(map[locationKey]uint64),
+		stacks:    make(map[sampleID]*sinkStack),
 	}
-	builder.MetricName(metric)
-	b.builders[sampleType] = builder
-	return builder
+	b.profiles[sampleType] = p
+	return p
 }
 
 func (b *jfrPprofBuilders) contextLabels(contextID uint64) *Context {
@@ -184,16 +630,17 @@ func (b *jfrPprofBuilders) contextLabels(contextID uint64) *Context {
 	return b.jfrLabels.Contexts[int64(contextID)]
 }
 
-func (b *jfrPprofBuilders) build(jfrEvent string) *Profiles {
-	profiles := make([]Profile, 0, len(b.builders))
-	for _, builder := range b.builders {
-		profiles = append(profiles, Profile{
-			Profile: builder.Profile,
-			Metric:  builder.metricName,
-		})
+func (b *jfrPprofBuilders) finish(jfrEvent string, info *parser.RecordingInfo) error {
+	s := &Summary{
+		JFREvent:      jfrEvent,
+		ParseMetrics:  b.metrics,
+		RecordingInfo: info,
 	}
-	return &Profiles{
-		Profiles: profiles,
-		JFREvent: jfrEvent,
+	if _, ok := b.profiles[sampleTypeCPUTime]; ok {
+		// CPU time samples are more accurate than execution samples, don't report both as process_cpu
+		if p, ok := b.profiles[sampleTypeCPU]; ok {
+			s.Discarded = append(s.Discarded, p.id)
+		}
 	}
+	return b.sink.Finish(s)
 }
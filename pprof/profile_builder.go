@@ -12,13 +12,15 @@ type ProfileBuilder struct {
 	externalFunctionID2FunctionID map[ExternalFunctionID]PPROFFunctionID
 	externalSampleID2SampleIndex  map[sampleID]uint32
 	metricName                    string
-
-	truncatedLoc uint64
 }
 
 type sampleID struct {
 	locationsID uint64
 	correlation StacktraceCorrelation
+	// thread is the thread ref with WithThreadNameLabel
+	thread uint64
+	// detail separates samples of the same stack, e.g. by thrown exception class
+	detail string
 }
 
 // NewProfileBuilderWithLabels creates a new ProfileBuilder with the given nanoseconds timestamp and labels.
@@ -195,14 +197,3 @@ func (m *ProfileBuilder) FindExternalSampleWithCorrelation(locationsID uint64, c
 	sample := m.Profile.Sample[sampleIndex]
 	return sample
 }
-
-func (m *ProfileBuilder) getTruncatedLocation() uint64 {
-	if m.truncatedLoc != 0 {
-		return m.truncatedLoc
-	}
-	const truncatedFrameName = "[truncated]"
-	f := m.addFunction(truncatedFrameName)
-	location := m.addLocation(f, 0)
-	m.truncatedLoc = uint64(location)
-	return m.truncatedLoc
-}
@@ -0,0 +1,294 @@
+package pyroscope
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/jfr-parser/pprof"
+)
+
+// Ingest is a recording uploaded to the ingest handler.
+type Ingest struct {
+	// AppName and Labels are parsed from the name query parameter, e.g. app.cpu{env=dev}.
+	AppName  string
+	Labels   map[string]string
+	SpyName  string
+	Profiles *pprof.Profiles
+}
+
+// IngestFunc receives the profiles of an upload. An error responds with 500.
+type IngestFunc func(ctx context.Context, in *Ingest) error
+
+type ingestOptions struct {
+	maxBytes     int64
+	parseOptions []pprof.Option
+}
+
+type IngestOption func(*ingestOptions)
+
+// WithMaxBytes limits the size of a request body, of the body once decompressed and
+// the total size of the decompressed recording and labels. Larger uploads are rejected
+// with 413. The default is 64 MiB, n <= 0 keeps it.
+func WithMaxBytes(n int64) IngestOption {
+	return func(o *ingestOptions) {
+		if n > 0 {
+			o.maxBytes = n
+		}
+	}
+}
+
+// WithParseOptions sets the options of pprof.ParseJFR.
+func WithParseOptions(opts ...pprof.Option) IngestOption {
+	return func(o *ingestOptions) {
+		o.parseOptions = opts
+	}
+}
+
+// NewIngestHandler returns a handler of the /ingest?format=jfr uploads of the Pyroscope
+// Java agent. The body is the recording, or a multipart form with the recording in
+// the jfr part and a LabelsSnapshot in the labels part. The body, with Content-Encoding
+// gzip, and every part may be gzipped once.
+// The from, until and sampleRate query parameters are the ParseInput of pprof.ParseJFR.
+//
+// Malformed requests are rejected with 400, uploads above WithMaxBytes with 413 and
+// recordings that fail to parse with 422.
+func NewIngestHandler(fn IngestFunc, opts ...IngestOption) http.Handler {
+	o := &ingestOptions{maxBytes: 64 << 20}
+	for i := range opts {
+		opts[i](o)
+	}
+	return &ingestHandler{fn: fn, opt: o}
+}
+
+type ingestHandler struct {
+	fn  IngestFunc
+	opt *ingestOptions
+}
+
+// errTooLarge is returned for uploads above ingestOptions.maxBytes.
+var errTooLarge = errors.New("request body too large")
+
+func (h *ingestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	if f := q.Get("format"); f != "jfr" {
+		http.Error(w, fmt.Sprintf("unsupported format %q", f), http.StatusBadRequest)
+		return
+	}
+	in := &Ingest{SpyName: q.Get("spyName")}
+	var err error
+	if in.AppName, in.Labels, err = ParseName(q.Get("name")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	pi, err := parseInput(q.Get("from"), q.Get("until"), q.Get("sampleRate"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	jfr, labels, err := h.readBody(w, r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, errTooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	var jfrLabels *pprof.LabelsSnapshot
+	if labels != nil {
+		jfrLabels = new(pprof.LabelsSnapshot)
+		if err := jfrLabels.UnmarshalVT(labels); err != nil {
+			http.Error(w, fmt.Sprintf("labels: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	if in.Profiles, err = pprof.ParseJFR(jfr, pi, jfrLabels, h.opt.parseOptions...); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err := h.fn(r.Context(), in); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// readBody returns the recording and the labels of an upload, labels is nil without a labels part.
+func (h *ingestHandler) readBody(w http.ResponseWriter, r *http.Request) (jfr, labels []byte, err error) {
+	body := io.Reader(http.MaxBytesReader(w, r.Body, h.opt.maxBytes))
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, nil, h.bodyError(err)
+		}
+		defer gz.Close()
+		body = &limitReader{r: gz, n: h.opt.maxBytes}
+	}
+
+	// remaining is shared by the parts, it limits their total size
+	remaining := h.opt.maxBytes
+	mediaType, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		jfr, err = h.read(body, &remaining)
+		return jfr, nil, err
+	}
+	mr := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, h.bodyError(err)
+		}
+		switch part.FormName() {
+		case "jfr":
+			jfr, err = h.read(part, &remaining)
+		case "labels":
+			labels, err = h.read(part, &remaining)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if jfr == nil {
+		return nil, nil, errors.New("multipart form has no jfr part")
+	}
+	return jfr, labels, nil
+}
+
+// read reads a recording or labels and decompresses them if they are gzipped. The
+// decompressed bytes are subtracted from remaining, gzip within gzip is rejected.
+func (h *ingestHandler) read(r io.Reader, remaining *int64) ([]byte, error) {
+	lr := &limitReader{r: r, n: *remaining}
+	data, err := io.ReadAll(lr)
+	if err != nil {
+		return nil, h.bodyError(err)
+	}
+	if isGzip(data) {
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		// the compressed bytes don't count towards the total
+		lr = &limitReader{r: gz, n: *remaining}
+		if data, err = io.ReadAll(lr); err != nil {
+			return nil, h.bodyError(err)
+		}
+		if isGzip(data) {
+			return nil, errors.New("nested gzip is not supported")
+		}
+	}
+	*remaining = lr.n
+	return data, nil
+}
+
+func isGzip(data []byte) bool {
+	return len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b
+}
+
+// limitReader returns errTooLarge once more than n bytes are read from r.
+type limitReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	// read at most one byte above the limit to detect larger inputs,
+	// l.n+1 does not overflow as l.n < len(p) here
+	if int64(len(p)) > l.n {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n, errTooLarge
+	}
+	return n, err
+}
+
+func (h *ingestHandler) bodyError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return errTooLarge
+	}
+	return err
+}
+
+// ParseName parses the name query parameter of /ingest, an app name optionally
+// followed by labels in braces, e.g. app.cpu{env=dev,region=eu}.
+func ParseName(name string) (string, map[string]string, error) {
+	labels := map[string]string{}
+	app, rest, ok := strings.Cut(name, "{")
+	if app == "" {
+		return "", nil, fmt.Errorf("invalid name %q: no app name", name)
+	}
+	if !ok {
+		return app, labels, nil
+	}
+	rest, ok = strings.CutSuffix(rest, "}")
+	if !ok {
+		return "", nil, fmt.Errorf("invalid name %q: missing }", name)
+	}
+	if rest == "" {
+		return app, labels, nil
+	}
+	for _, kv := range strings.Split(rest, ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return "", nil, fmt.Errorf("invalid name %q: label %q is not name=value", name, kv)
+		}
+		labels[k] = strings.TrimSpace(v)
+	}
+	return app, labels, nil
+}
+
+// parseInput parses the from and until unix seconds and the sample rate in Hz.
+// A missing from or until is now, a missing sample rate is 100.
+func parseInput(from, until, sampleRate string) (*pprof.ParseInput, error) {
+	now := time.Now()
+	pi := &pprof.ParseInput{StartTime: now, EndTime: now, SampleRate: 100}
+	for _, p := range []struct {
+		name  string
+		value string
+		dst   *time.Time
+	}{{"from", from, &pi.StartTime}, {"until", until, &pi.EndTime}} {
+		if p.value == "" {
+			continue
+		}
+		s, err := strconv.ParseInt(p.value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q", p.name, p.value)
+		}
+		*p.dst = time.Unix(s, 0)
+	}
+	if sampleRate != "" {
+		v, err := strconv.ParseInt(sampleRate, 10, 64)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("invalid sampleRate %q", sampleRate)
+		}
+		pi.SampleRate = v
+	}
+	if pi.EndTime.Before(pi.StartTime) {
+		return nil, fmt.Errorf("until %s is before from %s", until, from)
+	}
+	return pi, nil
+}
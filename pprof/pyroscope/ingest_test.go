@@ -0,0 +1,215 @@
+package pyroscope
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/jfr-parser/internal/testutil"
+)
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func multipartBody(t *testing.T, parts map[string][]byte) (io.Reader, string) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for name, data := range parts {
+		fw, err := w.CreateFormFile(name, name)
+		require.NoError(t, err)
+		_, err = fw.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return &buf, w.FormDataContentType()
+}
+
+func ingestURL(srv *httptest.Server, name string) string {
+	q := url.Values{}
+	q.Set("name", name)
+	q.Set("from", "1706241880")
+	q.Set("until", "1706241890")
+	q.Set("format", "jfr")
+	q.Set("sampleRate", "100")
+	q.Set("spyName", "javaspy")
+	return srv.URL + "/ingest?" + q.Encode()
+}
+
+func post(t *testing.T, u, contentType string, body io.Reader, header http.Header) (int, string) {
+	r, err := http.NewRequest(http.MethodPost, u, body)
+	require.NoError(t, err)
+	r.Header = header.Clone()
+	if r.Header == nil {
+		r.Header = http.Header{}
+	}
+	r.Header.Set("Content-Type", contentType)
+	res, err := http.DefaultClient.Do(r)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	msg, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return res.StatusCode, string(msg)
+}
+
+func TestIngestHandler(t *testing.T) {
+	jfr := testutil.ReadGzipFile(t, "dump2.jfr.gz")
+	labels := testutil.ReadGzipFile(t, "dump2.labels.pb.gz")
+
+	var received *Ingest
+	srv := httptest.NewServer(NewIngestHandler(func(ctx context.Context, in *Ingest) error {
+		received = in
+		return nil
+	}))
+	defer srv.Close()
+
+	// labels and a gzipped recording as multipart form
+	body, contentType := multipartBody(t, map[string][]byte{"jfr": gzipBytes(t, jfr), "labels": labels})
+	status, msg := post(t, ingestURL(srv, "app.cpu{env=dev,region=eu}"), contentType, body, nil)
+	require.Equal(t, http.StatusOK, status, msg)
+	require.NotNil(t, received)
+	assert.Equal(t, "app.cpu", received.AppName)
+	assert.Equal(t, map[string]string{"env": "dev", "region": "eu"}, received.Labels)
+	assert.Equal(t, "javaspy", received.SpyName)
+	require.NotEmpty(t, received.Profiles.Profiles)
+	labeled := 0
+	for _, p := range received.Profiles.Profiles {
+		assert.Equal(t, time.Unix(1706241880, 0).UnixNano(), p.Profile.TimeNanos)
+		assert.Equal(t, (10 * time.Second).Nanoseconds(), p.Profile.DurationNanos)
+		for _, s := range p.Profile.Sample {
+			if len(s.Label) > 0 {
+				labeled++
+			}
+		}
+	}
+	assert.Positive(t, labeled)
+
+	// a gzipped request body with just the recording
+	received = nil
+	status, msg = post(t, ingestURL(srv, "app"), "application/octet-stream", bytes.NewReader(gzipBytes(t, jfr)), http.Header{"Content-Encoding": {"gzip"}})
+	require.Equal(t, http.StatusOK, status, msg)
+	require.NotNil(t, received)
+	assert.Empty(t, received.Labels)
+	assert.Equal(t, len(received.Profiles.Profiles), 4)
+}
+
+func TestIngestHandlerErrors(t *testing.T) {
+	jfr := testutil.ReadGzipFile(t, "example.jfr.gz")
+	var fnErr error
+	srv := httptest.NewServer(NewIngestHandler(func(ctx context.Context, in *Ingest) error {
+		return fnErr
+	}, WithMaxBytes(int64(len(jfr)))))
+	defer srv.Close()
+
+	status, _ := post(t, ingestURL(srv, "app"), "application/octet-stream", bytes.NewReader(jfr), nil)
+	assert.Equal(t, http.StatusOK, status)
+
+	res, err := http.Get(ingestURL(srv, "app"))
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+
+	for _, name := range []string{"", "{env=dev}", "app{env=dev", "app{env}"} {
+		status, msg := post(t, ingestURL(srv, name), "application/octet-stream", bytes.NewReader(jfr), nil)
+		assert.Equal(t, http.StatusBadRequest, status, name)
+		assert.Contains(t, msg, "invalid name", name)
+	}
+
+	u, err := url.Parse(ingestURL(srv, "app"))
+	require.NoError(t, err)
+	q := u.Query()
+	q.Set("from", "yesterday")
+	u.RawQuery = q.Encode()
+	status, msg := post(t, u.String(), "application/octet-stream", bytes.NewReader(jfr), nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, msg, "invalid from")
+
+	status, _ = post(t, srv.URL+"/ingest?name=app&format=pprof", "application/octet-stream", bytes.NewReader(jfr), nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, _ = post(t, ingestURL(srv, "app"), "application/octet-stream", bytes.NewReader(append(jfr, 0)), nil)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, status)
+
+	// a small gzipped body must not decompress above the limit
+	bomb := gzipBytes(t, make([]byte, 2*len(jfr)))
+	status, _ = post(t, ingestURL(srv, "app"), "application/octet-stream", bytes.NewReader(bomb), nil)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, status)
+
+	// gzip is only decompressed once per part
+	nested := gzipBytes(t, gzipBytes(t, make([]byte, 100*len(jfr))))
+	status, msg = post(t, ingestURL(srv, "app"), "application/octet-stream", bytes.NewReader(nested), nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, msg, "nested gzip")
+	body, contentType := multipartBody(t, map[string][]byte{"jfr": nested})
+	status, _ = post(t, ingestURL(srv, "app"), contentType, body, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = post(t, ingestURL(srv, "app"), "application/octet-stream", bytes.NewReader(gzipBytes(t, nested)), http.Header{"Content-Encoding": {"gzip"}})
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	// the limit applies to the total of the parts
+	body, contentType = multipartBody(t, map[string][]byte{"jfr": gzipBytes(t, jfr), "labels": gzipBytes(t, []byte{0})})
+	status, _ = post(t, ingestURL(srv, "app"), contentType, body, nil)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, status)
+
+	body, contentType = multipartBody(t, map[string][]byte{"labels": {}})
+	status, msg = post(t, ingestURL(srv, "app"), contentType, body, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, msg, "no jfr part")
+
+	body, contentType = multipartBody(t, map[string][]byte{"jfr": jfr[:1000], "labels": {0xff}})
+	status, msg = post(t, ingestURL(srv, "app"), contentType, body, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, msg, "labels")
+
+	status, _ = post(t, ingestURL(srv, "app"), "application/octet-stream", bytes.NewReader(jfr[:1000]), nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+
+	fnErr = errors.New("storage unavailable")
+	status, msg = post(t, ingestURL(srv, "app"), "application/octet-stream", bytes.NewReader(jfr), nil)
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Contains(t, msg, "storage unavailable")
+}
+
+func TestIngestHandlerMaxBytes(t *testing.T) {
+	jfr := testutil.ReadGzipFile(t, "example.jfr.gz")
+	for _, n := range []int64{math.MaxInt64, 0, -1} {
+		srv := httptest.NewServer(NewIngestHandler(func(ctx context.Context, in *Ingest) error {
+			return nil
+		}, WithMaxBytes(n)))
+		status, msg := post(t, ingestURL(srv, "app"), "application/octet-stream", bytes.NewReader(gzipBytes(t, jfr)), nil)
+		assert.Equal(t, http.StatusOK, status, "%d: %s", n, msg)
+		body, contentType := multipartBody(t, map[string][]byte{"jfr": gzipBytes(t, jfr)})
+		status, msg = post(t, ingestURL(srv, "app"), contentType, body, nil)
+		assert.Equal(t, http.StatusOK, status, "%d: %s", n, msg)
+		srv.Close()
+	}
+}
+
+func TestParseName(t *testing.T) {
+	app, labels, err := ParseName("app.itimer{ env = dev ,region=eu}")
+	require.NoError(t, err)
+	assert.Equal(t, "app.itimer", app)
+	assert.Equal(t, map[string]string{"env": "dev", "region": "eu"}, labels)
+
+	app, labels, err = ParseName("app{}")
+	require.NoError(t, err)
+	assert.Equal(t, "app", app)
+	assert.Empty(t, labels)
+}
@@ -0,0 +1,128 @@
+package pyroscope
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	pushv1 "github.com/grafana/pyroscope/api/gen/proto/go/push/v1"
+	v1 "github.com/grafana/pyroscope/api/gen/proto/go/types/v1"
+
+	"github.com/grafana/jfr-parser/pprof"
+)
+
+// PushPath is the path of the Pyroscope push API, the connect procedure of PusherService.Push.
+const PushPath = "/push.v1.PusherService/Push"
+
+// NewPushRequest builds the push request of the profiles of a recording. Every profile
+// becomes a raw sample of the series with its Labels, profiles with the same labels,
+// e.g. the memory profiles of TLAB and outside TLAB allocations, share a series.
+// Series labels are sorted by name. Labels set by Labels, e.g. pyroscope_spy or app_name,
+// replace series labels of the same name.
+func NewPushRequest(profiles *pprof.Profiles, seriesLabels map[string]string, appName, spyName string) (*pushv1.PushRequest, error) {
+	req := &pushv1.PushRequest{}
+	series := make(map[string]*pushv1.RawProfileSeries)
+	for _, p := range profiles.Profiles {
+		raw, err := p.Profile.MarshalVT()
+		if err != nil {
+			return nil, fmt.Errorf("marshal %s profile: %w", p.Metric, err)
+		}
+		ls := dedupeLabels(Labels(seriesLabels, profiles.JFREvent, p.Metric, appName, spyName))
+		key := seriesKey(ls)
+		s, ok := series[key]
+		if !ok {
+			s = &pushv1.RawProfileSeries{Labels: ls}
+			series[key] = s
+			req.Series = append(req.Series, s)
+		}
+		s.Samples = append(s.Samples, &pushv1.RawSample{RawProfile: raw})
+	}
+	return req, nil
+}
+
+// dedupeLabels sorts ls by name and keeps the last label of a name. Labels appends its
+// own labels after the series labels, so they take precedence.
+func dedupeLabels(ls []*v1.LabelPair) []*v1.LabelPair {
+	slices.SortStableFunc(ls, func(a, b *v1.LabelPair) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	res := ls[:0]
+	for i, l := range ls {
+		if i+1 < len(ls) && ls[i+1].Name == l.Name {
+			continue
+		}
+		res = append(res, l)
+	}
+	return res
+}
+
+func seriesKey(ls []*v1.LabelPair) string {
+	sb := strings.Builder{}
+	for _, l := range ls {
+		sb.WriteString(l.Name)
+		sb.WriteByte(0)
+		sb.WriteString(l.Value)
+		sb.WriteByte(0)
+	}
+	return sb.String()
+}
+
+// StatusError is the error of a push the server responded to with a non-2xx status.
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+	// Message is the start of the response body.
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("push to %s: %s: %s", e.URL, e.Status, e.Message)
+}
+
+// CheckResponse returns a StatusError if res has a non-2xx status.
+func CheckResponse(res *http.Response) error {
+	if res.StatusCode/100 == 2 {
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+	return &StatusError{
+		URL:        res.Request.URL.String(),
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+		Message:    string(bytes.TrimSpace(msg)),
+	}
+}
+
+// Push sends req to the push API of the Pyroscope server at baseURL, e.g.
+// http://localhost:4040. header is added to the request, e.g. for authentication.
+func Push(ctx context.Context, client *http.Client, baseURL string, req *pushv1.PushRequest, header http.Header) error {
+	body, err := req.MarshalVT()
+	if err != nil {
+		return fmt.Errorf("marshal push request: %w", err)
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(baseURL, "/")+PushPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, vs := range header {
+		for _, v := range vs {
+			r.Header.Add(k, v)
+		}
+	}
+	r.Header.Set("Content-Type", "application/proto")
+	res, err := client.Do(r)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if err := CheckResponse(res); err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	return nil
+}
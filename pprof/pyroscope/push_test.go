@@ -0,0 +1,152 @@
+package pyroscope
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	profilev1 "github.com/grafana/pyroscope/api/gen/proto/go/google/v1"
+	pushv1 "github.com/grafana/pyroscope/api/gen/proto/go/push/v1"
+	v1 "github.com/grafana/pyroscope/api/gen/proto/go/types/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/jfr-parser/internal/testutil"
+	"github.com/grafana/jfr-parser/pprof"
+)
+
+func parseTestFile(t testing.TB, name string) *pprof.Profiles {
+	body := testutil.ReadGzipFile(t, name+".jfr.gz")
+	profiles, err := pprof.ParseJFR(body, &pprof.ParseInput{
+		StartTime:  time.Unix(1706241880, 0),
+		EndTime:    time.Unix(1706241890, 0),
+		SampleRate: 100,
+	}, nil)
+	require.NoError(t, err)
+	return profiles
+}
+
+func labelMap(ls []*v1.LabelPair) map[string]string {
+	m := make(map[string]string, len(ls))
+	for _, l := range ls {
+		m[l.Name] = l.Value
+	}
+	return m
+}
+
+func TestLabels(t *testing.T) {
+	ls := labelMap(Labels(map[string]string{
+		"env":                "dev",
+		LabelNameProfileName: "ignored",
+		LabelNameSessionID:   "42",
+	}, "cpu", "process_cpu", "app", "javaspy"))
+	assert.Equal(t, map[string]string{
+		"env":                 "dev",
+		LabelNameSessionID:    "42",
+		LabelNamePyroscopeSpy: "javaspy",
+		LabelNameDelta:        "false",
+		LabelNameJfrEvent:     "cpu",
+		LabelNameProfileName:  "process_cpu",
+		LabelNameServiceName:  "app",
+	}, ls)
+
+	// the app name does not replace a service_name series label
+	ls = labelMap(Labels(map[string]string{LabelNameServiceName: "svc"}, "cpu", "process_cpu", "app", "javaspy"))
+	assert.Equal(t, "svc", ls[LabelNameServiceName])
+	assert.Equal(t, "app", ls["app_name"])
+}
+
+func TestNewPushRequest(t *testing.T) {
+	profiles := parseTestFile(t, "async-profiler")
+	req, err := NewPushRequest(profiles, map[string]string{"env": "dev"}, "app", "javaspy")
+	require.NoError(t, err)
+
+	metrics := map[string]int{}
+	for _, p := range profiles.Profiles {
+		metrics[p.Metric]++
+	}
+	require.Equal(t, len(metrics), len(req.Series))
+	samples := 0
+	for _, s := range req.Series {
+		assert.True(t, slices.IsSortedFunc(s.Labels, func(a, b *v1.LabelPair) int {
+			return strings.Compare(a.Name, b.Name)
+		}))
+		ls := labelMap(s.Labels)
+		assert.Equal(t, "dev", ls["env"])
+		assert.Equal(t, "app", ls[LabelNameServiceName])
+		assert.Equal(t, "javaspy", ls[LabelNamePyroscopeSpy])
+		assert.Equal(t, "false", ls[LabelNameDelta])
+		assert.Equal(t, profiles.JFREvent, ls[LabelNameJfrEvent])
+		assert.Equal(t, metrics[ls[LabelNameProfileName]], len(s.Samples))
+		for _, sample := range s.Samples {
+			p := new(profilev1.Profile)
+			require.NoError(t, p.UnmarshalVT(sample.RawProfile))
+			assert.NotEmpty(t, p.Sample)
+		}
+		samples += len(s.Samples)
+	}
+	assert.Equal(t, len(profiles.Profiles), samples)
+}
+
+func TestNewPushRequestDuplicateLabels(t *testing.T) {
+	profiles := parseTestFile(t, "async-profiler")
+	seriesLabels := map[string]string{
+		LabelNameServiceName:  "svc",
+		"app_name":            "series",
+		LabelNamePyroscopeSpy: "series",
+		LabelNameJfrEvent:     "series",
+	}
+	// Labels keeps the series labels and adds its own
+	assert.Len(t, Labels(seriesLabels, profiles.JFREvent, "process_cpu", "app", "javaspy"), 9)
+
+	req, err := NewPushRequest(profiles, seriesLabels, "app", "javaspy")
+	require.NoError(t, err)
+	for _, s := range req.Series {
+		require.Len(t, s.Labels, 6)
+		ls := labelMap(s.Labels)
+		assert.Equal(t, "svc", ls[LabelNameServiceName])
+		assert.Equal(t, "app", ls["app_name"])
+		assert.Equal(t, "javaspy", ls[LabelNamePyroscopeSpy])
+		assert.Equal(t, profiles.JFREvent, ls[LabelNameJfrEvent])
+	}
+}
+
+func TestPush(t *testing.T) {
+	var received *pushv1.PushRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, PushPath, r.URL.Path)
+		assert.Equal(t, "application/proto", r.Header.Get("Content-Type"))
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, `{"code":"unauthenticated","message":"no token"}`, http.StatusUnauthorized)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		received = new(pushv1.PushRequest)
+		require.NoError(t, received.UnmarshalVT(body))
+		w.Header().Set("Content-Type", "application/proto")
+	}))
+	defer srv.Close()
+
+	req, err := NewPushRequest(parseTestFile(t, "example"), nil, "app", "javaspy")
+	require.NoError(t, err)
+
+	err = Push(context.Background(), srv.Client(), srv.URL, req, nil)
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusUnauthorized, statusErr.StatusCode)
+	assert.Equal(t, srv.URL+PushPath, statusErr.URL)
+	require.ErrorContains(t, err, "401 Unauthorized")
+	require.ErrorContains(t, err, "no token")
+	assert.Nil(t, received)
+
+	header := http.Header{"Authorization": {"Bearer token"}}
+	require.NoError(t, Push(context.Background(), srv.Client(), srv.URL+"/", req, header))
+	require.NotNil(t, received)
+	assert.True(t, req.EqualVT(received))
+}
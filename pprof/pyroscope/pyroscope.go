@@ -27,6 +27,9 @@ func IsLabelAllowedForIngestion(name string) bool {
 	return allowed
 }
 
+// Labels returns the labels of the series of a profile. The app name is the service_name
+// label, or app_name if the series labels have a service_name. Series labels are kept
+// even if Labels adds a label of the same name, NewPushRequest removes the duplicates.
 func Labels(seriesLabels map[string]string, jfrEvent, metricName, appName, spyName string) []*v1.LabelPair {
 	ls := make([]*v1.LabelPair, 0, len(seriesLabels)+5)
 	for k, v := range seriesLabels {
@@ -0,0 +1,106 @@
+package pprof
+
+import (
+	"github.com/grafana/jfr-parser/internal/panics"
+	"github.com/grafana/jfr-parser/parser"
+)
+
+// Sink receives the profiles WalkJFR converts a recording to. The walk adds the
+// functions and locations of a profile once and references them by the ids the
+// sink returns, a sink is free to choose the ids.
+//
+// Sinks are not safe for concurrent use, a walk calls them from a single goroutine.
+type Sink interface {
+	// AddProfile starts a profile and returns its id, which the other calls take.
+	AddProfile(t *ProfileType) int
+	// AddFunction adds the function of a frame, e.g. java/lang/Thread.run, and returns its id.
+	AddFunction(profile int, name string) uint64
+	// AddLocation adds a line of a function and returns its id.
+	AddLocation(profile int, function uint64, line int64) uint64
+	// AddSample adds the sample of an event. s and its values are only valid during the
+	// call, Locations and Labels may be retained but not modified.
+	AddSample(profile int, s *Sample)
+	// Finish is called once the last event is read.
+	Finish(s *Summary) error
+}
+
+// TimestampSink is a Sink that uses Sample.TimestampNanos.
+type TimestampSink interface {
+	Sink
+	// Timestamps reports whether the sink uses Sample.TimestampNanos.
+	Timestamps() bool
+}
+
+// ValueType is the type and unit of a sample value, e.g. cpu and nanoseconds.
+type ValueType struct {
+	Type string
+	Unit string
+}
+
+// ProfileType describes a profile of the walk.
+type ProfileType struct {
+	// Metric is the pyroscope metric of the profile, e.g. process_cpu or memory.
+	Metric      string
+	SampleTypes []ValueType
+	// PeriodType is the zero value for profiles without a period type.
+	PeriodType    ValueType
+	TimeNanos     int64
+	DurationNanos int64
+}
+
+// Sample is a stack trace with one value per sample type of its profile.
+type Sample struct {
+	// Locations are location ids, leaf first.
+	Locations []uint64
+	Values    []int64
+	Labels    []Label
+	// TimestampNanos is the start time of the event. It is only set for a TimestampSink,
+	// the walk skips converting the event start times for other sinks.
+	TimestampNanos int64
+	// StackID identifies the locations and labels of the sample within its profile.
+	// Samples with the same StackID have the same Locations and Labels, sinks that
+	// aggregate samples can use it instead of comparing them.
+	StackID uint64
+}
+
+type Label struct {
+	Key   string
+	Value string
+}
+
+// Summary is passed to Sink.Finish.
+type Summary struct {
+	JFREvent     string
+	ParseMetrics ParseMetrics
+	// RecordingInfo is only set with WithRecordingInfo.
+	RecordingInfo *parser.RecordingInfo
+	// Discarded are the ids of profiles that should be dropped. The process_cpu profile of
+	// jdk.ExecutionSample is discarded if the recording also has jdk.CPUTimeSample events.
+	Discarded []int
+}
+
+// WalkJFR converts the events of a JFR recording to profiles and adds them to sink.
+// ParseJFR is WalkJFR with a ProfilesSink.
+func WalkJFR(body []byte, pi *ParseInput, jfrLabels *LabelsSnapshot, sink Sink, opts ...Option) (err error) {
+	o := &pprofOptions{
+		truncatedFrame:       false,
+		disablePanicRecovery: false,
+	}
+	for i := range opts {
+		opts[i](o)
+	}
+
+	if !o.disablePanicRecovery {
+		defer panics.Recover(&err)
+	}
+
+	var builders *jfrPprofBuilders
+	p := parser.NewParser(body, parser.Options{
+		SymbolProcessor: parser.ProcessSymbols,
+		OnChunk: func(h parser.ChunkHeader) {
+			builders.onChunk(h)
+		},
+	})
+	builders = newJfrPprofBuilders(p, jfrLabels, pi, sink, o)
+	return parse(builders, o)
+}
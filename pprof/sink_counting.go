@@ -0,0 +1,33 @@
+package pprof
+
+// CountingSink counts the calls of a walk and discards the samples. It measures
+// the cost of the walk without building profiles, e.g. in benchmarks.
+type CountingSink struct {
+	Profiles  int
+	Functions int
+	Locations int
+	Samples   int
+}
+
+func (s *CountingSink) AddProfile(*ProfileType) int {
+	s.Profiles++
+	return s.Profiles - 1
+}
+
+func (s *CountingSink) AddFunction(int, string) uint64 {
+	s.Functions++
+	return uint64(s.Functions)
+}
+
+func (s *CountingSink) AddLocation(int, uint64, int64) uint64 {
+	s.Locations++
+	return uint64(s.Locations)
+}
+
+func (s *CountingSink) AddSample(int, *Sample) {
+	s.Samples++
+}
+
+func (s *CountingSink) Finish(*Summary) error {
+	return nil
+}
@@ -0,0 +1,100 @@
+package pprof
+
+import (
+	"slices"
+
+	"github.com/google/pprof/profile"
+)
+
+type GoogleProfile struct {
+	Profile *profile.Profile
+	Metric  string
+}
+
+// GoogleSink builds github.com/google/pprof/profile profiles, samples with the
+// same StackID are merged.
+type GoogleSink struct {
+	profiles []GoogleProfile
+	samples  []map[uint64]*profile.Sample
+	result   []GoogleProfile
+}
+
+func NewGoogleSink() *GoogleSink {
+	return &GoogleSink{}
+}
+
+func (s *GoogleSink) AddProfile(t *ProfileType) int {
+	p := &profile.Profile{
+		TimeNanos:     t.TimeNanos,
+		DurationNanos: t.DurationNanos,
+		Mapping:       []*profile.Mapping{{ID: 1, HasFunctions: true}},
+	}
+	for _, st := range t.SampleTypes {
+		p.SampleType = append(p.SampleType, &profile.ValueType{Type: st.Type, Unit: st.Unit})
+	}
+	if t.PeriodType != (ValueType{}) {
+		p.PeriodType = &profile.ValueType{Type: t.PeriodType.Type, Unit: t.PeriodType.Unit}
+	}
+	s.profiles = append(s.profiles, GoogleProfile{Profile: p, Metric: t.Metric})
+	s.samples = append(s.samples, make(map[uint64]*profile.Sample))
+	return len(s.profiles) - 1
+}
+
+func (s *GoogleSink) AddFunction(profileID int, name string) uint64 {
+	p := s.profiles[profileID].Profile
+	id := uint64(len(p.Function)) + 1
+	p.Function = append(p.Function, &profile.Function{ID: id, Name: name})
+	return id
+}
+
+func (s *GoogleSink) AddLocation(profileID int, function uint64, line int64) uint64 {
+	p := s.profiles[profileID].Profile
+	id := uint64(len(p.Location)) + 1
+	p.Location = append(p.Location, &profile.Location{
+		ID:      id,
+		Mapping: p.Mapping[0],
+		Line:    []profile.Line{{Function: p.Function[function-1], Line: line}},
+	})
+	return id
+}
+
+func (s *GoogleSink) AddSample(profileID int, sample *Sample) {
+	if ps, ok := s.samples[profileID][sample.StackID]; ok {
+		for i, v := range sample.Values {
+			ps.Value[i] += v
+		}
+		return
+	}
+	p := s.profiles[profileID].Profile
+	ps := &profile.Sample{
+		Location: make([]*profile.Location, 0, len(sample.Locations)),
+		Value:    slices.Clone(sample.Values),
+	}
+	for _, id := range sample.Locations {
+		ps.Location = append(ps.Location, p.Location[id-1])
+	}
+	if len(sample.Labels) > 0 {
+		ps.Label = make(map[string][]string, len(sample.Labels))
+		for _, l := range sample.Labels {
+			ps.Label[l.Key] = append(ps.Label[l.Key], l.Value)
+		}
+	}
+	s.samples[profileID][sample.StackID] = ps
+	p.Sample = append(p.Sample, ps)
+}
+
+func (s *GoogleSink) Finish(summary *Summary) error {
+	s.result = make([]GoogleProfile, 0, len(s.profiles))
+	for i, p := range s.profiles {
+		if !slices.Contains(summary.Discarded, i) {
+			s.result = append(s.result, p)
+		}
+	}
+	s.samples = nil
+	return nil
+}
+
+// Profiles returns the profiles once the walk finished.
+func (s *GoogleSink) Profiles() []GoogleProfile {
+	return s.result
+}
@@ -0,0 +1,100 @@
+package pprof
+
+import (
+	"slices"
+
+	profilev1 "github.com/grafana/pyroscope/api/gen/proto/go/google/v1"
+)
+
+// ProfilesSink builds pyroscope profilev1 profiles, samples with the same StackID
+// are merged. It is the sink of ParseJFR.
+type ProfilesSink struct {
+	builders []*ProfileBuilder
+	// samples maps the StackID of a sample to its index in the profile, per builder
+	samples []map[uint64]int
+	// values is the block the values of new samples are taken from
+	values   []int64
+	profiles *Profiles
+}
+
+func NewProfilesSink() *ProfilesSink {
+	return &ProfilesSink{}
+}
+
+func (s *ProfilesSink) AddProfile(t *ProfileType) int {
+	b := NewProfileBuilderWithLabels(t.TimeNanos)
+	b.DurationNanos = t.DurationNanos
+	for _, st := range t.SampleTypes {
+		b.AddSampleType(st.Type, st.Unit)
+	}
+	if t.PeriodType != (ValueType{}) {
+		b.PeriodType(t.PeriodType.Type, t.PeriodType.Unit)
+	}
+	b.MetricName(t.Metric)
+	s.builders = append(s.builders, b)
+	s.samples = append(s.samples, make(map[uint64]int))
+	return len(s.builders) - 1
+}
+
+func (s *ProfilesSink) AddFunction(profile int, name string) uint64 {
+	return uint64(s.builders[profile].addFunction(name))
+}
+
+func (s *ProfilesSink) AddLocation(profile int, function uint64, line int64) uint64 {
+	return uint64(s.builders[profile].addLocation(PPROFFunctionID(function), uint32(line)))
+}
+
+func (s *ProfilesSink) AddSample(profile int, sample *Sample) {
+	b := s.builders[profile]
+	if i, ok := s.samples[profile][sample.StackID]; ok {
+		ps := b.Profile.Sample[i]
+		for i, v := range sample.Values {
+			ps.Value[i] += v
+		}
+		return
+	}
+	ps := &profilev1.Sample{LocationId: sample.Locations, Value: s.cloneValues(sample.Values)}
+	s.samples[profile][sample.StackID] = len(b.Profile.Sample)
+	b.Profile.Sample = append(b.Profile.Sample, ps)
+	if len(sample.Labels) > 0 {
+		ps.Label = make([]*profilev1.Label, 0, len(sample.Labels))
+		for _, l := range sample.Labels {
+			ps.Label = append(ps.Label, &profilev1.Label{Key: b.addString(l.Key), Str: b.addString(l.Value)})
+		}
+	}
+}
+
+// cloneValues copies vs into the current block, saving an allocation per sample.
+func (s *ProfilesSink) cloneValues(vs []int64) []int64 {
+	if len(s.values)+len(vs) > cap(s.values) {
+		s.values = make([]int64, 0, max(1024, len(vs)))
+	}
+	n := len(s.values)
+	s.values = append(s.values, vs...)
+	return s.values[n:len(s.values):len(s.values)]
+}
+
+func (s *ProfilesSink) Finish(summary *Summary) error {
+	profiles := make([]Profile, 0, len(s.builders))
+	for i, b := range s.builders {
+		if slices.Contains(summary.Discarded, i) {
+			continue
+		}
+		profiles = append(profiles, Profile{
+			Profile: b.Profile,
+			Metric:  b.metricName,
+		})
+	}
+	s.profiles = &Profiles{
+		Profiles:      profiles,
+		JFREvent:      summary.JFREvent,
+		ParseMetrics:  summary.ParseMetrics,
+		RecordingInfo: summary.RecordingInfo,
+	}
+	return nil
+}
+
+// Profiles returns the profiles once the walk finished.
+func (s *ProfilesSink) Profiles() *Profiles {
+	return s.profiles
+}
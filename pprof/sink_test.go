@@ -0,0 +1,113 @@
+package pprof
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sinkTestFiles = []testdata{
+	{jfr: "example"},
+	{jfr: "async-profiler"},
+	{jfr: "dump2", labels: "dump2.labels.pb.gz"},
+	{jfr: "new_spancontext", labels: "new_spancontext.labels.gz"},
+}
+
+func walkTestFile(t testing.TB, td testdata, sink Sink) {
+	r := heapReader()
+	jfr, _ := r(t, testdataDir+td.jfr+".jfr.gz")
+	ls, _ := readLabels(t, td, r)
+	require.NoError(t, WalkJFR(jfr, parseInput, ls, sink, td.options...))
+}
+
+func TestGoogleSink(t *testing.T) {
+	for _, td := range sinkTestFiles {
+		t.Run(td.jfr, func(t *testing.T) {
+			ps := NewProfilesSink()
+			walkTestFile(t, td, ps)
+			gs := NewGoogleSink()
+			walkTestFile(t, td, gs)
+
+			expected := toGoogleProfiles(t, ps.Profiles().Profiles)
+			actual := gs.Profiles()
+			require.Equal(t, len(expected), len(actual))
+			for i := range expected {
+				assert.Equal(t, expected[i].metric, fmt.Sprintf("%s_%s", actual[i].Metric, sampleTypesToString(actual[i].Profile)))
+				require.NoError(t, actual[i].Profile.CheckValid())
+				assert.Equal(t, expected[i].profile.String(), actual[i].Profile.String())
+			}
+		})
+	}
+}
+
+func TestCountingSink(t *testing.T) {
+	td := testdata{jfr: "async-profiler"}
+	ps := NewProfilesSink()
+	walkTestFile(t, td, ps)
+	cs := &CountingSink{}
+	walkTestFile(t, td, cs)
+
+	functions, locations, samples := 0, 0, 0
+	for _, p := range ps.Profiles().Profiles {
+		functions += len(p.Profile.Function)
+		locations += len(p.Profile.Location)
+		samples += len(p.Profile.Sample)
+	}
+	assert.Equal(t, len(ps.Profiles().Profiles), cs.Profiles)
+	assert.Equal(t, functions, cs.Functions)
+	assert.Equal(t, locations, cs.Locations)
+	// every event is a sample, the profiles merge samples of the same stack
+	assert.Greater(t, cs.Samples, samples)
+}
+
+type timestampSink struct {
+	CountingSink
+	timestamps         bool
+	minNanos, maxNanos int64
+}
+
+func (s *timestampSink) Timestamps() bool {
+	return s.timestamps
+}
+
+func (s *timestampSink) AddSample(_ int, sample *Sample) {
+	if s.minNanos == 0 || sample.TimestampNanos < s.minNanos {
+		s.minNanos = sample.TimestampNanos
+	}
+	s.maxNanos = max(s.maxNanos, sample.TimestampNanos)
+}
+
+func TestSinkTimestamps(t *testing.T) {
+	s := &timestampSink{timestamps: true}
+	walkTestFile(t, testdata{jfr: "async-profiler"}, s)
+	// the recording is a few seconds long and was taken in 2023
+	assert.Greater(t, s.minNanos, int64(1672531200e9))
+	assert.Less(t, s.maxNanos, int64(1704067200e9))
+	assert.Less(t, s.maxNanos-s.minNanos, int64(60e9))
+	assert.Greater(t, s.maxNanos, s.minNanos)
+
+	// start times are not converted for sinks that don't use them
+	s = &timestampSink{}
+	walkTestFile(t, testdata{jfr: "async-profiler"}, s)
+	assert.Zero(t, s.minNanos)
+	assert.Zero(t, s.maxNanos)
+}
+
+func BenchmarkWalkJFR(b *testing.B) {
+	for _, testfile := range testFiles {
+		r := heapReader()
+		b.Run(testfile.jfr, func(b *testing.B) {
+			jfr, _ := r(b, testdataDir+testfile.jfr+".jfr.gz")
+			ls, _ := readLabels(b, testfile, r)
+			b.ResetTimer()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := WalkJFR(jfr, parseInput, ls, &CountingSink{}); err != nil {
+					b.Fatalf("Unable to parse JFR file: %s", err)
+				}
+			}
+		})
+	}
+}
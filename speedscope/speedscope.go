@@ -0,0 +1,288 @@
+// Package speedscope converts JFR recordings into speedscope files
+// (https://www.speedscope.app). Unlike pprof and folded stacks, the sampled
+// profiles of a speedscope file keep the order of samples, one profile per
+// thread and event type.
+package speedscope
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/grafana/jfr-parser/internal/panics"
+	"github.com/grafana/jfr-parser/parser"
+	"github.com/grafana/jfr-parser/parser/types"
+)
+
+const Schema = "https://www.speedscope.app/file-format-schema.json"
+
+type speedscopeOptions struct {
+	disablePanicRecovery bool
+}
+
+type Option func(*speedscopeOptions)
+
+func WithDisablePanicRecovery(v bool) Option {
+	return func(o *speedscopeOptions) {
+		o.disablePanicRecovery = v
+	}
+}
+
+// File is a speedscope file, see https://www.speedscope.app/file-format-schema.json.
+type File struct {
+	Schema   string    `json:"$schema"`
+	Shared   Shared    `json:"shared"`
+	Profiles []Profile `json:"profiles"`
+	Name     string    `json:"name,omitempty"`
+	Exporter string    `json:"exporter,omitempty"`
+}
+
+type Shared struct {
+	Frames []Frame `json:"frames"`
+}
+
+type Frame struct {
+	Name string `json:"name"`
+}
+
+// Profile is a sampled profile. Samples are stacks of indices into
+// Shared.Frames, root first, ordered by the event timestamps.
+type Profile struct {
+	Type       string  `json:"type"`
+	Name       string  `json:"name"`
+	Unit       string  `json:"unit"`
+	StartValue int64   `json:"startValue"`
+	EndValue   int64   `json:"endValue"`
+	Samples    [][]int `json:"samples"`
+	Weights    []int64 `json:"weights"`
+}
+
+// Write writes the file as JSON.
+func (f *File) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(f)
+}
+
+func ParseJFR(body []byte, opts ...Option) (res *File, err error) {
+	o := &speedscopeOptions{}
+	for i := range opts {
+		opts[i](o)
+	}
+	if !o.disablePanicRecovery {
+		defer panics.Recover(&err)
+	}
+	p := parser.NewParser(body, parser.Options{
+		SymbolProcessor: parser.ProcessSymbols,
+	})
+	return parse(p)
+}
+
+func parse(p *parser.Parser) (*File, error) {
+	var event string
+	b := newBuilder(p)
+	for {
+		typ, err := p.ParseEvent()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("jfr parser ParseEvent error: %w", err)
+		}
+		switch typ {
+		case p.TypeMap.T_EXECUTION_SAMPLE:
+			e := &p.ExecutionSample
+			ts := p.TimestampNanos(typ, "startTime", int64(e.StartTime))
+			if s := p.GetThreadState(e.State); s != nil && s.Name != "STATE_SLEEPING" {
+				b.add(eventCPU, ts, e.SampledThread, e.StackTrace, 1)
+			}
+			if event == "wall" {
+				b.add(eventWall, ts, e.SampledThread, e.StackTrace, 1)
+			}
+		case p.TypeMap.T_WALL_CLOCK_SAMPLE:
+			e := &p.WallClockSample
+			b.add(eventWall, p.TimestampNanos(typ, "startTime", int64(e.StartTime)), e.SampledThread, e.StackTrace, int64(e.Samples))
+		case p.TypeMap.T_ALLOC_IN_NEW_TLAB:
+			e := &p.ObjectAllocationInNewTLAB
+			b.add(eventAlloc, p.TimestampNanos(typ, "startTime", int64(e.StartTime)), e.EventThread, e.StackTrace, int64(e.TlabSize))
+		case p.TypeMap.T_ALLOC_OUTSIDE_TLAB:
+			e := &p.ObjectAllocationOutsideTLAB
+			b.add(eventAlloc, p.TimestampNanos(typ, "startTime", int64(e.StartTime)), e.EventThread, e.StackTrace, int64(e.AllocationSize))
+		case p.TypeMap.T_ALLOC_SAMPLE:
+			e := &p.ObjectAllocationSample
+			b.add(eventAlloc, p.TimestampNanos(typ, "startTime", int64(e.StartTime)), e.EventThread, e.StackTrace, int64(e.Weight))
+		case p.TypeMap.T_MONITOR_ENTER:
+			e := &p.JavaMonitorEnter
+			b.add(eventLock, p.TimestampNanos(typ, "startTime", int64(e.StartTime)), e.EventThread, e.StackTrace,
+				p.TimespanNanos(typ, "duration", int64(e.Duration)))
+		case p.TypeMap.T_THREAD_PARK:
+			e := &p.ThreadPark
+			b.add(eventPark, p.TimestampNanos(typ, "startTime", int64(e.StartTime)), e.EventThread, e.StackTrace,
+				p.TimespanNanos(typ, "duration", int64(e.Duration)))
+		case p.TypeMap.T_ACTIVE_SETTING:
+			if p.ActiveSetting.Name == "event" {
+				event = p.ActiveSetting.Value
+			}
+		}
+	}
+	return b.build(), nil
+}
+
+const (
+	eventCPU = iota
+	eventWall
+	eventAlloc
+	eventLock
+	eventPark
+	eventCount
+)
+
+var eventTypes = [eventCount]struct {
+	name string
+	unit string
+}{
+	eventCPU:   {"cpu", "none"},
+	eventWall:  {"wall", "none"},
+	eventAlloc: {"alloc", "bytes"},
+	eventLock:  {"lock", "nanoseconds"},
+	eventPark:  {"park", "nanoseconds"},
+}
+
+type profileKey struct {
+	thread    parser.ThreadKey
+	eventType int
+}
+
+type sample struct {
+	nanos  int64
+	stack  []int
+	weight int64
+}
+
+type builder struct {
+	parser   *parser.Parser
+	frames   []Frame
+	frameIDs map[string]int
+	profiles map[profileKey][]sample
+	// threads holds the name of a thread when its first sample was added
+	threads map[parser.ThreadKey]string
+	stacks  *parser.StackCache[[]int]
+}
+
+func newBuilder(p *parser.Parser) *builder {
+	return &builder{
+		parser:   p,
+		frameIDs: make(map[string]int),
+		profiles: make(map[profileKey][]sample),
+		threads:  make(map[parser.ThreadKey]string),
+		stacks:   parser.NewStackCache[[]int](p),
+	}
+}
+
+func (b *builder) add(eventType int, nanos int64, thread types.ThreadRef, ref types.StackTraceRef, weight int64) {
+	stack, ok := b.stacks.Get(ref, b.resolve)
+	if !ok {
+		return
+	}
+	k := profileKey{thread: b.thread(thread), eventType: eventType}
+	b.profiles[k] = append(b.profiles[k], sample{nanos: nanos, stack: stack, weight: weight})
+}
+
+// resolve returns the frame indices of st, root first.
+func (b *builder) resolve(st *types.StackTrace) []int {
+	stack := make([]int, 0, len(st.Frames))
+	for i := len(st.Frames) - 1; i >= 0; i-- {
+		m := b.parser.GetMethod(st.Frames[i].Method)
+		if m == nil {
+			continue
+		}
+		name, ok := b.parser.FrameName(m)
+		if !ok {
+			continue
+		}
+		id, ok := b.frameIDs[name]
+		if !ok {
+			id = len(b.frames)
+			b.frameIDs[name] = id
+			b.frames = append(b.frames, Frame{Name: name})
+		}
+		stack = append(stack, id)
+	}
+	return stack
+}
+
+// thread returns the key of a thread and records its name.
+func (b *builder) thread(ref types.ThreadRef) parser.ThreadKey {
+	t := b.parser.GetThread(ref)
+	if t == nil {
+		if _, ok := b.threads[parser.ThreadKey{}]; !ok {
+			b.threads[parser.ThreadKey{}] = "unknown thread"
+		}
+		return parser.ThreadKey{}
+	}
+	k := parser.ThreadKeyOf(t)
+	if _, ok := b.threads[k]; !ok {
+		b.threads[k] = threadName(t)
+	}
+	return k
+}
+
+func threadName(t *types.Thread) string {
+	switch {
+	case t.JavaName != "":
+		return t.JavaName
+	case t.OsName != "":
+		return t.OsName
+	case t.JavaThreadId != 0:
+		// the notation of Thread.toString, unnamed virtual threads have neither name
+		return fmt.Sprintf("#%d", t.JavaThreadId)
+	default:
+		return fmt.Sprintf("tid=%d", t.OsThreadId)
+	}
+}
+
+// build returns the profiles ordered by thread name, thread id and event type.
+func (b *builder) build() *File {
+	res := &File{
+		Schema:   Schema,
+		Shared:   Shared{Frames: b.frames},
+		Exporter: "jfr-parser",
+	}
+	if res.Shared.Frames == nil {
+		res.Shared.Frames = []Frame{}
+	}
+	keys := make([]profileKey, 0, len(b.profiles))
+	for k := range b.profiles {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(x, y profileKey) int {
+		if c := strings.Compare(b.threads[x.thread], b.threads[y.thread]); c != 0 {
+			return c
+		}
+		if c := parser.CompareThreadKeys(x.thread, y.thread); c != 0 {
+			return c
+		}
+		return cmp.Compare(x.eventType, y.eventType)
+	})
+	for _, k := range keys {
+		samples := b.profiles[k]
+		slices.SortStableFunc(samples, func(x, y sample) int {
+			return cmp.Compare(x.nanos, y.nanos)
+		})
+		p := Profile{
+			Type:    "sampled",
+			Name:    b.threads[k.thread] + " " + eventTypes[k.eventType].name,
+			Unit:    eventTypes[k.eventType].unit,
+			Samples: make([][]int, len(samples)),
+			Weights: make([]int64, len(samples)),
+		}
+		for i, s := range samples {
+			p.Samples[i] = s.stack
+			p.Weights[i] = s.weight
+			p.EndValue += s.weight
+		}
+		res.Profiles = append(res.Profiles, p)
+	}
+	return res
+}
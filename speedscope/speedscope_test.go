@@ -0,0 +1,94 @@
+package speedscope
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/jfr-parser/collapsed"
+	"github.com/grafana/jfr-parser/internal/testutil"
+)
+
+func TestTotalsMatchCollapsed(t *testing.T) {
+	collapsedTypes := map[string]string{
+		"process_cpu/cpu":                 "cpu",
+		"wall/wall":                       "wall",
+		"memory/alloc_in_new_tlab_bytes":  "alloc",
+		"memory/alloc_outside_tlab_bytes": "alloc",
+		"memory/alloc_sample_bytes":       "alloc",
+		"mutex/delay":                     "lock",
+		"block/delay":                     "park",
+	}
+	for _, jfr := range []string{"cortex-dev-01__kafka-0__cpu_lock0_alloc0__0", "async-profiler", "object-allocation-sample"} {
+		t.Run(jfr, func(t *testing.T) {
+			body := testutil.ReadGzipFile(t, jfr+".jfr.gz")
+			expected, err := collapsed.ParseJFR(body)
+			require.NoError(t, err)
+			actual, err := ParseJFR(body)
+			require.NoError(t, err)
+
+			expectedTotals := map[string]int64{}
+			for _, p := range expected.Profiles {
+				last := len(p.SampleTypes) - 1
+				typ, ok := collapsedTypes[p.Metric+"/"+p.SampleTypes[last].Type]
+				if !ok {
+					continue
+				}
+				// collapsed CPU and wall values are nanoseconds, speedscope counts samples
+				div := int64(1)
+				if typ == "cpu" || typ == "wall" {
+					div = 1e9 / 100
+				}
+				for _, s := range p.Stacks {
+					expectedTotals[typ] += s.Values[last] / div
+				}
+			}
+			actualTotals := map[string]int64{}
+			for _, p := range actual.Profiles {
+				require.Len(t, p.Weights, len(p.Samples))
+				for _, s := range p.Samples {
+					for _, f := range s {
+						require.Less(t, f, len(actual.Shared.Frames))
+					}
+				}
+				typ := p.Name[strings.LastIndexByte(p.Name, ' ')+1:]
+				actualTotals[typ] += p.EndValue
+			}
+			assert.NotEmpty(t, actualTotals)
+			assert.Equal(t, expectedTotals, actualTotals)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	f, err := ParseJFR(testutil.ReadGzipFile(t, "async-profiler.jfr.gz"))
+	require.NoError(t, err)
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, f.Write(buf))
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, Schema, decoded["$schema"])
+	profiles := decoded["profiles"].([]any)
+	require.Len(t, profiles, len(f.Profiles))
+	assert.Equal(t, "sampled", profiles[0].(map[string]any)["type"])
+}
+
+func TestThreads(t *testing.T) {
+	// two unnamed virtual threads, which are kept apart by their Java thread id
+	f, err := ParseJFR(testutil.ReadGzipFile(t, "virtual-threads.jfr.gz"))
+	require.NoError(t, err)
+	names := map[string]int64{}
+	for _, p := range f.Profiles {
+		names[p.Name] = p.EndValue
+	}
+	assert.Equal(t, map[string]int64{
+		"#40 cpu":  3,
+		"#41 cpu":  2,
+		"main cpu": 4,
+	}, names)
+}